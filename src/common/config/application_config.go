@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/jabong/florest-core/src/common/monitor"
 	"github.com/jabong/florest-core/src/common/ratelimiter"
+	"github.com/jabong/florest-core/src/common/tracer"
 	"github.com/jabong/florest-core/src/common/utils/http"
 )
 
@@ -11,6 +12,8 @@ type AppConfig struct {
 	AppName              string `json:"AppName"`
 	AppVersion           string `json:"AppVersion"`
 	ServerPort           string
+	Server               ServerConfig
+	AdminPort            string
 	LogConfFile          string
 	MonitorConfig        monitor.MConf
 	Performance          PerformanceConfigs
@@ -18,10 +21,61 @@ type AppConfig struct {
 	HTTPConfig           http.Config `json:"HttpConfig"`
 	Profiler             ProfilerConfig
 	ResponseHeaders      ResponseHeaderFields
+	URIScheme            URISchemeConfig
+	// VersionFallbackChain is ordered from the newest to the oldest version, e.g. ["V3", "V2", "V1"].
+	// A request for a version in the chain is served by the newest version which registers the api
+	VersionFallbackChain []string
+	Experiments          []ExperimentConfig
+	ShadowTraffic        ShadowTrafficConfig
+	ErrorResponse        ErrorResponseConfig
+	Upload               http.UploadConfig
+	Tracing              tracer.Config
+	AccessLog            AccessLogConfig
+	AuditLog             AuditLogConfig
+	ResponseCache        ResponseCacheConfig
+	Idempotency          IdempotencyConfig
+	HealthCheck          HealthCheckConfig
 	ApplicationConfig    interface{}
 	AppRateLimiterConfig *ratelimiter.Config
 }
 
+// ServerConfig configures the listeners, the timeouts and the TLS of the web server
+type ServerConfig struct {
+	// Addresses the web server listens on along with ServerPort, e.g. 127.0.0.1:8443, or a unix domain
+	// socket prefixed by unix:, e.g. unix:/var/run/app.sock. The unix domain sockets are served without TLS
+	Addresses []string
+	// ReadTimeoutInMs, ReadHeaderTimeoutInMs, WriteTimeoutInMs and IdleTimeoutInMs are the timeouts of the
+	// connections, not applied when not specified. A write timeout applies to the streamed responses and the
+	// WebSocket handshakes too
+	ReadTimeoutInMs       int
+	ReadHeaderTimeoutInMs int
+	WriteTimeoutInMs      int
+	IdleTimeoutInMs       int
+	// MaxHeaderBytes limits the size of the request headers, 1 MB if not specified
+	MaxHeaderBytes int
+	// HTTP2 serves HTTP/2 to the TLS clients negotiating it
+	HTTP2 bool
+	// TLS serves the tcp addresses over TLS, not used when nil
+	TLS *TLSConfig
+}
+
+// TLSConfig is the certificate and the TLS settings of the web server. The certificate, the key and the
+// client CAs are reloaded from their files on SIGHUP
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the PEM bundle of the CAs the client certificates are verified with
+	ClientCAFile string
+	// ClientAuth is None, Request, Require, VerifyIfGiven or RequireAndVerify. Defaults to RequireAndVerify
+	// when ClientCAFile is specified, None otherwise
+	ClientAuth string
+	// MinVersion is 1.0, 1.1, 1.2 or 1.3, 1.2 if not specified
+	MinVersion string
+	// CipherSuites are the names of the TLS 1.2 cipher suites allowed, e.g.
+	// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. The default suites are allowed when empty
+	CipherSuites []string
+}
+
 // PerformanceConfigs contains Garbage Collector detials, which will determine when the GC will kick
 type PerformanceConfigs struct {
 	UseCorePercentage float64
@@ -31,6 +85,10 @@ type PerformanceConfigs struct {
 // ResponseHeaderFields
 type ResponseHeaderFields struct {
 	CacheControl CacheControlHeaders
+	// ETag sets the ETag of the successful GET responses to a hash of the response data, unless the nodes
+	// set the version of the resource in the response meta data. The conditional requests are then
+	// answered with 304 Not Modified
+	ETag bool
 }
 
 // CacheControlHeaders helps in telling the caller whether to cache the response and up to what time etc.
@@ -41,6 +99,156 @@ type CacheControlHeaders struct {
 	MaxAgeInSeconds int
 }
 
+// URISchemeConfig determines how the resource, version and path params are interpreted from a request
+type URISchemeConfig struct {
+	// Type of the uri scheme - Path (default), Header or MediaType. Custom types can be registered
+	// using service.RegisterURIScheme
+	Type string
+	// Prefix is the path before the version/resource. Defaults to AppName when not specified, set it
+	// to empty if the app prefix is stripped by a gateway
+	Prefix *string
+	// VersionHeader is the request header carrying the version for the Header type
+	VersionHeader string
+	// VersionQueryParam is the query param carrying the version for the Header type, e.g. v
+	VersionQueryParam string
+	// MediaTypeVendor is the vendor in the Accept header for the MediaType type,
+	// e.g. florest for application/vnd.florest.v2+json
+	MediaTypeVendor string
+	// DefaultVersion is used when the request does not specify a version
+	DefaultVersion string
+}
+
+// ExperimentConfig defines the server side bucket assignment for a resource, used for the requests
+// which do not carry the bucket in the bucket header
+type ExperimentConfig struct {
+	// Resource for which the buckets are assigned, e.g. HELLO
+	Resource string
+	// BucketKey is the bucket to be assigned. Defaults to the key registered with
+	// service.RegisterResourceBucketMapping for the resource
+	BucketKey string
+	// Buckets are the bucket values along with their traffic weights
+	Buckets []BucketWeight
+	// StickyKey is the request field used to assign the same bucket to the same caller - UserID,
+	// SessionID or RequestID. Requests without the sticky key are not assigned any bucket. The request id
+	// is generated per request unless the caller sends it, so RequestID assigns the buckets at random
+	// per request rather than per caller
+	StickyKey string
+}
+
+// BucketWeight is the traffic weight of a bucket value in an experiment
+type BucketWeight struct {
+	Value  string
+	Weight int
+}
+
+// ShadowTrafficConfig mirrors a sample of the live requests to a candidate orchestrator bucket, whose
+// result is compared with the primary result. The client always receives the primary response.
+// Only the GET requests are mirrored, unless the api opts in with versionmanager.Version.ShadowWrites.
+// The candidate runs the real nodes of its orchestrator on its own copy of the request, hence the
+// nodes of the GET apis should be free of side effects, and the nodes of the apis mirroring their writes
+// must skip them when constants.ShadowRequest is set in the execution context
+type ShadowTrafficConfig struct {
+	// NWorkers and TaskQueueSize size the worker pool executing the mirrored requests. Mirrored
+	// requests are dropped when the task queue is full
+	NWorkers      int
+	TaskQueueSize int
+	Resources     []ShadowResourceConfig
+}
+
+// ShadowResourceConfig defines the candidate bucket and the sampling of a resource
+type ShadowResourceConfig struct {
+	// Resource whose requests are mirrored, e.g. HELLO
+	Resource string
+	// CandidateBucket is the orchestrator bucket to mirror to. Defaults to New
+	CandidateBucket string
+	// SamplePercentage is the percentage of requests to mirror, between 0 and 100
+	SamplePercentage float64
+}
+
+// ErrorResponseConfig determines how the errors are rendered in the responses
+type ErrorResponseConfig struct {
+	// Format is Envelope (default) to render the errors in the status of the response envelope, or
+	// Problem to render them as application/problem+json documents (RFC 7807). It can be overridden
+	// per api with versionmanager.Version.ErrorFormat
+	Format string
+	// ProblemTypeBaseURI prefixed to the error code forms the type of a problem, e.g.
+	// https://errors.example.com/ gives https://errors.example.com/1402. The type is about:blank if not specified
+	ProblemTypeBaseURI string
+	// StatusPolicy decides the http status of a response with more than one error - HighestSeverity,
+	// FirstWins or LastWins (default)
+	StatusPolicy string
+	// DefaultHTTPStatus is the http status of the app error codes which are not mapped, 500 if not specified
+	DefaultHTTPStatus int
+}
+
+// AccessLogConfig writes a record for each request served, with the method, uri, resolved api, status,
+// response size, duration, client and the ids of the request
+type AccessLogConfig struct {
+	// LoggerKey is the key of the FileLogger the records are written to, the access log is not written
+	// when empty. The records are formatted as per Format, hence the FileLogger should have the raw FormatType
+	LoggerKey string
+	// Format is JSON (default) or Combined, the combined log format followed by the duration in milliseconds,
+	// the request id, the transaction id, the resource, version, action and bucket
+	Format string
+	// SuccessSamplePercentage is the percentage of the 2xx responses logged, 100 if not specified.
+	// The other responses are always logged
+	SuccessSamplePercentage *float64
+	// TrustedProxies are the addresses or the CIDR ranges of the proxies in front of the app, e.g. 10.0.0.0/8.
+	// The client of the requests from a trusted proxy is the last address of X-Forwarded-For which is not of
+	// a trusted proxy, the client being the remote address of the other requests
+	TrustedProxies []string
+}
+
+// AuditLogConfig writes a record for each authorization decision on the apis with
+// versionmanager.Version.Authorization, with the decision, its reason, the api, the caller and the ids of the request
+type AuditLogConfig struct {
+	// LoggerKey is the key of the FileLogger the JSON records are written to, hence the FileLogger should have
+	// the raw FormatType. The audit log is not written when empty
+	LoggerKey string
+}
+
+// ResponseCacheConfig is the store of the responses of the apis cached with versionmanager.Version.ResponseCache
+type ResponseCacheConfig struct {
+	// CacheKey is the key of the cache registered with cache.Set the responses are stored in. The responses
+	// are stored in an in-process LRU cache when empty
+	CacheKey string
+	// MaxEntries is the size of the in-process LRU cache, 10000 if not specified
+	MaxEntries int
+	// CoalesceTimeoutInMs is how long the concurrent requests missing the cache wait for the response of
+	// the first one, 5000 if not specified. The requests which time out are served by the orchestrator
+	CoalesceTimeoutInMs int
+}
+
+// IdempotencyConfig is the store of the responses of the apis made idempotent with versionmanager.Version.Idempotency
+type IdempotencyConfig struct {
+	// CacheKey is the key of the cache registered with cache.Set the responses are stored in
+	CacheKey string
+	// SQLKey is the key of the sql db registered with sqldb.Set the responses are stored in, in SQLTable
+	// (idempotency_keys if not specified). The responses are stored in memory when neither key is specified
+	SQLKey   string
+	SQLTable string
+	// MaxBodySize is the maximum size in bytes of the body of the requests made with an Idempotency-Key,
+	// 32 MB if not specified. The larger bodies are rejected with 413
+	MaxBodySize int64
+}
+
+// HealthCheckConfig configures the liveness and readiness checks of the app, served on their own paths
+// with 200 if all the checks are up and 503 otherwise
+type HealthCheckConfig struct {
+	// LivePath and ReadyPath are the paths of the liveness and readiness checks, /live and /ready if not specified
+	LivePath  string
+	ReadyPath string
+	// TimeoutInMs is how long a check can take before it is reported down, 2000 if not specified
+	TimeoutInMs int
+	// CacheTTLInMs is how long the result of a check is reused by the next probes, not reused when 0
+	CacheTTLInMs int
+	// DiskMinFreeMB is the free space the disks of the log paths should have, 100 if not specified
+	DiskMinFreeMB int
+	// DisableBuiltInChecks skips the readiness checks of the registered sql dbs, mongodbs, caches and the
+	// disks of the log paths
+	DisableBuiltInChecks bool
+}
+
 // Application
 type Application struct {
 	ResponseHeaders ResponseHeaderFields
@@ -2,6 +2,7 @@ package constants
 
 import (
 	"fmt"
+	"sort"
 )
 
 // HTTPCode simply represents the http request code
@@ -54,6 +55,36 @@ const (
 	//RequestValidationFailedCode is the error code if request validation fails
 	RequestValidationFailedCode = 1405
 
+	// NotAcceptableErrorCode is the error code if the response can not be encoded in any of the acceptable media types
+	NotAcceptableErrorCode APPErrorCode = 1406
+
+	// APISunsetErrorCode is the error code if the requested api version is past its sunset date
+	APISunsetErrorCode APPErrorCode = 1410
+
+	// UnsupportedMediaTypeErrorCode is the error code if the request body is in an unsupported content type
+	UnsupportedMediaTypeErrorCode APPErrorCode = 1415
+
+	// UnauthorizedErrorCode is the error code if a request does not carry valid credentials
+	UnauthorizedErrorCode APPErrorCode = 1441
+
+	// ForbiddenErrorCode is the error code if the caller of a request is not allowed to make it
+	ForbiddenErrorCode APPErrorCode = 1443
+
+	// ConflictErrorCode is the error code if a request conflicts with a request in flight
+	ConflictErrorCode APPErrorCode = 1409
+
+	// IdempotencyKeyReusedErrorCode is the error code if an idempotency key is reused with a different request body
+	IdempotencyKeyReusedErrorCode APPErrorCode = 1422
+
+	// PreconditionFailedErrorCode is the error code if the If-Match of a request does not match the resource
+	PreconditionFailedErrorCode APPErrorCode = 1412
+
+	// PayloadTooLargeErrorCode is the error code if an upload exceeds its size limits
+	PayloadTooLargeErrorCode APPErrorCode = 1413
+
+	// UpgradeRequiredErrorCode is the error code if a WebSocket api is called without a valid WebSocket handshake
+	UpgradeRequiredErrorCode APPErrorCode = 1426
+
 	// InvalidURLKeyErrorCode is the error code if url contains an invalid key
 	ResourceErrorCode APPErrorCode = 1501
 
@@ -79,7 +110,18 @@ const (
 	HTTPStatusBadRequestCode          HTTPCode = 400
 	HTTPStatusInternalServerErrorCode HTTPCode = 500
 	HTTPFatalErrorCode                HTTPCode = 501
+	HTTPStatusUnauthorized            HTTPCode = 401
+	HTTPStatusForbidden               HTTPCode = 403
 	HTTPStatusNotFound                HTTPCode = 404
+	HTTPStatusNotAcceptable           HTTPCode = 406
+	HTTPStatusConflict                HTTPCode = 409
+	HTTPStatusGone                    HTTPCode = 410
+	HTTPStatusNotModified             HTTPCode = 304
+	HTTPStatusPreconditionFailed      HTTPCode = 412
+	HTTPStatusPayloadTooLarge         HTTPCode = 413
+	HTTPStatusUnsupportedMediaType    HTTPCode = 415
+	HTTPStatusUnprocessableEntity     HTTPCode = 422
+	HTTPStatusUpgradeRequired         HTTPCode = 426
 	HTTPRateLimitExceeded             HTTPCode = 429
 )
 
@@ -97,26 +139,121 @@ var appErrorCodeToHTTPCodeMap = map[APPErrorCode]HTTPCode{
 	InvalidURLKeyErrorCode:      HTTPStatusBadRequestCode,
 	RequestValidationFailedCode: HTTPStatusBadRequestCode,
 	InvalidRequestURI:           HTTPStatusNotFound,
+	APISunsetErrorCode:          HTTPStatusGone,
+	NotAcceptableErrorCode:      HTTPStatusNotAcceptable,
+
+	UnsupportedMediaTypeErrorCode: HTTPStatusUnsupportedMediaType,
+	PreconditionFailedErrorCode:   HTTPStatusPreconditionFailed,
+	PayloadTooLargeErrorCode:      HTTPStatusPayloadTooLarge,
+	UpgradeRequiredErrorCode:      HTTPStatusUpgradeRequired,
+	UnauthorizedErrorCode:         HTTPStatusUnauthorized,
+	ForbiddenErrorCode:            HTTPStatusForbidden,
+	ConflictErrorCode:             HTTPStatusConflict,
+	IdempotencyKeyReusedErrorCode: HTTPStatusUnprocessableEntity,
 
 	InvalidErrorCode: HTTPFatalErrorCode,
 
 	RateLimitExceeded: HTTPRateLimitExceeded,
 }
 
+// HTTPStatusPolicy decides which of the errors of a response decides its http status
+type HTTPStatusPolicy string
+
+const (
+	// HighestSeverityWins uses the status of the most severe error, server errors over client errors.
+	// The first error wins among the errors of the same severity
+	HighestSeverityWins HTTPStatusPolicy = "HighestSeverity"
+	// FirstWins uses the status of the first error
+	FirstWins HTTPStatusPolicy = "FirstWins"
+	// LastWins uses the status of the last error
+	LastWins HTTPStatusPolicy = "LastWins"
+)
+
+// ErrorCategory classifies the errors by the http status they are mapped to
+type ErrorCategory string
+
+const (
+	ClientErrorCategory  ErrorCategory = "client"
+	ServerErrorCategory  ErrorCategory = "server"
+	UnknownErrorCategory ErrorCategory = "unknown"
+)
+
+var httpStatusPolicy = LastWins
+
+// defaultHTTPCode is the http code of the app error codes which are not mapped
+var defaultHTTPCode = HTTPStatusInternalServerErrorCode
+
+// SetHTTPStatusPolicy sets the policy deciding the http status of a response with more than one error,
+// LastWins by default
+func SetHTTPStatusPolicy(policy HTTPStatusPolicy) error {
+	switch policy {
+	case HighestSeverityWins, FirstWins, LastWins:
+		httpStatusPolicy = policy
+		return nil
+	}
+	return fmt.Errorf("Unknown http status policy %s", policy)
+}
+
+// SetDefaultHTTPCode sets the http code of the app error codes which are not mapped, 500 by default
+func SetDefaultHTTPCode(httpCode HTTPCode) error {
+	if !isValidHTTPCode(httpCode) {
+		return fmt.Errorf("Invalid default http code %d", httpCode)
+	}
+	defaultHTTPCode = httpCode
+	return nil
+}
+
+// ResetHTTPErrors resets the http status policy and the default http code to LastWins and 500
+func ResetHTTPErrors() {
+	httpStatusPolicy = LastWins
+	defaultHTTPCode = HTTPStatusInternalServerErrorCode
+}
+
 func GetAppHTTPError(appErrors AppErrors) *APPHttpStatus {
 	httpCode := HTTPStatusSuccessCode
+	if _, primaryHTTPCode, found := getPrimaryAppError(appErrors); found {
+		httpCode = primaryHTTPCode
+	}
+	return getAppErrStatus(httpCode, appErrors)
+}
 
-	//Only considering the last app error to generate the http code
-	if appErrors.Errors != nil && len(appErrors.Errors) > 0 {
-		lastAppError := appErrors.Errors[len(appErrors.Errors)-1]
-		v, found := appErrorCodeToHTTPCodeMap[lastAppError.Code]
-		if !found {
-			httpCode = InvalidErrorCode
+// GetPrimaryAppError returns the error deciding the http status of the errors as per the http status policy
+func GetPrimaryAppError(appErrors AppErrors) (AppError, bool) {
+	appError, _, found := getPrimaryAppError(appErrors)
+	return appError, found
+}
+
+func getPrimaryAppError(appErrors AppErrors) (primary AppError, httpCode HTTPCode, found bool) {
+	for i, appError := range appErrors.Errors {
+		code := ResolveHTTPCode(appError.Code)
+		if i == 0 || httpStatusPolicy == LastWins ||
+			httpStatusPolicy == HighestSeverityWins && getSeverity(code) > getSeverity(httpCode) {
+			primary, httpCode, found = appError, code, true
 		}
-		httpCode = v
 	}
+	return primary, httpCode, found
+}
 
-	return getAppErrStatus(httpCode, appErrors)
+// getSeverity ranks the server errors over the client errors over the others
+func getSeverity(httpCode HTTPCode) int {
+	switch GetErrorCategory(httpCode) {
+	case ServerErrorCategory:
+		return 2
+	case ClientErrorCategory:
+		return 1
+	}
+	return 0
+}
+
+// GetErrorCategory returns the category of the errors with the http code
+func GetErrorCategory(httpCode HTTPCode) ErrorCategory {
+	switch {
+	case httpCode >= 400 && httpCode < 500:
+		return ClientErrorCategory
+	case httpCode >= 500 && httpCode < 600:
+		return ServerErrorCategory
+	}
+	return UnknownErrorCategory
 }
 
 // getAppErrStatus returns the complete httpStatus containing errors and success/failure status
@@ -135,9 +272,44 @@ func getAppErrStatus(status HTTPCode, appErrors AppErrors) *APPHttpStatus {
 	return httpStatus
 }
 
-// UpdateAppHTTPError updates the map with error code to http code
-func UpdateAppHTTPError(appErrorCodeMap map[APPErrorCode]HTTPCode) {
+// GetHTTPCode returns the http code the app error code is mapped to
+func GetHTTPCode(code APPErrorCode) (HTTPCode, bool) {
+	httpCode, found := appErrorCodeToHTTPCodeMap[code]
+	return httpCode, found
+}
+
+// ResolveHTTPCode returns the http code the app error code is mapped to, the default http code if it is not mapped
+func ResolveHTTPCode(code APPErrorCode) HTTPCode {
+	if httpCode, found := appErrorCodeToHTTPCodeMap[code]; found {
+		return httpCode
+	}
+	return defaultHTTPCode
+}
+
+// UpdateAppHTTPError updates the map with error code to http code. The map is rejected as a whole if an
+// error code is already mapped to a different http code or if a http code is invalid
+func UpdateAppHTTPError(appErrorCodeMap map[APPErrorCode]HTTPCode) error {
+	codes := make([]int, 0, len(appErrorCodeMap))
+	for k := range appErrorCodeMap {
+		codes = append(codes, int(k))
+	}
+	sort.Ints(codes)
+	for _, c := range codes {
+		k := APPErrorCode(c)
+		v := appErrorCodeMap[k]
+		if !isValidHTTPCode(v) {
+			return fmt.Errorf("Invalid http code %d for app error code %d", v, k)
+		}
+		if existing, found := appErrorCodeToHTTPCodeMap[k]; found && existing != v {
+			return fmt.Errorf("App error code %d is already mapped to http code %d, can not map it to %d", k, existing, v)
+		}
+	}
 	for k, v := range appErrorCodeMap {
 		appErrorCodeToHTTPCodeMap[k] = v
 	}
+	return nil
+}
+
+func isValidHTTPCode(httpCode HTTPCode) bool {
+	return httpCode >= 100 && httpCode < 600
 }
@@ -0,0 +1,117 @@
+package constants
+
+import (
+	"testing"
+)
+
+const testUnmappedErrorCode APPErrorCode = 9999
+
+/*
+Test the http status of the errors as per the http status policy
+*/
+func TestGetAppHTTPError(t *testing.T) {
+	defer SetHTTPStatusPolicy(LastWins)
+
+	errs := AppErrors{Errors: []AppError{{Code: ParamsInValidErrorCode}, {Code: DbErrorCode}, {Code: InvalidRequestURI}}}
+	cases := []struct {
+		policy   HTTPStatusPolicy
+		httpCode HTTPCode
+		primary  APPErrorCode
+	}{
+		{LastWins, HTTPStatusNotFound, InvalidRequestURI},
+		{FirstWins, HTTPStatusBadRequestCode, ParamsInValidErrorCode},
+		{HighestSeverityWins, HTTPStatusInternalServerErrorCode, DbErrorCode},
+	}
+	for _, c := range cases {
+		if err := SetHTTPStatusPolicy(c.policy); err != nil {
+			t.Fatalf("Failed to set policy %s. Err : %s", c.policy, err)
+		}
+		status := GetAppHTTPError(errs)
+		if status.HTTPStatusCode != c.httpCode || status.Success {
+			t.Errorf("Status mismatch for policy %s, got %+v", c.policy, status)
+		}
+		if primary, found := GetPrimaryAppError(errs); !found || primary.Code != c.primary {
+			t.Errorf("Primary error mismatch for policy %s, got %+v", c.policy, primary)
+		}
+	}
+	if err := SetHTTPStatusPolicy("Random"); err == nil {
+		t.Error("Expected error for an unknown policy")
+	}
+
+	if status := GetAppHTTPError(AppErrors{}); status.HTTPStatusCode != HTTPStatusSuccessCode || !status.Success {
+		t.Errorf("Expected success for no errors, got %+v", status)
+	}
+}
+
+/*
+Test the http status of the error codes which are not mapped
+*/
+func TestGetAppHTTPErrorUnmapped(t *testing.T) {
+	defer SetDefaultHTTPCode(HTTPStatusInternalServerErrorCode)
+
+	errs := AppErrors{Errors: []AppError{{Code: testUnmappedErrorCode}}}
+	if status := GetAppHTTPError(errs); status.HTTPStatusCode != HTTPStatusInternalServerErrorCode {
+		t.Errorf("Expected default status for unmapped code, got %+v", status)
+	}
+	if err := SetDefaultHTTPCode(503); err != nil {
+		t.Fatalf("Failed to set default http code. Err : %s", err)
+	}
+	if status := GetAppHTTPError(errs); status.HTTPStatusCode != 503 {
+		t.Errorf("Expected configured default status for unmapped code, got %+v", status)
+	}
+	if err := SetDefaultHTTPCode(0); err == nil {
+		t.Error("Expected error for an invalid default http code")
+	}
+}
+
+/*
+Test the http status policy and the default http code are reset to their defaults
+*/
+func TestResetHTTPErrors(t *testing.T) {
+	defer ResetHTTPErrors()
+	if err := SetHTTPStatusPolicy(FirstWins); err != nil {
+		t.Fatalf("Failed to set http status policy. Err : %s", err)
+	}
+	if err := SetDefaultHTTPCode(503); err != nil {
+		t.Fatalf("Failed to set default http code. Err : %s", err)
+	}
+	ResetHTTPErrors()
+
+	errs := AppErrors{Errors: []AppError{{Code: ParamsInValidErrorCode}, {Code: testUnmappedErrorCode}}}
+	if status := GetAppHTTPError(errs); status.HTTPStatusCode != HTTPStatusInternalServerErrorCode {
+		t.Errorf("Expected the last error with the default status after the reset, got %+v", status)
+	}
+}
+
+/*
+Test colliding and invalid mappings are rejected as a whole
+*/
+func TestUpdateAppHTTPError(t *testing.T) {
+	if err := UpdateAppHTTPError(map[APPErrorCode]HTTPCode{DbErrorCode: HTTPStatusInternalServerErrorCode}); err != nil {
+		t.Errorf("Mapping a code to the same http code should be allowed. Err : %s", err)
+	}
+	rejected := []map[APPErrorCode]HTTPCode{
+		{testUnmappedErrorCode: HTTPStatusBadRequestCode, DbErrorCode: HTTPStatusBadRequestCode},
+		{testUnmappedErrorCode: 0},
+		{testUnmappedErrorCode: 600},
+	}
+	for _, m := range rejected {
+		if err := UpdateAppHTTPError(m); err == nil {
+			t.Errorf("Expected error for mapping %v", m)
+		}
+	}
+	if _, found := GetHTTPCode(testUnmappedErrorCode); found {
+		t.Error("Rejected mapping modified the error code map")
+	}
+}
+
+/*
+Test the error categories
+*/
+func TestGetErrorCategory(t *testing.T) {
+	if GetErrorCategory(HTTPStatusNotFound) != ClientErrorCategory ||
+		GetErrorCategory(HTTPFatalErrorCode) != ServerErrorCategory ||
+		GetErrorCategory(HTTPStatusSuccessCode) != UnknownErrorCategory {
+		t.Error("Error category mismatch")
+	}
+}
@@ -6,10 +6,29 @@ const (
 	Request = "REQUEST"
 	// RequestContext
 	RequestContext = "REQUEST_CONTEXT"
+	// ShadowRequest is set in the execution context of a mirrored request, nodes with side effects
+	// should skip them when it is set
+	ShadowRequest = "SHADOW_REQUEST"
 	// RequestBodyParam
 	RequestBodyParam = "REQUEST_BODY_PARAM"
 	// RequestPathParam
 	RequestPathParam = "REQUEST_PATH_PARAMETER"
+	// BoundBody, BoundQuery, BoundHeader and BoundPath are the pointers to the structs the parts of the
+	// request are bound to, for the apis declaring the request types
+	BoundBody   = "BOUND_BODY"
+	BoundQuery  = "BOUND_QUERY"
+	BoundHeader = "BOUND_HEADER"
+	BoundPath   = "BOUND_PATH"
+	// UploadedFiles and UploadedValues are the files and the values of the multipart uploads, for the
+	// apis parsing the uploads. The files are removed once the response is written
+	UploadedFiles  = "UPLOADED_FILES"
+	UploadedValues = "UPLOADED_VALUES"
+	// WebSocketMessage and WebSocketMessageType are the payload and the type of the message received
+	// on a WebSocket, for the orchestrators of the WebSocket apis
+	WebSocketMessage     = "WEBSOCKET_MESSAGE"
+	WebSocketMessageType = "WEBSOCKET_MESSAGE_TYPE"
+	// WebSocketSession is the session set up for a WebSocket api, served once the service pipeline ends
+	WebSocketSession = "WEBSOCKET_SESSION"
 
 	// Response
 	Response = "RESPONSE"
@@ -20,18 +39,36 @@ const (
 	ResponseStatus        = "RESPONSE_STATUS"
 	ResponseHeadersConfig = "RESPONSE_HEADERS_CONFIG"
 	APIResponse           = "API_RESPONSE"
+	ResponseMediaType     = "RESPONSE_MEDIA_TYPE"
+	// ResponseWriter is the http.ResponseWriter of the request and ResponseStream the stream over it
+	// for the streaming apis
+	ResponseWriter = "RESPONSE_WRITER"
+	ResponseStream = "RESPONSE_STREAM"
+	// CachedResponse is the response served from the response cache and ResponseCacheCall the
+	// response to be cached once served
+	CachedResponse    = "CACHED_RESPONSE"
+	ResponseCacheCall = "RESPONSE_CACHE_CALL"
+	// IdempotentRequest is the request made with an idempotency key and ReplayedResponse the response of
+	// its first request replayed
+	IdempotentRequest = "IDEMPOTENT_REQUEST"
+	ReplayedResponse  = "REPLAYED_RESPONSE"
+	// Principal is the caller of the request authenticated by the authenticators of the api
+	Principal = "PRINCIPAL"
 
 	APPError = "APPERROR"
 
 	HTTPVerb = "HTTPVERB"
 	URI      = "URI"
 
-	BucketID = "BUCKETID"
+	BucketID        = "BUCKETID"
+	AssignedBuckets = "ASSIGNED_BUCKETS"
 
-	Resource   = "RESOURCE"
-	Version    = "VERSION"
-	Action     = "ACTION"
-	PathParams = "PATH_PARAMS"
+	Resource    = "RESOURCE"
+	Version     = "VERSION"
+	Action      = "ACTION"
+	PathParams  = "PATH_PARAMS"
+	Deprecation = "DEPRECATION"
+	APIVersion  = "API_VERSION"
 
 	Result = "RESULT"
 
@@ -56,6 +93,8 @@ const (
 	OrchestratorBucketKey          = "Algo"
 	OrchestratorBucketDefaultValue = "Old"
 	OrchestratorBucketNewAlgo      = "New"
+	// AssignedBucketsHeader echoes the buckets assigned by the service in the bucket header format
+	AssignedBucketsHeader = "X-Bucket"
 )
 
 // constant for monitor
@@ -7,6 +7,7 @@ import (
 const (
 	STRING = "string"
 	JSON   = "json"
+	RAW    = "raw"
 )
 
 // GetFormatter returns required formatter based on string argument
@@ -16,6 +17,8 @@ func GetFormatter(ftype string) (ret FormatInterface, err error) {
 		ret = new(stringFormat)
 	case JSON:
 		ret = new(jsonFormat)
+	case RAW:
+		ret = new(rawFormat)
 	default:
 		err = errors.New("unsupported format type:" + ftype)
 	}
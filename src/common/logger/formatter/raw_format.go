@@ -0,0 +1,13 @@
+package formatter
+
+import (
+	"github.com/jabong/florest-core/src/common/logger/message"
+)
+
+type rawFormat struct {
+}
+
+//GetFormattedLog returns the message of the log as is
+func (rf *rawFormat) GetFormattedLog(msg *message.LogMsg) interface{} {
+	return msg.Message
+}
@@ -9,7 +9,7 @@ type stringFormat struct {
 }
 
 //formatString is format of the log in string formattype configuration
-var formatString = "[level : %s, message : %s, tId : %s, reqId : %s, appId : %s, sessionId : %s, userId : %s, stackTraces : %s, timestamp : %s, uri : %s]"
+var formatString = "[level : %s, message : %s, tId : %s, reqId : %s, appId : %s, sessionId : %s, userId : %s, stackTraces : %s, timestamp : %s, uri : %s, traceId : %s]"
 
 //GetFormattedLog returns formatted log as a string interface
 func (sf *stringFormat) GetFormattedLog(msg *message.LogMsg) interface{} {
@@ -22,5 +22,6 @@ func (sf *stringFormat) GetFormattedLog(msg *message.LogMsg) interface{} {
 		msg.UserID,
 		msg.StackTraces,
 		msg.TimeStamp,
-		msg.URI)
+		msg.URI,
+		msg.TraceID)
 }
@@ -7,6 +7,9 @@ import (
 	"io/ioutil"
 	"log"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/jabong/florest-core/src/common/logger/formatter"
 	"github.com/jabong/florest-core/src/common/logger/impls"
@@ -32,6 +35,18 @@ var loggerImpls map[string]LogInterface
 //conf holds the various logger configs
 var conf *Config
 
+//logLevel is the log level in effect, initialised from the config and changed with SetLogLevel
+var logLevel int32
+
+//levelNames maps the log levels to their names
+var levelNames = map[int]string{
+	DebugLevel:   "debug",
+	InfoLevel:    "info",
+	TraceLevel:   "trace",
+	WarningLevel: "warning",
+	ErrLevel:     "error",
+}
+
 //Initialise initialises the logger
 func Initialise(confFile string) error {
 	conf = new(Config)
@@ -48,6 +63,7 @@ func Initialise(confFile string) error {
 		log.Println(msg)
 		return err
 	}
+	atomic.StoreInt32(&logLevel, int32(conf.LogLevel))
 	loggerImpls = make(map[string]LogInterface)
 	return initLoggers()
 }
@@ -70,6 +86,22 @@ func GetDefaultLogTypeKey() string {
 	return conf.DefaultLogType
 }
 
+//GetLogPaths returns the paths the file loggers write to, without duplicates
+func GetLogPaths() []string {
+	if conf == nil {
+		return nil
+	}
+	var paths []string
+	seen := make(map[string]bool)
+	for _, c := range conf.FileLogger {
+		if !seen[c.Path] {
+			seen[c.Path] = true
+			paths = append(paths, c.Path)
+		}
+	}
+	return paths
+}
+
 //getStackTrace gets the stack trace for a called function.
 func getStackTrace() []string {
 	var sf []string
@@ -123,7 +155,42 @@ func initLoggers() error {
 	return initFileLoggers()
 }
 
-//CanLog returns true if the argument logeLevel is smaller than config logLevel
-func CanLog(logLevel int) bool {
-	return conf != nil && conf.LogLevel >= logLevel
+//CanLog returns true if the argument level is smaller than the log level in effect
+func CanLog(level int) bool {
+	return conf != nil && int(atomic.LoadInt32(&logLevel)) >= level
+}
+
+//GetLogLevel returns the log level in effect
+func GetLogLevel() int {
+	return int(atomic.LoadInt32(&logLevel))
+}
+
+//SetLogLevel changes the log level in effect without reloading the logger config
+func SetLogLevel(level int) error {
+	if _, ok := levelNames[level]; !ok {
+		return fmt.Errorf("Invalid log level %d", level)
+	}
+	atomic.StoreInt32(&logLevel, int32(level))
+	return nil
+}
+
+//GetLogLevelName returns the name of the log level, e.g. info
+func GetLogLevelName(level int) string {
+	return levelNames[level]
+}
+
+//ParseLogLevel parses a log level given by its name, e.g. warning, or its number
+func ParseLogLevel(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for level, name := range levelNames {
+		if name == s {
+			return level, nil
+		}
+	}
+	if level, err := strconv.Atoi(s); err == nil {
+		if _, ok := levelNames[level]; ok {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid log level %s", s)
 }
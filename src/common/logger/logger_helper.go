@@ -136,6 +136,34 @@ func Profile(a ...interface{}) {
 	ProfileSpecific(GetDefaultLoggerType(), a...)
 }
 
+//AccessSpecific logs an access log record to a specific log handle. Like the profiles,
+//the records are written irrespective of the log level
+func AccessSpecific(logType string, a ...interface{}) {
+	loggerHandle, err := GetLoggerHandle(logType)
+	if err != nil {
+		fmt.Println("Skipping Log Access Message : " + err.Error())
+		return
+	}
+	msg := Convert(a...)
+	msg.Level = "access"
+	msg.TimeStamp = time.Now().Local().Format(time.RFC3339)
+	loggerHandle.Info(msg)
+}
+
+//AuditSpecific logs an audit record to a specific log handle. Like the access log records,
+//the audit records are written irrespective of the log level
+func AuditSpecific(logType string, a ...interface{}) {
+	loggerHandle, err := GetLoggerHandle(logType)
+	if err != nil {
+		fmt.Println("Skipping Log Audit Message : " + err.Error())
+		return
+	}
+	msg := Convert(a...)
+	msg.Level = "audit"
+	msg.TimeStamp = time.Now().Local().Format(time.RFC3339)
+	loggerHandle.Info(msg)
+}
+
 //GetDefaultLoggerType gets the key of a default logger type
 func GetDefaultLoggerType() string {
 	return GetDefaultLogTypeKey()
@@ -159,6 +187,9 @@ func Convert(a ...interface{}) message.LogMsg {
 	//First param is message string; Second param is request context
 	vMsg, msgOk := a[0].(string)
 	vRc, rcOk := a[1].(utilHttp.RequestContext)
+	if pRc, ok := a[1].(*utilHttp.RequestContext); ok && pRc != nil {
+		vRc, rcOk = *pRc, true
+	}
 
 	if !msgOk || !rcOk {
 
@@ -174,5 +205,6 @@ func Convert(a ...interface{}) message.LogMsg {
 		AppID:         vRc.AppName,
 		UserID:        vRc.UserID,
 		URI:           vRc.URI,
+		TraceID:       vRc.TraceID,
 	}
 }
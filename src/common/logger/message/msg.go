@@ -17,4 +17,5 @@ type LogMsg struct {
 	StackTraces   []string `json:"stackTraces,omitempty"`
 	TimeStamp     string   `json:"timestamp"`
 	URI           string   `json:"uri,omitempty"`
+	TraceID       string   `json:"traceId,omitempty"`
 }
@@ -1,7 +1,7 @@
 package monitor
 
 import (
-	_ "expvar"
+	"expvar"
 	"fmt"
 	"github.com/ooyala/go-dogstatsd"
 	"net"
@@ -177,11 +177,19 @@ func (d *DataDogAgentClient) SendAppMetrics(serverIP string) (err error) {
 	}
 	go func() {
 		logMsg(infoMsgtag, fmt.Sprintf("App Metrics available at %s", serverIP), d.conf)
-		http.Serve(sock, nil)
+		http.Serve(sock, appMetricsHandler())
 	}()
 	return nil
 }
 
+// appMetricsHandler serves the expvar stats only, not the handlers registered on the default serve mux,
+// e.g. the pprof profiles
+func appMetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
 // getNamespacedEventName prefixes the appName with  each event name
 func (d *DataDogAgentClient) getNamespacedEventTitle(n string) string {
 	return d.client.Namespace + n
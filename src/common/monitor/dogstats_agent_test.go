@@ -1,6 +1,8 @@
 package monitor
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -65,3 +67,14 @@ func TestSet(t *testing.T) {
 		t.Errorf("Error event failed %v", err)
 	}
 }
+
+func TestAppMetricsHandler(t *testing.T) {
+	h := appMetricsHandler()
+	for path, status := range map[string]int{"/debug/vars": http.StatusOK, "/debug/pprof/": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != status {
+			t.Errorf("Expected %s to be served with %d, got %d", path, status, w.Code)
+		}
+	}
+}
@@ -72,3 +72,19 @@ func (o *GCRARateLimiter) RateLimit(key string) (exceeded bool,
 	return exceeded, res, nil
 
 }
+
+// Inspect returns the state of the rate limiter for a particular key
+// without counting a request against it
+func (o *GCRARateLimiter) Inspect(key string) (*State, *Error) {
+	_, rres, rerr := o.rateLimiter.RateLimit(key, 0)
+	if rerr != nil {
+		return nil, getErrObj(ErrFatal, rerr.Error())
+	}
+	return &State{
+		Type:       GCRA,
+		MaxRate:    o.MaxRate,
+		MaxBurst:   o.MaxBurst,
+		Remaining:  rres.Remaining,
+		ResetAfter: rres.ResetAfter,
+	}, nil
+}
@@ -11,3 +11,9 @@ type RateLimiter interface {
 	// information about the state of the RateLimiter.
 	RateLimit(key string) (exceeded bool, res *RateLimitResult, err *Error)
 }
+
+// Inspector is implemented by the rate limiters which can report the state of a key
+// without consuming any of its quota
+type Inspector interface {
+	Inspect(key string) (*State, *Error)
+}
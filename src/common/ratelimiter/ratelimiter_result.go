@@ -29,3 +29,21 @@ type RateLimitResult struct {
 	// It should be -1 unless the rate limit has been exceeded.
 	RetryAfter time.Duration
 }
+
+// State is the state of a rate limiter for a key
+type State struct {
+	// Type is the rate limiting algorithm
+	Type string
+
+	// MaxRate and MaxBurst are the configured rate per second and burst
+	MaxRate  int
+	MaxBurst int
+
+	// Remaining is the number of requests that could be permitted
+	// instantaneously for the key
+	Remaining int
+
+	// ResetAfter is the time until the RateLimiter returns to its
+	// initial state for the key
+	ResetAfter time.Duration
+}
@@ -127,3 +127,35 @@ func TestGCRA(t *testing.T) {
 	}
 
 }
+
+func TestGCRAInspect(t *testing.T) {
+	conf := new(Config)
+
+	conf.Type = GCRA
+	conf.MaxRate = 1
+	conf.MaxBurst = 2
+
+	rl, err := New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inspector, ok := rl.(Inspector)
+	if !ok {
+		t.Fatal("GCRA rate limiter should be an inspector")
+	}
+
+	for i := 0; i < 2; i++ {
+		state, ierr := inspector.Inspect("key")
+		if ierr != nil {
+			t.Fatal(ierr)
+		}
+		if state.Type != GCRA || state.MaxRate != 1 || state.MaxBurst != 2 || state.Remaining != 3-i {
+			t.Fatalf("Unexpected state %+v on inspection %d", state, i)
+		}
+		// inspecting does not consume the quota
+		if _, ierr = inspector.Inspect("key"); ierr != nil {
+			t.Fatal(ierr)
+		}
+		rl.RateLimit("key")
+	}
+}
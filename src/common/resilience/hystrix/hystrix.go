@@ -47,3 +47,24 @@ func GetCommandConfigurations() map[string]HCommandConf {
 	}
 	return res
 }
+
+// HCircuitState is the state of the circuit of a command along with its configuration
+type HCircuitState struct {
+	HCommandConf
+	// Open is true when the circuit is open and the calls are short circuited to the fallback
+	Open bool
+}
+
+// GetCircuitStates returns the state of the circuits of all configured commands indexed by command name
+func GetCircuitStates() map[string]HCircuitState {
+	cmdConf := GetCommandConfigurations()
+	res := make(map[string]HCircuitState, len(cmdConf))
+	for k, v := range cmdConf {
+		state := HCircuitState{HCommandConf: v}
+		if cb, _, err := hys.GetCircuit(k); err == nil {
+			state.Open = cb.IsOpen()
+		}
+		res[k] = state
+	}
+	return res
+}
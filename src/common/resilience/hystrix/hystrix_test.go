@@ -93,3 +93,23 @@ func TestHystrixGoFailure(t *testing.T) {
 		}
 	}
 }
+
+func TestCircuitStates(t *testing.T) {
+	conf := HCommandConf{
+		Timeout:                100,
+		MaxConcurrentRequests:  10,
+		RequestVolumeThreshold: 5,
+		SleepWindow:            40,
+		ErrorPercentThreshold:  50,
+	}
+	ConfigureCommand("testState", conf)
+	res := GetCircuitStates()
+
+	state, ok := res["testState"]
+	if !ok {
+		t.Fatal("Not able to find testState in circuit states")
+	}
+	if state.Open || !reflect.DeepEqual(state.HCommandConf, conf) {
+		t.Errorf("Expected closed circuit with %+v, got %+v", conf, state)
+	}
+}
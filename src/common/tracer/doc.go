@@ -0,0 +1,5 @@
+// Package tracer records the spans of a request - the request itself, the orchestrator nodes, the cache, sql and
+// mongo db operations and the outbound http calls - and exports them in batches through an Exporter.
+// Spans are correlated across services with the W3C trace context headers. A nil *Span is valid and
+// records nothing, which is what Start returns while tracing is disabled
+package tracer
@@ -0,0 +1,3 @@
+// Package exporter provides the exporters of the tracer spans - to the app logs, and to an OpenTelemetry
+// collector over OTLP/HTTP with the JSON encoding
+package exporter
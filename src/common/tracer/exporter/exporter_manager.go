@@ -0,0 +1,29 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+// New returns the exporter named in the config
+func New(conf tracer.Config) (tracer.Exporter, error) {
+	switch strings.ToUpper(conf.Exporter) {
+	case "", strings.ToUpper(tracer.LoggerExporter):
+		return NewLoggerExporter(), nil
+	case strings.ToUpper(tracer.OTLPExporter):
+		endpoint := conf.OTLPEndpoint
+		if endpoint == "" {
+			endpoint = tracer.DefaultOTLPEndpoint
+		}
+		timeout := conf.OTLPTimeoutInMs
+		if timeout <= 0 {
+			timeout = tracer.DefaultOTLPTimeoutInMs
+		}
+		return NewOTLPExporter(endpoint, conf.ServiceName, conf.OTLPHeaders,
+			time.Duration(timeout)*time.Millisecond), nil
+	}
+	return nil, fmt.Errorf("Unknown tracer exporter %s", conf.Exporter)
+}
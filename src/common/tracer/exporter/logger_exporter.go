@@ -0,0 +1,56 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jabong/florest-core/src/common/logger"
+	"github.com/jabong/florest-core/src/common/tracer"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+// loggedSpan is the log form of a span
+type loggedSpan struct {
+	Name       string                 `json:"name"`
+	Kind       tracer.SpanKind        `json:"kind"`
+	TraceID    string                 `json:"traceId"`
+	SpanID     string                 `json:"spanId"`
+	ParentID   string                 `json:"parentId,omitempty"`
+	Start      string                 `json:"start"`
+	DurationMs float64                `json:"durationMs"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      bool                   `json:"error,omitempty"`
+	Status     string                 `json:"status,omitempty"`
+}
+
+// LoggerExporter writes the spans to the app logs, a log line for each span at the info level
+type LoggerExporter struct {
+}
+
+// NewLoggerExporter returns an exporter writing the spans to the app logs
+func NewLoggerExporter() *LoggerExporter {
+	return new(LoggerExporter)
+}
+
+// Export logs the spans
+func (e *LoggerExporter) Export(spans []*tracer.Span) error {
+	for _, s := range spans {
+		b, err := json.Marshal(loggedSpan{
+			Name:       s.Name,
+			Kind:       s.Kind,
+			TraceID:    s.TraceID,
+			SpanID:     s.SpanID,
+			ParentID:   s.ParentID,
+			Start:      s.Start.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+			DurationMs: float64(s.Duration().Nanoseconds()) / 1e6,
+			Attributes: s.Attributes,
+			Error:      s.Error,
+			Status:     s.StatusMessage,
+		})
+		if err != nil {
+			return err
+		}
+		logger.Info(fmt.Sprintf("Span %s", b), utilhttp.RequestContext{TraceID: s.TraceID})
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var received map[string]interface{}
+	var header http.Header
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("Invalid json %s", body)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	start := time.Unix(1500000000, 0)
+	span := &tracer.Span{
+		Name:          "GET v1 orders",
+		Kind:          tracer.SpanKindServer,
+		TraceID:       "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:        "00f067aa0ba902b7",
+		ParentID:      "a3ce929d0e0e4736",
+		Start:         start,
+		End:           start.Add(time.Second),
+		Attributes:    map[string]interface{}{tracer.AttrHTTPMethod: "GET", tracer.AttrHTTPStatusCode: uint16(500)},
+		Error:         true,
+		StatusMessage: "failed",
+	}
+	e := NewOTLPExporter(collector.URL, "orders", map[string]string{"X-Api-Key": "secret"}, time.Second)
+	if err := e.Export([]*tracer.Span{span}); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("Content-Type") != "application/json" || header.Get("X-Api-Key") != "secret" {
+		t.Fatalf("Invalid headers %v", header)
+	}
+
+	rs := received["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	resource := rs["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	if resource["key"] != "service.name" || resource["value"].(map[string]interface{})["stringValue"] != "orders" {
+		t.Fatalf("Invalid resource %v", resource)
+	}
+	ss := rs["scopeSpans"].([]interface{})[0].(map[string]interface{})
+	got := ss["spans"].([]interface{})[0].(map[string]interface{})
+	expected := map[string]interface{}{
+		"traceId":           span.TraceID,
+		"spanId":            span.SpanID,
+		"parentSpanId":      span.ParentID,
+		"name":              span.Name,
+		"kind":              float64(2),
+		"startTimeUnixNano": "1500000000000000000",
+		"endTimeUnixNano":   "1500000001000000000",
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("Expected %s %v, got %v", k, v, got[k])
+		}
+	}
+	attrs := got["attributes"].([]interface{})
+	code := attrs[1].(map[string]interface{})
+	if code["key"] != tracer.AttrHTTPStatusCode || code["value"].(map[string]interface{})["intValue"] != "500" {
+		t.Errorf("Invalid status code attribute %v", code)
+	}
+	status := got["status"].(map[string]interface{})
+	if status["code"] != float64(2) || status["message"] != "failed" {
+		t.Errorf("Invalid status %v", status)
+	}
+}
+
+func TestOTLPExporterRejected(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	e := NewOTLPExporter(collector.URL, "orders", nil, time.Second)
+	if err := e.Export([]*tracer.Span{{Name: "op"}}); err == nil {
+		t.Fatal("Export should fail when the collector rejects the spans")
+	}
+}
+
+func TestOTLPExporterWithTracer(t *testing.T) {
+	spans := make(chan int, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		spans <- len(req.ResourceSpans[0].ScopeSpans[0].Spans)
+	}))
+	defer collector.Close()
+
+	conf := tracer.Config{Enabled: true, ServiceName: "orders", Exporter: tracer.OTLPExporter,
+		OTLPEndpoint: collector.URL}
+	e, err := New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tracer.Init(conf, e, func(err error) { t.Error(err) }); err != nil {
+		t.Fatal(err)
+	}
+	root := tracer.Start(tracer.SpanContext{}, "root", tracer.SpanKindServer)
+	tracer.Start(root.Context(), "child", tracer.SpanKindInternal).Finish()
+	root.Finish()
+	tracer.Stop()
+
+	if n := <-spans; n != 2 {
+		t.Fatalf("Expected 2 spans exported, got %d", n)
+	}
+	if _, err := New(tracer.Config{Exporter: "Unknown"}); err == nil {
+		t.Fatal("Unknown exporter should fail")
+	}
+}
@@ -0,0 +1,126 @@
+package tracer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind is the role of a span in a trace
+type SpanKind int
+
+// Span kinds, numbered as in OTLP
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// SpanContext identifies a span across services
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// IsValid checks if the context identifies a span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != "" && sc.SpanID != ""
+}
+
+// Span is a timed operation of a trace. Its fields must not be modified once it has ended
+type Span struct {
+	Name       string
+	Kind       SpanKind
+	TraceID    string
+	SpanID     string
+	ParentID   string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]interface{}
+	// Error marks the operation as failed, StatusMessage describing the failure
+	Error         bool
+	StatusMessage string
+
+	mu      sync.Mutex
+	sampled bool
+	ended   bool
+}
+
+// Context returns the context of the span, to be passed as the parent of its child spans
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: s.TraceID, SpanID: s.SpanID, Sampled: s.sampled}
+}
+
+// SetAttribute sets an attribute of the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Attributes[key] = value
+	}
+}
+
+// SetError marks the span as failed if err is not nil
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Error = true
+		s.StatusMessage = err.Error()
+	}
+}
+
+// Finish ends the span and queues it for export if it is sampled. Calls after the first are ignored
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.mu.Unlock()
+	if s.sampled {
+		getInstance().enqueue(s)
+	}
+}
+
+// Duration returns the duration of an ended span
+func (s *Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// NewTraceID returns a new random trace id, 16 bytes in lower case hex
+func NewTraceID() string {
+	return randomHex(16)
+}
+
+// NewSpanID returns a new random span id, 8 bytes in lower case hex
+func NewSpanID() string {
+	return randomHex(8)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	for {
+		rand.Read(b)
+		if id := hex.EncodeToString(b); strings.Trim(id, "0") != "" {
+			return id
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package tracer
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Exporter exports the ended spans, called from a single goroutine with the spans in batches
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// ExporterFunc adapts a function to an Exporter
+type ExporterFunc func(spans []*Span) error
+
+// Export calls f(spans)
+func (f ExporterFunc) Export(spans []*Span) error {
+	return f(spans)
+}
+
+// tracer queues the ended spans and exports them in batches
+type tracer struct {
+	conf     Config
+	exporter Exporter
+	queue    chan *Span
+	stop     chan struct{}
+	stopped  chan struct{}
+	onError  func(err error)
+}
+
+var (
+	instance *tracer
+	mutex    sync.RWMutex
+)
+
+func getInstance() *tracer {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return instance
+}
+
+// Init starts the tracer with the exporter, stopping the tracer started earlier if any. The tracer is not
+// started if the config is not enabled. onError, if not nil, is called with the errors of the exports
+func Init(conf Config, exporter Exporter, onError func(err error)) error {
+	Stop()
+	if !conf.Enabled {
+		return nil
+	}
+	if exporter == nil {
+		return errors.New("Tracer exporter not specified")
+	}
+	ratio := defaultSampleRatio
+	if conf.SampleRatio != nil {
+		ratio = *conf.SampleRatio
+	}
+	if ratio < 0 || ratio > 1 {
+		return errors.New("Tracer sample ratio must be between 0 and 1")
+	}
+	conf.SampleRatio = &ratio
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = defaultQueueSize
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = defaultBatchSize
+	}
+	if conf.FlushIntervalInMs <= 0 {
+		conf.FlushIntervalInMs = defaultFlushIntervalInMs
+	}
+	t := &tracer{
+		conf:     conf,
+		exporter: exporter,
+		queue:    make(chan *Span, conf.QueueSize),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		onError:  onError,
+	}
+	go t.process()
+
+	mutex.Lock()
+	instance = t
+	mutex.Unlock()
+	return nil
+}
+
+// Stop exports the queued spans and stops the tracer. The spans ended afterwards are not exported
+func Stop() {
+	mutex.Lock()
+	t := instance
+	instance = nil
+	mutex.Unlock()
+	if t != nil {
+		close(t.stop)
+		<-t.stopped
+	}
+}
+
+// Enabled checks if the spans are being recorded
+func Enabled() bool {
+	return getInstance() != nil
+}
+
+// Start starts a span as a child of parent, or as the root span of a new trace if parent is not valid.
+// Returns nil if the tracer is not started
+func Start(parent SpanContext, name string, kind SpanKind) *Span {
+	t := getInstance()
+	if t == nil {
+		return nil
+	}
+	if !parent.IsValid() {
+		return newSpan(SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Sampled: t.sample()}, "", name, kind)
+	}
+	return newSpan(SpanContext{TraceID: parent.TraceID, SpanID: NewSpanID(), Sampled: parent.Sampled},
+		parent.SpanID, name, kind)
+}
+
+// StartWithContext starts a span whose ids are assigned beforehand, e.g. the span of a request whose id
+// has been handed to the logs. parentID is empty for a root span. Returns nil if the tracer is not started
+func StartWithContext(sc SpanContext, parentID string, name string, kind SpanKind) *Span {
+	if getInstance() == nil || !sc.IsValid() {
+		return nil
+	}
+	return newSpan(sc, parentID, name, kind)
+}
+
+// Sample decides if a new trace is to be recorded as per the sample ratio
+func Sample() bool {
+	t := getInstance()
+	return t != nil && t.sample()
+}
+
+func newSpan(sc SpanContext, parentID string, name string, kind SpanKind) *Span {
+	return &Span{
+		Name:       name,
+		Kind:       kind,
+		TraceID:    sc.TraceID,
+		SpanID:     sc.SpanID,
+		ParentID:   parentID,
+		Start:      time.Now(),
+		Attributes: make(map[string]interface{}),
+		sampled:    sc.Sampled,
+	}
+}
+
+func (t *tracer) sample() bool {
+	ratio := *t.conf.SampleRatio
+	return ratio >= 1 || (ratio > 0 && rand.Float64() < ratio)
+}
+
+// enqueue queues the span for export, dropping it if the queue is full
+func (t *tracer) enqueue(s *Span) {
+	if t == nil {
+		return
+	}
+	select {
+	case t.queue <- s:
+	default:
+		if t.onError != nil {
+			t.onError(errors.New("Tracer queue full, span dropped"))
+		}
+	}
+}
+
+// process exports the queued spans when a batch fills up or at the flush interval
+func (t *tracer) process() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(time.Duration(t.conf.FlushIntervalInMs) * time.Millisecond)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, t.conf.BatchSize)
+	for {
+		select {
+		case s := <-t.queue:
+			batch = append(batch, s)
+			if len(batch) >= t.conf.BatchSize {
+				batch = t.export(batch)
+			}
+		case <-ticker.C:
+			batch = t.export(batch)
+		case <-t.stop:
+			for {
+				select {
+				case s := <-t.queue:
+					batch = append(batch, s)
+					if len(batch) >= t.conf.BatchSize {
+						batch = t.export(batch)
+					}
+				default:
+					t.export(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// export exports the batch and returns an empty batch for the next spans
+func (t *tracer) export(batch []*Span) []*Span {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := t.exporter.Export(batch); err != nil && t.onError != nil {
+		t.onError(err)
+	}
+	return make([]*Span, 0, t.conf.BatchSize)
+}
@@ -0,0 +1,27 @@
+package tracer
+
+// Config of the tracer. The fields not specified take the default values
+type Config struct {
+	// Enabled turns on the recording of the spans
+	Enabled bool
+	// ServiceName identifies the app in the exported spans, the app name if not specified
+	ServiceName string
+	// SampleRatio is the ratio of the new traces which are recorded, between 0 and 1, all of them if not
+	// specified. Traces started by the callers are recorded as per their sampled flag
+	SampleRatio *float64
+	// Exporter is the name of the exporter - Logger (default) or OTLP
+	Exporter string
+	// OTLPEndpoint is the url the OTLP exporter posts the spans to
+	OTLPEndpoint string
+	// OTLPHeaders are sent along with the spans, e.g. the api key of the collector
+	OTLPHeaders map[string]string
+	// OTLPTimeoutInMs is the timeout of an export, 10000 if not specified
+	OTLPTimeoutInMs int
+	// QueueSize is the number of the ended spans waiting to be exported, the spans ended while the
+	// queue is full are dropped
+	QueueSize int
+	// BatchSize is the maximum number of spans exported together
+	BatchSize int
+	// FlushIntervalInMs is the interval at which the queued spans are exported
+	FlushIntervalInMs int
+}
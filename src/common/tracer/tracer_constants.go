@@ -0,0 +1,32 @@
+package tracer
+
+// Exporters
+const (
+	LoggerExporter string = "Logger"
+	OTLPExporter   string = "OTLP"
+)
+
+// Defaults of the config
+const (
+	defaultSampleRatio       = 1.0
+	defaultQueueSize         = 2048
+	defaultBatchSize         = 512
+	defaultFlushIntervalInMs = 5000
+	DefaultOTLPEndpoint      = "http://localhost:4318/v1/traces"
+	DefaultOTLPTimeoutInMs   = 10000
+)
+
+// Attribute keys of the spans recorded by the framework
+const (
+	AttrHTTPMethod     = "http.method"
+	AttrHTTPURL        = "http.url"
+	AttrHTTPTarget     = "http.target"
+	AttrHTTPStatusCode = "http.status_code"
+	AttrDBSystem       = "db.system"
+	AttrDBStatement    = "db.statement"
+	AttrDBOperation    = "db.operation"
+	AttrDBCollection   = "db.collection"
+	AttrCacheKey       = "cache.key"
+	AttrNodeID         = "node.id"
+	AttrError          = "error"
+)
@@ -0,0 +1,175 @@
+package tracer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recorder collects the exported batches
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]*Span
+}
+
+func (r *recorder) Export(spans []*Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, spans)
+	return nil
+}
+
+func (r *recorder) spans() []*Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var spans []*Span
+	for _, b := range r.batches {
+		spans = append(spans, b...)
+	}
+	return spans
+}
+
+// sampleRatio returns a pointer to the sample ratio of a config
+func sampleRatio(ratio float64) *float64 {
+	return &ratio
+}
+
+func TestTracerNotStarted(t *testing.T) {
+	Stop()
+	if Enabled() || Sample() {
+		t.Fatal("Tracer should not be enabled")
+	}
+	span := Start(SpanContext{}, "op", SpanKindInternal)
+	if span != nil {
+		t.Fatal("Span should not be started when the tracer is not started")
+	}
+	// the methods of a nil span are no-ops
+	span.SetAttribute("k", "v")
+	span.SetError(errors.New("failed"))
+	span.Finish()
+	if span.Context().IsValid() {
+		t.Fatal("Context of a nil span should not be valid")
+	}
+	if err := Init(Config{Enabled: false}, nil, nil); err != nil || Enabled() {
+		t.Fatalf("Disabled config should not start the tracer, err %v", err)
+	}
+}
+
+func TestTracerInvalidConfig(t *testing.T) {
+	defer Stop()
+	if err := Init(Config{Enabled: true}, nil, nil); err == nil {
+		t.Fatal("Init without an exporter should fail")
+	}
+	if err := Init(Config{Enabled: true, SampleRatio: sampleRatio(1.5)}, new(recorder), nil); err == nil {
+		t.Fatal("Init with a sample ratio above 1 should fail")
+	}
+}
+
+func TestTracerParentChild(t *testing.T) {
+	r := new(recorder)
+	if err := Init(Config{Enabled: true}, r, nil); err != nil {
+		t.Fatal(err)
+	}
+	root := Start(SpanContext{}, "root", SpanKindServer)
+	child := Start(root.Context(), "child", SpanKindClient)
+	if root.ParentID != "" || len(root.TraceID) != 32 || len(root.SpanID) != 16 {
+		t.Fatalf("Invalid root span %+v", root)
+	}
+	if child.TraceID != root.TraceID || child.ParentID != root.SpanID || child.SpanID == root.SpanID {
+		t.Fatalf("Child span %+v not linked to root %+v", child, root)
+	}
+	child.SetAttribute(AttrDBStatement, "select 1")
+	child.SetError(errors.New("failed"))
+	child.Finish()
+	child.SetAttribute("late", true)
+	child.Finish()
+	root.Finish()
+	Stop()
+
+	spans := r.spans()
+	if len(spans) != 2 || spans[0] != child || spans[1] != root {
+		t.Fatalf("Expected the child and the root spans exported once, got %v", spans)
+	}
+	if !child.Error || child.StatusMessage != "failed" || child.Attributes[AttrDBStatement] != "select 1" {
+		t.Fatalf("Invalid child span %+v", child)
+	}
+	if _, ok := child.Attributes["late"]; ok {
+		t.Fatal("Attribute set after the end should be ignored")
+	}
+	if child.Duration() < 0 {
+		t.Fatal("Duration should not be negative")
+	}
+}
+
+func TestTracerSampling(t *testing.T) {
+	r := new(recorder)
+	if err := Init(Config{Enabled: true, SampleRatio: sampleRatio(0)}, r, nil); err != nil {
+		t.Fatal(err)
+	}
+	if Sample() {
+		t.Fatal("Trace should not be sampled with a ratio of 0")
+	}
+	Start(SpanContext{}, "unsampled", SpanKindInternal).Finish()
+	// the decision of the caller is followed
+	parent := SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Sampled: true}
+	Start(parent, "sampled", SpanKindInternal).Finish()
+	StartWithContext(SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID()}, "", "unsampled",
+		SpanKindServer).Finish()
+	Stop()
+
+	spans := r.spans()
+	if len(spans) != 1 || spans[0].Name != "sampled" || spans[0].ParentID != parent.SpanID {
+		t.Fatalf("Expected only the sampled span exported, got %v", spans)
+	}
+}
+
+func TestTracerDefaultSampling(t *testing.T) {
+	defer Stop()
+	if err := Init(Config{Enabled: true}, new(recorder), nil); err != nil {
+		t.Fatal(err)
+	}
+	// all the new traces are sampled when the ratio is not specified
+	for i := 0; i < 100; i++ {
+		if !Sample() {
+			t.Fatal("Trace should be sampled without a sample ratio")
+		}
+	}
+}
+
+func TestTracerBatching(t *testing.T) {
+	r := new(recorder)
+	conf := Config{Enabled: true, BatchSize: 3, FlushIntervalInMs: 60000}
+	if err := Init(conf, r, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		Start(SpanContext{}, "op", SpanKindInternal).Finish()
+	}
+	Stop()
+
+	if len(r.batches) != 3 || len(r.batches[0]) != 3 || len(r.batches[1]) != 3 || len(r.batches[2]) != 1 {
+		t.Fatalf("Expected batches of 3, 3 and 1 spans, got %v", r.batches)
+	}
+	// spans ended after stop are not exported
+	Start(SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Sampled: true}, "op", SpanKindInternal).Finish()
+	if len(r.spans()) != 7 {
+		t.Fatal("Span ended after stop should not be exported")
+	}
+}
+
+func TestTracerExportError(t *testing.T) {
+	var errs []error
+	failing := ExporterFunc(func(spans []*Span) error {
+		return errors.New("collector down")
+	})
+	if err := Init(Config{Enabled: true}, failing, func(err error) {
+		errs = append(errs, err)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	Start(SpanContext{}, "op", SpanKindInternal).Finish()
+	Stop()
+	if len(errs) != 1 || errs[0].Error() != "collector down" {
+		t.Fatalf("Expected the export error reported, got %v", errs)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/tracer"
 )
 
 //Get makes an http get request with default header parameters
@@ -90,6 +91,14 @@ func httpExecuter(req *http.Request, resp *http.Response, headers map[string]str
 	for key, val := range headers {
 		req.Header.Add(key, val)
 	}
+	span := startClientSpan(req)
+	defer func() {
+		if ret.HTTPStatus != 0 {
+			span.SetAttribute(tracer.AttrHTTPStatusCode, int(ret.HTTPStatus))
+		}
+		span.SetError(err)
+		span.Finish()
+	}()
 	var client *http.Client
 	// set client
 	if isPoolSet() {
@@ -123,13 +132,31 @@ func httpExecuter(req *http.Request, resp *http.Response, headers map[string]str
 	// read body
 	body, berr := ioutil.ReadAll(resp.Body)
 	if berr != nil {
-		return nil, berr
+		err = berr
+		return nil, err
 	}
 	ret.Body = body
 	// return
 	return ret, err
 }
 
+// startClientSpan starts the span of the call as a child of the span in the traceparent header of the
+// request, if any, and passes the span on in the header so that the callee spans are its children
+func startClientSpan(req *http.Request) *tracer.Span {
+	tp, ok := ParseTraceParent(req.Header.Get(TraceParentHeader))
+	if !ok {
+		return nil
+	}
+	span := tracer.Start(tp.SpanContext(), "HTTP "+req.Method, tracer.SpanKindClient)
+	if span == nil {
+		return nil
+	}
+	span.SetAttribute(tracer.AttrHTTPMethod, req.Method)
+	span.SetAttribute(tracer.AttrHTTPURL, req.URL.String())
+	req.Header.Set(TraceParentHeader, NewTraceParent(span.Context()).String())
+	return span
+}
+
 // getReqWithBody returns http request for given url and body
 func getReqWithBody(name string, url string, body string) (req *http.Request, err error) {
 	req, err = http.NewRequest(name, url, strings.NewReader(body))
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+// spanRecorder collects the exported spans
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*tracer.Span
+}
+
+func (r *spanRecorder) Export(spans []*tracer.Span) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+// getSpan calls the handler with a sampled traceparent and returns the client span of the call
+func getSpan(t *testing.T, handler http.HandlerFunc) (*tracer.Span, error) {
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	r := new(spanRecorder)
+	if err := tracer.Init(tracer.Config{Enabled: true}, r, nil); err != nil {
+		t.Fatalf("Failed to start the tracer. Err : %s", err)
+	}
+	traceParent := "00-" + testTraceID + "-" + testParentID + "-01"
+	_, err := Get(srv.URL, map[string]string{TraceParentHeader: traceParent}, time.Second)
+	tracer.Stop()
+	if len(r.spans) != 1 {
+		t.Fatalf("Expected the client span to be exported, got %d spans", len(r.spans))
+	}
+	return r.spans[0], err
+}
+
+func TestClientSpan(t *testing.T) {
+	span, err := getSpan(t, func(w http.ResponseWriter, r *http.Request) {
+		if tp, ok := ParseTraceParent(r.Header.Get(TraceParentHeader)); !ok || tp.ParentID == testParentID {
+			t.Errorf("Client span not passed on to the callee, got %s", r.Header.Get(TraceParentHeader))
+		}
+		w.Write([]byte("ok"))
+	})
+	if err != nil || span.Error || span.Kind != tracer.SpanKindClient || span.ParentID != testParentID ||
+		span.Attributes[tracer.AttrHTTPStatusCode] != http.StatusOK {
+		t.Errorf("Unexpected span %+v. Err : %v", span, err)
+	}
+}
+
+func TestClientSpanBodyReadError(t *testing.T) {
+	span, err := getSpan(t, func(w http.ResponseWriter, r *http.Request) {
+		// the body is shorter than announced, failing its read
+		w.Header().Set("Content-Length", "10")
+		w.Write([]byte("abc"))
+	})
+	if err == nil {
+		t.Fatal("Expected the body read to fail")
+	}
+	if !span.Error || span.StatusMessage == "" {
+		t.Errorf("Failed body read not recorded in the span %+v", span)
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Conditional request and response headers
+const (
+	ETagHeader            = "ETag"
+	LastModifiedHeader    = "Last-Modified"
+	IfMatchHeader         = "If-Match"
+	IfNoneMatchHeader     = "If-None-Match"
+	IfModifiedSinceHeader = "If-Modified-Since"
+)
+
+// weakPrefix marks a weak entity tag
+const weakPrefix = "W/"
+
+// NewETag returns a strong entity tag from the hash of the data
+func NewETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// QuoteETag returns the version as an entity tag, quoting it unless it is already a quoted or a weak tag
+func QuoteETag(version string) string {
+	if strings.HasPrefix(version, weakPrefix) || (len(version) > 1 && strings.HasPrefix(version, `"`) &&
+		strings.HasSuffix(version, `"`)) {
+		return version
+	}
+	return `"` + version + `"`
+}
+
+// MatchETag checks if the entity tag is one of the tags of an If-Match or an If-None-Match header,
+// * matching any tag. The weak comparison ignores the weak prefix of the tags, the strong comparison
+// fails for weak tags
+func MatchETag(header string, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if !weak && strings.HasPrefix(etag, weakPrefix) {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, weakPrefix)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, weakPrefix) {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, weakPrefix)
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModifiedSince checks if the resource last modified at lastModified has not been modified since the
+// time of an If-Modified-Since header. Returns false if the header or the time is not valid
+func NotModifiedSince(header string, lastModified time.Time) bool {
+	if header == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	// the header has a precision of a second
+	return !lastModified.Truncate(time.Second).After(since)
+}
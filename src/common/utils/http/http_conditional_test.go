@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		header   string
+		etag     string
+		weak     bool
+		expected bool
+	}{
+		{`"a"`, `"a"`, false, true},
+		{`"a"`, `"b"`, false, false},
+		{`"b", "a"`, `"a"`, false, true},
+		{`"b","c"`, `"a"`, true, false},
+		{`*`, `"a"`, false, true},
+		{` * `, `W/"a"`, false, true},
+		{`*`, ``, true, false},
+		{`"a"`, ``, true, false},
+		{`W/"a"`, `"a"`, true, true},
+		{`W/"a"`, `"a"`, false, false},
+		{`"a"`, `W/"a"`, true, true},
+		{`"a"`, `W/"a"`, false, false},
+		{`W/"a"`, `W/"a"`, true, true},
+		{`W/"a"`, `W/"a"`, false, false},
+		{`W/"b", "a"`, `"a"`, false, true},
+		{`"a`, `"a"`, true, false},
+	}
+	for _, tt := range tests {
+		if got := MatchETag(tt.header, tt.etag, tt.weak); got != tt.expected {
+			t.Errorf("MatchETag(%s, %s, weak %v) = %v, expected %v", tt.header, tt.etag, tt.weak, got, tt.expected)
+		}
+	}
+}
+
+func TestNotModifiedSince(t *testing.T) {
+	lastModified := time.Date(2020, 1, 1, 10, 0, 0, 500000000, time.UTC)
+	tests := []struct {
+		header       string
+		lastModified time.Time
+		expected     bool
+	}{
+		// the fraction of a second of the modification time is not in the header
+		{lastModified.Format(http.TimeFormat), lastModified, true},
+		{lastModified.Add(time.Second).Format(http.TimeFormat), lastModified, true},
+		{lastModified.Add(-time.Second).Format(http.TimeFormat), lastModified, false},
+		{"Wednesday, 01-Jan-20 10:00:00 GMT", lastModified, true},
+		{"", lastModified, false},
+		{"yesterday", lastModified, false},
+		{lastModified.Format(http.TimeFormat), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		if got := NotModifiedSince(tt.header, tt.lastModified); got != tt.expected {
+			t.Errorf("NotModifiedSince(%q, %v) = %v, expected %v", tt.header, tt.lastModified, got, tt.expected)
+		}
+	}
+}
+
+func TestQuoteETag(t *testing.T) {
+	tests := map[string]string{
+		`v1`:     `"v1"`,
+		`"v1"`:   `"v1"`,
+		`W/"v1"`: `W/"v1"`,
+		`"`:      `"""`,
+		`"v1`:    `""v1"`,
+		``:       `""`,
+	}
+	for version, expected := range tests {
+		if got := QuoteETag(version); got != expected {
+			t.Errorf("QuoteETag(%s) = %s, expected %s", version, got, expected)
+		}
+	}
+}
+
+func TestNewETag(t *testing.T) {
+	etag := NewETag([]byte("data"))
+	if len(etag) != 34 || QuoteETag(etag) != etag || etag == NewETag([]byte("other")) {
+		t.Errorf("Unexpected entity tag %s", etag)
+	}
+}
@@ -5,18 +5,29 @@ import (
 )
 
 //GetHTTPHeaders returns a map of required headers
-//GetHTTPHeaders reads the header values from input request context. A new transaction id is
-//created for each call to this method
+//GetHTTPHeaders reads the header values from input request context, forwarding the request and transaction ids
+//unchanged so that the calls can be correlated. A new transaction id is created only if the context has none.
+//The traceparent header carries the trace of the request with its span as the parent
 func GetHTTPHeaders(rc *RequestContext) map[string]string {
 	if rc == nil {
 		return nil
 	}
 
-	headerMap := make(map[string]string, 4)
+	headerMap := make(map[string]string, 6)
 	chkNSetMap(CustomHeaderMap, headerMap, UserID, rc.UserID)
 	chkNSetMap(CustomHeaderMap, headerMap, SessionID, rc.SessionID)
 	chkNSetMap(CustomHeaderMap, headerMap, RequestID, rc.RequestID)
-	chkNSetMap(CustomHeaderMap, headerMap, TransactionID, GetTransactionID())
+	transactionID := rc.TransactionID
+	if transactionID == "" {
+		transactionID = GetTransactionID()
+	}
+	chkNSetMap(CustomHeaderMap, headerMap, TransactionID, transactionID)
+	if tp, ok := rc.GetTraceParent(); ok {
+		headerMap[TraceParentHeader] = tp.String()
+		if rc.TraceState != "" {
+			headerMap[TraceStateHeader] = rc.TraceState
+		}
+	}
 	return headerMap
 }
 
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"testing"
+)
+
+func TestGetHTTPHeaders(t *testing.T) {
+	rc := &RequestContext{UserID: "u1", SessionID: "s1", RequestID: "r1", TransactionID: "t1",
+		TraceID: testTraceID, SpanID: testParentID, TraceFlags: "01", TraceState: "vendor=value"}
+	headers := GetHTTPHeaders(rc)
+	expected := map[string]string{
+		CustomHeaderMap[UserID]:        "u1",
+		CustomHeaderMap[SessionID]:     "s1",
+		CustomHeaderMap[RequestID]:     "r1",
+		CustomHeaderMap[TransactionID]: "t1",
+		TraceParentHeader:              "00-" + testTraceID + "-" + testParentID + "-01",
+		TraceStateHeader:               "vendor=value",
+	}
+	for k, v := range expected {
+		if headers[k] != v {
+			t.Errorf("Expected header %s %q, got %q", k, v, headers[k])
+		}
+	}
+	if len(headers) != len(expected) {
+		t.Errorf("Unexpected headers %v", headers)
+	}
+
+	headers = GetHTTPHeaders(&RequestContext{TraceState: "vendor=value"})
+	if headers[CustomHeaderMap[TransactionID]] == "" {
+		t.Error("Transaction id not created for a context without one")
+	}
+	if _, found := headers[TraceParentHeader]; found {
+		t.Error("Traceparent sent without a trace")
+	}
+	if _, found := headers[TraceStateHeader]; found {
+		t.Error("Tracestate sent without a traceparent")
+	}
+
+	if GetHTTPHeaders(nil) != nil {
+		t.Error("Headers returned for no context")
+	}
+}
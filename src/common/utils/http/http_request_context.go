@@ -2,6 +2,8 @@ package http
 
 import (
 	"fmt"
+
+	"github.com/jabong/florest-core/src/common/tracer"
 )
 
 /*
@@ -16,11 +18,18 @@ type RequestContext struct {
 	URI           string
 	ClientAppID   string
 	TokenID       string
+	//TraceID identifies the trace of the request across services, SpanID the processing of the request by the
+	//service and ParentSpanID the caller. TraceFlags and TraceState are forwarded as received (W3C trace context)
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	TraceFlags   string
+	TraceState   string
 }
 
 //Implements the Stringer interface
 func (t RequestContext) String() string {
-	format := "[AppName : %s, UserID : %s, SessionID : %s, RequestID : %s, TransactionID : %s, TokenId : %s, URI : %s, ClientAppId : %s]"
+	format := "[AppName : %s, UserID : %s, SessionID : %s, RequestID : %s, TransactionID : %s, TokenId : %s, URI : %s, ClientAppId : %s, TraceID : %s]"
 	return fmt.Sprintf(format,
 		t.AppName,
 		t.UserID,
@@ -30,5 +39,20 @@ func (t RequestContext) String() string {
 		t.TokenID,
 		t.URI,
 		t.ClientAppID,
+		t.TraceID,
 	)
 }
+
+//GetTraceParent returns the traceparent to be sent on the outbound calls made while processing the request,
+//the span of the request being their parent
+func (t RequestContext) GetTraceParent() (TraceParent, bool) {
+	if t.TraceID == "" || t.SpanID == "" {
+		return TraceParent{}, false
+	}
+	return TraceParent{TraceID: t.TraceID, ParentID: t.SpanID, Flags: t.TraceFlags}, true
+}
+
+//GetSpanContext returns the span of the request, the parent of the spans recorded while processing it
+func (t RequestContext) GetSpanContext() tracer.SpanContext {
+	return tracer.SpanContext{TraceID: t.TraceID, SpanID: t.SpanID, Sampled: isSampled(t.TraceFlags)}
+}
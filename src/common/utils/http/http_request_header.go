@@ -5,6 +5,9 @@ import (
 	"strconv"
 )
 
+// IdempotencyKeyHeader carries the key the retries of a request are identified with
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 type RequestHeader struct {
 	ContentType   string
 	Accept        string
@@ -19,6 +22,15 @@ type RequestHeader struct {
 	BucketsList   string
 	Debug         bool
 	ClientAppID   string
+	TraceParent   string
+	TraceState    string
+	// Conditional request headers
+	IfMatch         string
+	IfNoneMatch     string
+	IfModifiedSince string
+	CacheControl    string
+	Pragma          string
+	IdempotencyKey  string
 }
 
 func GetReqHeader(req *http.Request) RequestHeader {
@@ -36,6 +48,15 @@ func GetReqHeader(req *http.Request) RequestHeader {
 		BucketsList:   req.Header.Get("bucket"),
 		Debug:         getBoolHeaderField(req, CustomHeaderMap[DebugFlag]),
 		ClientAppID:   req.Header.Get(CustomHeaderMap[AppID]),
+		TraceParent:   req.Header.Get(TraceParentHeader),
+		TraceState:    req.Header.Get(TraceStateHeader),
+		// Conditional request headers
+		IfMatch:         req.Header.Get(IfMatchHeader),
+		IfNoneMatch:     req.Header.Get(IfNoneMatchHeader),
+		IfModifiedSince: req.Header.Get(IfModifiedSinceHeader),
+		CacheControl:    req.Header.Get("Cache-Control"),
+		Pragma:          req.Header.Get("Pragma"),
+		IdempotencyKey:  req.Header.Get(IdempotencyKeyHeader),
 	}
 }
 
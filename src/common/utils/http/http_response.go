@@ -1,6 +1,8 @@
 package http
 
 import (
+	"time"
+
 	"github.com/jabong/florest-core/src/common/constants"
 )
 
@@ -10,8 +12,13 @@ type Debug struct {
 }
 
 type ResponseMetaData struct {
-	URLParams   map[string]interface{} `json:"urlParams"`
-	APIMetaData map[string]interface{} `json:"apiMetaData"`
+	URLParams     map[string]interface{} `json:"urlParams"`
+	APIMetaData   map[string]interface{} `json:"apiMetaData"`
+	ServedVersion string                 `json:"servedVersion,omitempty"`
+	// ETag is the version of the resource set by the nodes, used as the ETag of the response instead of the
+	// hash of the response data. LastModified is the time the resource was last modified, if known
+	ETag         string    `json:"-"`
+	LastModified time.Time `json:"-"`
 }
 
 // NewResponseMetaData creates and returns an instance of ResponseMetaData
@@ -29,6 +36,19 @@ type Response struct {
 	MetaData  *ResponseMetaData       `json:"_metaData,omitempty"`
 }
 
+// Problem represents an error response as a problem document (RFC 7807). Code, DeveloperMessage and
+// Errors are the extension members carrying the app errors
+type Problem struct {
+	Type             string                 `json:"type"`
+	Title            string                 `json:"title"`
+	Status           constants.HTTPCode     `json:"status"`
+	Detail           string                 `json:"detail,omitempty"`
+	Instance         string                 `json:"instance,omitempty"`
+	Code             constants.APPErrorCode `json:"code"`
+	DeveloperMessage string                 `json:"developerMessage,omitempty"`
+	Errors           []constants.AppError   `json:"errors,omitempty"`
+}
+
 // APIResponse represents a complete response containing HTTPStatus, Headers and Body
 type APIResponse struct {
 	HTTPStatus constants.HTTPCode
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/constants"
+)
+
+// Formats of the streamed responses
+const (
+	// StreamJSONLines streams json values separated by new lines (application/x-ndjson)
+	StreamJSONLines = "JSONLines"
+	// StreamSSE streams server sent events (text/event-stream)
+	StreamSSE = "SSE"
+)
+
+var streamContentTypes = map[string]string{
+	StreamJSONLines: "application/x-ndjson",
+	StreamSSE:       "text/event-stream",
+}
+
+// ErrStreamClosed is returned when writing to a stream whose client has gone away
+var ErrStreamClosed = errors.New("Stream closed by the client")
+
+// Event is a server sent event. Data is sent as is if it is a string, as json otherwise
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// Stream writes a response incrementally. The status and the headers are written along with the first
+// byte, until then the request can still fail with a regular error response. It is safe for concurrent use
+type Stream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	format  string
+	done    <-chan struct{}
+	headers map[string]string
+	started bool
+}
+
+// IsStreamFormat checks if the format is a known stream format
+func IsStreamFormat(format string) bool {
+	_, found := streamContentTypes[format]
+	return found
+}
+
+// NewStream creates a stream over the response writer. done is closed when the client goes away and the
+// headers are written along with the first byte, the content type being set as per the format
+func NewStream(w http.ResponseWriter, format string, done <-chan struct{}, headers map[string]string) (*Stream, error) {
+	if !IsStreamFormat(format) {
+		return nil, fmt.Errorf("Unknown stream format %s", format)
+	}
+	return &Stream{w: w, format: format, done: done, headers: headers}, nil
+}
+
+// Format returns the format of the stream
+func (s *Stream) Format() string {
+	return s.format
+}
+
+// Started checks if the first byte has been written
+func (s *Stream) Started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.started
+}
+
+// Done is closed when the client goes away
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Write writes the bytes as is. The bytes are buffered until Flush is called or the buffer fills up
+func (s *Stream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(p)
+}
+
+// WriteJSON writes the value as a json line. The line is buffered until Flush is called or the buffer fills up
+func (s *Stream) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.write(append(b, '\n'))
+	return err
+}
+
+// Send writes the event and flushes it
+func (s *Stream) Send(e Event) error {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + singleLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + singleLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString(fmt.Sprintf("retry: %d\n", e.Retry/time.Millisecond))
+	}
+	data, ok := e.Data.(string)
+	if !ok && e.Data != nil {
+		j, err := json.Marshal(e.Data)
+		if err != nil {
+			return err
+		}
+		data = string(j)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.write([]byte(b.String())); err != nil {
+		return err
+	}
+	s.flush()
+	return nil
+}
+
+// SendEvents sends the events of the channel until it is closed or the client goes away
+func (s *Stream) SendEvents(events <-chan Event) error {
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := s.Send(e); err != nil {
+				return err
+			}
+		case <-s.done:
+			return ErrStreamClosed
+		}
+	}
+}
+
+// WriteError writes the error status as the last record of the stream, as an error event for
+// server sent events and as a json line with the status otherwise
+func (s *Stream) WriteError(status constants.APPHttpStatus) error {
+	if s.format == StreamSSE {
+		return s.Send(Event{Event: "error", Data: status})
+	}
+	if err := s.WriteJSON(Response{Status: status}); err != nil {
+		return err
+	}
+	s.Flush()
+	return nil
+}
+
+// Flush sends the buffered bytes to the client
+func (s *Stream) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		s.flush()
+	}
+}
+
+func (s *Stream) write(p []byte) (int, error) {
+	select {
+	case <-s.done:
+		return 0, ErrStreamClosed
+	default:
+	}
+	if !s.started {
+		s.started = true
+		header := s.w.Header()
+		for k, v := range s.headers {
+			header.Set(k, v)
+		}
+		header.Set("Content-Type", streamContentTypes[s.format])
+		header.Set("Cache-Control", "no-cache")
+		// disables the response buffering of the proxies like nginx
+		header.Set("X-Accel-Buffering", "no")
+		header.Del("Content-Length")
+		s.w.WriteHeader(http.StatusOK)
+	}
+	return s.w.Write(p)
+}
+
+func (s *Stream) flush() {
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
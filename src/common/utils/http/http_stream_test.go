@@ -0,0 +1,176 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/constants"
+)
+
+func TestNewStream(t *testing.T) {
+	if _, err := NewStream(httptest.NewRecorder(), "XML", nil, nil); err == nil {
+		t.Error("Stream of an unknown format created")
+	}
+}
+
+func TestStreamSend(t *testing.T) {
+	rec := httptest.NewRecorder()
+	s, _ := NewStream(rec, StreamSSE, nil, map[string]string{"X-Request-Id": "1"})
+	if s.Started() {
+		t.Error("Stream started before the first byte")
+	}
+	err := s.Send(Event{ID: "1\n2", Event: "update", Data: "first\r\nsecond\nthird", Retry: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to send the event. Err : %s", err)
+	}
+	if err := s.Send(Event{Data: map[string]int{"count": 2}}); err != nil {
+		t.Fatalf("Failed to send the event. Err : %s", err)
+	}
+	if err := s.Send(Event{Event: "ping"}); err != nil {
+		t.Fatalf("Failed to send the event. Err : %s", err)
+	}
+
+	want := "id: 12\nevent: update\nretry: 2000\ndata: first\ndata: second\ndata: third\n\n" +
+		"data: {\"count\":2}\n\n" +
+		"event: ping\ndata: \n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("Events %q, want %q", got, want)
+	}
+	if !s.Started() || !rec.Flushed {
+		t.Error("Events not flushed")
+	}
+	for k, v := range map[string]string{"Content-Type": "text/event-stream", "Cache-Control": "no-cache",
+		"X-Accel-Buffering": "no", "X-Request-Id": "1"} {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("Header %s is %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestStreamWriteJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Length", "10")
+	s, _ := NewStream(rec, StreamJSONLines, nil, nil)
+	s.WriteJSON(map[string]int{"id": 1})
+	s.WriteJSON("two")
+	if rec.Flushed {
+		t.Error("Lines flushed before Flush")
+	}
+	s.Flush()
+	if got, want := rec.Body.String(), "{\"id\":1}\n\"two\"\n"; got != want {
+		t.Errorf("Lines %q, want %q", got, want)
+	}
+	if !rec.Flushed {
+		t.Error("Lines not flushed")
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content type %s, want application/x-ndjson", got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content length %s sent with the stream", got)
+	}
+	if err := s.WriteJSON(func() {}); err == nil {
+		t.Error("Value which can not be encoded written")
+	}
+}
+
+func TestStreamWriteError(t *testing.T) {
+	status := constants.APPHttpStatus{HTTPStatusCode: constants.HTTPStatusInternalServerErrorCode,
+		Errors: []constants.AppError{{Code: constants.InvalidErrorCode, Message: "Failed"}}}
+	encoded, _ := json.Marshal(status)
+
+	rec := httptest.NewRecorder()
+	s, _ := NewStream(rec, StreamJSONLines, nil, nil)
+	s.WriteJSON(1)
+	if err := s.WriteError(status); err != nil {
+		t.Fatalf("Failed to write the error. Err : %s", err)
+	}
+	if got, want := rec.Body.String(), "1\n{\"status\":"+string(encoded)+",\"data\":null}\n"; got != want {
+		t.Errorf("Lines %q, want %q", got, want)
+	}
+	if rec.Code != http.StatusOK || !rec.Flushed {
+		t.Errorf("Error written with status %d, flushed %v", rec.Code, rec.Flushed)
+	}
+
+	rec = httptest.NewRecorder()
+	s, _ = NewStream(rec, StreamSSE, nil, nil)
+	s.Send(Event{Data: "1"})
+	if err := s.WriteError(status); err != nil {
+		t.Fatalf("Failed to write the error. Err : %s", err)
+	}
+	if got, want := rec.Body.String(), "data: 1\n\nevent: error\ndata: "+string(encoded)+"\n\n"; got != want {
+		t.Errorf("Events %q, want %q", got, want)
+	}
+}
+
+func TestStreamClosed(t *testing.T) {
+	done := make(chan struct{})
+	rec := httptest.NewRecorder()
+	s, _ := NewStream(rec, StreamSSE, done, nil)
+	events := make(chan Event, 1)
+	events <- Event{Data: "1"}
+	close(done)
+	if _, err := s.Write([]byte("data: 1\n\n")); err != ErrStreamClosed {
+		t.Errorf("Write to a closed stream returned %v", err)
+	}
+	if err := s.SendEvents(events); err != ErrStreamClosed {
+		t.Errorf("Events sent to a closed stream returned %v", err)
+	}
+	if rec.Body.Len() != 0 || s.Started() {
+		t.Errorf("Written to a closed stream %q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	s, _ = NewStream(rec, StreamSSE, make(chan struct{}), nil)
+	events = make(chan Event, 2)
+	events <- Event{Data: "1"}
+	events <- Event{Data: "2"}
+	close(events)
+	if err := s.SendEvents(events); err != nil {
+		t.Fatalf("Failed to send the events. Err : %s", err)
+	}
+	if got, want := rec.Body.String(), "data: 1\n\ndata: 2\n\n"; got != want {
+		t.Errorf("Events %q, want %q", got, want)
+	}
+}
+
+func TestGzipFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	var flushed []byte
+	MakeGzipHandler(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := NewStream(w, StreamSSE, nil, nil)
+		s.Send(Event{Data: "1"})
+		flushed = append(flushed, rec.Body.Bytes()...)
+		s.Send(Event{Data: "2"})
+	})(rec, r)
+
+	if !rec.Flushed || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Gzipped stream flushed %v, content encoding %s", rec.Flushed, rec.Header().Get("Content-Encoding"))
+	}
+	// the first event is readable before the handler returns
+	gz, err := gzip.NewReader(bytes.NewReader(flushed))
+	if err != nil {
+		t.Fatalf("Failed to read the flushed bytes. Err : %s", err)
+	}
+	b, _ := ioutil.ReadAll(gz)
+	if got := string(b); got != "data: 1\n\n" {
+		t.Errorf("Flushed %q, want the first event", got)
+	}
+	gz, err = gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to read the response. Err : %s", err)
+	}
+	b, err = ioutil.ReadAll(gz)
+	if err != nil || !strings.HasSuffix(string(b), "data: 2\n\n") {
+		t.Errorf("Response %q, Err : %v", b, err)
+	}
+}
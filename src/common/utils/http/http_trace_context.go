@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+// W3C trace context headers
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// traceParentVersion is the version of the traceparent header generated
+const traceParentVersion = "00"
+
+// TraceFlagSampled is the trace flag marking the trace as sampled by the caller
+const TraceFlagSampled = "01"
+
+// TraceParent is the W3C traceparent header, version-traceid-parentid-flags
+type TraceParent struct {
+	TraceID  string
+	ParentID string
+	Flags    string
+}
+
+// ParseTraceParent parses the traceparent header, ok is false if it is not valid
+func ParseTraceParent(header string) (tp TraceParent, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" || (parts[0] == traceParentVersion && len(parts) != 4) {
+		return tp, false
+	}
+	tp = TraceParent{TraceID: parts[1], ParentID: parts[2], Flags: parts[3]}
+	if !isHex(tp.TraceID, 32) || isZero(tp.TraceID) || !isHex(tp.ParentID, 16) || isZero(tp.ParentID) ||
+		!isHex(tp.Flags, 2) {
+		return TraceParent{}, false
+	}
+	return tp, true
+}
+
+// String formats the traceparent header
+func (tp TraceParent) String() string {
+	flags := tp.Flags
+	if flags == "" {
+		flags = "00"
+	}
+	return traceParentVersion + "-" + tp.TraceID + "-" + tp.ParentID + "-" + flags
+}
+
+// NewTraceID returns a new random trace id, 16 bytes in lower case hex
+func NewTraceID() string {
+	return tracer.NewTraceID()
+}
+
+// NewSpanID returns a new random span id, 8 bytes in lower case hex
+func NewSpanID() string {
+	return tracer.NewSpanID()
+}
+
+// SpanContext returns the span identified by the traceparent
+func (tp TraceParent) SpanContext() tracer.SpanContext {
+	return tracer.SpanContext{TraceID: tp.TraceID, SpanID: tp.ParentID, Sampled: isSampled(tp.Flags)}
+}
+
+// NewTraceParent returns the traceparent identifying the span
+func NewTraceParent(sc tracer.SpanContext) TraceParent {
+	flags := "00"
+	if sc.Sampled {
+		flags = TraceFlagSampled
+	}
+	return TraceParent{TraceID: sc.TraceID, ParentID: sc.SpanID, Flags: flags}
+}
+
+// isSampled checks the sampled bit of the trace flags
+func isSampled(flags string) bool {
+	b, err := hex.DecodeString(flags)
+	return err == nil && len(b) == 1 && b[0]&1 == 1
+}
+
+// isHex checks if s is n lower case hex digits
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(id string) bool {
+	return strings.Trim(id, "0") == ""
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+const (
+	testTraceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentID = "00f067aa0ba902b7"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	valid := map[string]TraceParent{
+		"00-" + testTraceID + "-" + testParentID + "-01":        {TraceID: testTraceID, ParentID: testParentID, Flags: "01"},
+		" 00-" + testTraceID + "-" + testParentID + "-00 ":      {TraceID: testTraceID, ParentID: testParentID, Flags: "00"},
+		"01-" + testTraceID + "-" + testParentID + "-01":        {TraceID: testTraceID, ParentID: testParentID, Flags: "01"},
+		"cc-" + testTraceID + "-" + testParentID + "-01-future": {TraceID: testTraceID, ParentID: testParentID, Flags: "01"},
+	}
+	for header, expected := range valid {
+		tp, ok := ParseTraceParent(header)
+		if !ok || tp != expected {
+			t.Errorf("Expected %s to be parsed to %+v, got %+v %v", header, expected, tp, ok)
+		}
+	}
+
+	invalid := map[string]string{
+		"empty":                    "",
+		"version ff":               "ff-" + testTraceID + "-" + testParentID + "-01",
+		"invalid version":          "0g-" + testTraceID + "-" + testParentID + "-01",
+		"zero trace id":            "00-00000000000000000000000000000000-" + testParentID + "-01",
+		"zero parent id":           "00-" + testTraceID + "-0000000000000000-01",
+		"upper case trace id":      "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testParentID + "-01",
+		"upper case parent id":     "00-" + testTraceID + "-00F067AA0BA902B7-01",
+		"upper case flags":         "00-" + testTraceID + "-" + testParentID + "-0A",
+		"extra field on 00":        "00-" + testTraceID + "-" + testParentID + "-01-extra",
+		"missing flags":            "00-" + testTraceID + "-" + testParentID,
+		"short trace id":           "00-4bf92f3577b34da6a3ce929d0e0e473-" + testParentID + "-01",
+		"long parent id":           "00-" + testTraceID + "-00f067aa0ba902b70-01",
+		"invalid flags":            "00-" + testTraceID + "-" + testParentID + "-1",
+		"future version bad trace": "cc-" + testTraceID + "x-" + testParentID + "-01",
+	}
+	for name, header := range invalid {
+		if tp, ok := ParseTraceParent(header); ok || tp != (TraceParent{}) {
+			t.Errorf("Expected the traceparent with %s to be invalid, got %+v %v", name, tp, ok)
+		}
+	}
+}
+
+func TestTraceParentString(t *testing.T) {
+	tp := TraceParent{TraceID: testTraceID, ParentID: testParentID}
+	if s := tp.String(); s != "00-"+testTraceID+"-"+testParentID+"-00" {
+		t.Errorf("Unexpected traceparent %s", s)
+	}
+	tp = NewTraceParent(tracer.SpanContext{TraceID: testTraceID, SpanID: testParentID, Sampled: true})
+	if s := tp.String(); s != "00-"+testTraceID+"-"+testParentID+"-01" {
+		t.Errorf("Unexpected traceparent %s", s)
+	}
+	if sc := tp.SpanContext(); sc.TraceID != testTraceID || sc.SpanID != testParentID || !sc.Sampled {
+		t.Errorf("Unexpected span context %+v", sc)
+	}
+	if tp, ok := ParseTraceParent(tp.String()); !ok || tp.Flags != TraceFlagSampled {
+		t.Errorf("Generated traceparent not parsed, got %+v %v", tp, ok)
+	}
+}
+
+func TestNewIDs(t *testing.T) {
+	traceID, spanID := NewTraceID(), NewSpanID()
+	if !isHex(traceID, 32) || isZero(traceID) || !isHex(spanID, 16) || isZero(spanID) {
+		t.Errorf("Invalid ids %s %s", traceID, spanID)
+	}
+	if NewTraceID() == traceID || NewSpanID() == spanID {
+		t.Error("Ids are not random")
+	}
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+)
+
+// Default limits of the multipart uploads
+const (
+	DefaultMaxUploadPartSize  int64 = 10 << 20
+	DefaultMaxUploadTotalSize int64 = 32 << 20
+	DefaultMaxUploadMemory    int64 = 1 << 20
+)
+
+// UploadConfig limits the multipart uploads. The fields not specified take the default values
+type UploadConfig struct {
+	// MaxPartSize is the maximum size in bytes of a part
+	MaxPartSize int64
+	// MaxTotalSize is the maximum size in bytes of all the parts together
+	MaxTotalSize int64
+	// MaxMemory is the size in bytes beyond which a file is spooled to a temp file
+	MaxMemory int64
+	// TempDir is the directory of the spooled files, the default temp directory if not specified
+	TempDir string
+}
+
+// WithDefaults returns the config with the fields not specified taken from def, and then from the defaults
+func (c UploadConfig) WithDefaults(def UploadConfig) UploadConfig {
+	c.MaxPartSize = firstPositive(c.MaxPartSize, def.MaxPartSize, DefaultMaxUploadPartSize)
+	c.MaxTotalSize = firstPositive(c.MaxTotalSize, def.MaxTotalSize, DefaultMaxUploadTotalSize)
+	c.MaxMemory = firstPositive(c.MaxMemory, def.MaxMemory, DefaultMaxUploadMemory)
+	if c.TempDir == "" {
+		c.TempDir = def.TempDir
+	}
+	return c
+}
+
+func firstPositive(values ...int64) int64 {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// UploadLimitError is returned when an upload exceeds one of its limits
+type UploadLimitError struct {
+	// Part is the form name of the part exceeding the limit, empty if the total size is exceeded
+	Part  string
+	Limit int64
+}
+
+func (e *UploadLimitError) Error() string {
+	if e.Part == "" {
+		return fmt.Sprintf("Upload exceeds the total size limit of %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("Part %s exceeds the size limit of %d bytes", e.Part, e.Limit)
+}
+
+// UploadedFile is a file part of a multipart upload, held in memory or spooled to a temp file
+type UploadedFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Header      textproto.MIMEHeader
+	Size        int64
+	content     []byte
+	path        string
+}
+
+// Open returns a reader of the content of the file
+func (f *UploadedFile) Open() (io.ReadCloser, error) {
+	if f.path != "" {
+		return os.Open(f.path)
+	}
+	return ioutil.NopCloser(bytes.NewReader(f.content)), nil
+}
+
+// Path returns the path of the temp file the file is spooled to, empty if it is held in memory
+func (f *UploadedFile) Path() string {
+	return f.path
+}
+
+// MultipartForm is a parsed multipart upload
+type MultipartForm struct {
+	Values map[string][]string
+	Files  map[string][]*UploadedFile
+}
+
+// RemoveAll removes the temp files of the upload
+func (f *MultipartForm) RemoveAll() error {
+	var err error
+	for _, files := range f.Files {
+		for _, file := range files {
+			if file.path == "" {
+				continue
+			}
+			if rerr := os.Remove(file.path); rerr != nil && !os.IsNotExist(rerr) && err == nil {
+				err = rerr
+			}
+		}
+	}
+	return err
+}
+
+// IsMultipart checks if the request body is a multipart form
+func IsMultipart(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "multipart/form-data"
+}
+
+// ParseMultipart reads the multipart form of the request within the limits of the config. Files larger
+// than MaxMemory are spooled to temp files which the caller must remove with RemoveAll. The temp files
+// are removed if an error is returned. An *UploadLimitError is returned if a limit is exceeded
+func ParseMultipart(r *http.Request, conf UploadConfig) (*MultipartForm, error) {
+	conf = conf.WithDefaults(UploadConfig{})
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	form := &MultipartForm{Values: make(map[string][]string), Files: make(map[string][]*UploadedFile)}
+	remaining := conf.MaxTotalSize
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err == nil {
+			remaining, err = readPart(form, part, conf, remaining)
+		}
+		if err != nil {
+			form.RemoveAll()
+			return nil, err
+		}
+	}
+}
+
+// readPart adds the part to the form and returns the size remaining within the total size limit
+func readPart(form *MultipartForm, part *multipart.Part, conf UploadConfig, remaining int64) (int64, error) {
+	defer part.Close()
+	name := part.FormName()
+	if name == "" {
+		// the unnamed parts are drained, counting toward the total size
+		n, err := io.Copy(ioutil.Discard, io.LimitReader(part, remaining+1))
+		if err != nil {
+			return remaining, err
+		}
+		if n > remaining {
+			return remaining, &UploadLimitError{Limit: conf.MaxTotalSize}
+		}
+		return remaining - n, nil
+	}
+	limit, limitErr := conf.MaxPartSize, &UploadLimitError{Part: name, Limit: conf.MaxPartSize}
+	if remaining < limit {
+		limit, limitErr = remaining, &UploadLimitError{Limit: conf.MaxTotalSize}
+	}
+	// one byte more than the limit is read to detect the parts exceeding it
+	src := io.LimitReader(part, limit+1)
+
+	if part.FileName() == "" {
+		value, err := ioutil.ReadAll(src)
+		if err != nil {
+			return remaining, err
+		}
+		if int64(len(value)) > limit {
+			return remaining, limitErr
+		}
+		form.Values[name] = append(form.Values[name], string(value))
+		return remaining - int64(len(value)), nil
+	}
+
+	file := &UploadedFile{FieldName: name, FileName: part.FileName(), ContentType: part.Header.Get("Content-Type"),
+		Header: part.Header}
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, src, conf.MaxMemory+1)
+	if err != nil && err != io.EOF {
+		return remaining, err
+	}
+	if n <= conf.MaxMemory {
+		file.content, file.Size = buf.Bytes(), n
+	} else if err = spool(file, io.MultiReader(&buf, src), conf.TempDir); err != nil {
+		return remaining, err
+	}
+	if file.Size > limit {
+		if file.path != "" {
+			os.Remove(file.path)
+		}
+		return remaining, limitErr
+	}
+	form.Files[name] = append(form.Files[name], file)
+	return remaining - file.Size, nil
+}
+
+// spool writes the content of the file to a temp file
+func spool(file *UploadedFile, src io.Reader, dir string) error {
+	tmp, err := ioutil.TempFile(dir, "upload-")
+	if err != nil {
+		return err
+	}
+	file.path = tmp.Name()
+	file.Size, err = io.Copy(tmp, src)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file.path)
+		file.path = ""
+	}
+	return err
+}
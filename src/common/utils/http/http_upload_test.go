@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testPart is a part of a test upload, a file if fileName is set and unnamed if name is empty
+type testPart struct {
+	name     string
+	fileName string
+	content  string
+}
+
+// newUploadRequest returns a request uploading the parts
+func newUploadRequest(t *testing.T, parts ...testPart) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, p := range parts {
+		header := make(textproto.MIMEHeader)
+		switch {
+		case p.fileName != "":
+			header.Set("Content-Disposition", `form-data; name="`+p.name+`"; filename="`+p.fileName+`"`)
+			header.Set("Content-Type", "text/plain")
+		case p.name != "":
+			header.Set("Content-Disposition", `form-data; name="`+p.name+`"`)
+		}
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			t.Fatalf("Failed to create the part. Err : %s", err)
+		}
+		pw.Write([]byte(p.content))
+	}
+	w.Close()
+	r := httptest.NewRequest("POST", "/upload", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	return r
+}
+
+// newUploadDir returns an empty directory to spool the files to, removed once the test is done
+func newUploadDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "upload")
+	if err != nil {
+		t.Fatalf("Failed to create the temp directory. Err : %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// assertSpooled checks the number of files spooled to the directory
+func assertSpooled(t *testing.T, dir string, want int) {
+	t.Helper()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read the temp directory. Err : %s", err)
+	}
+	if len(files) != want {
+		t.Errorf("%d files spooled, want %d", len(files), want)
+	}
+}
+
+func readFile(t *testing.T, f *UploadedFile) string {
+	t.Helper()
+	r, err := f.Open()
+	if err != nil {
+		t.Fatalf("Failed to open %s. Err : %s", f.FileName, err)
+	}
+	defer r.Close()
+	b, _ := ioutil.ReadAll(r)
+	return string(b)
+}
+
+func TestParseMultipart(t *testing.T) {
+	dir := newUploadDir(t)
+	r := newUploadRequest(t, testPart{name: "title", content: "hello"}, testPart{name: "title", content: "world"},
+		testPart{name: "doc", fileName: "small.txt", content: "tiny"},
+		testPart{name: "doc", fileName: "large.txt", content: "larger than memory"},
+		testPart{content: "unnamed"})
+	if !IsMultipart(r) {
+		t.Fatal("Upload not detected as multipart")
+	}
+	form, err := ParseMultipart(r, UploadConfig{MaxMemory: 8, TempDir: dir})
+	if err != nil {
+		t.Fatalf("Failed to parse the upload. Err : %s", err)
+	}
+	if got := strings.Join(form.Values["title"], ","); got != "hello,world" || len(form.Values) != 1 {
+		t.Errorf("Values %v", form.Values)
+	}
+	docs := form.Files["doc"]
+	if len(docs) != 2 || len(form.Files) != 1 {
+		t.Fatalf("Files %v", form.Files)
+	}
+	small, large := docs[0], docs[1]
+	if small.Path() != "" || small.Size != 4 || small.FileName != "small.txt" || small.ContentType != "text/plain" ||
+		readFile(t, small) != "tiny" {
+		t.Errorf("File held in memory %+v", small)
+	}
+	if large.Path() == "" || large.Size != 18 || readFile(t, large) != "larger than memory" {
+		t.Errorf("File spooled %+v", large)
+	}
+	assertSpooled(t, dir, 1)
+
+	if err := form.RemoveAll(); err != nil {
+		t.Errorf("Failed to remove the spooled files. Err : %s", err)
+	}
+	assertSpooled(t, dir, 0)
+	if err := form.RemoveAll(); err != nil {
+		t.Errorf("Removing the removed files failed. Err : %s", err)
+	}
+
+	if _, err := ParseMultipart(httptest.NewRequest("POST", "/upload", strings.NewReader("a=b")),
+		UploadConfig{}); err == nil {
+		t.Error("Body which is not multipart parsed")
+	}
+}
+
+func TestParseMultipartLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []testPart
+		conf  UploadConfig
+		part  string
+		limit int64
+	}{
+		{"value larger than a part", []testPart{{name: "title", content: "hello"}},
+			UploadConfig{MaxPartSize: 4}, "title", 4},
+		{"file larger than a part", []testPart{{name: "doc", fileName: "a.txt", content: "hello"}},
+			UploadConfig{MaxPartSize: 4}, "doc", 4},
+		{"spooled file larger than a part", []testPart{{name: "doc", fileName: "a.txt", content: "hello world"}},
+			UploadConfig{MaxPartSize: 8, MaxMemory: 2}, "doc", 8},
+		{"parts larger than the total", []testPart{{name: "doc", fileName: "a.txt", content: "hello"},
+			{name: "doc", fileName: "b.txt", content: "world"}},
+			UploadConfig{MaxPartSize: 8, MaxTotalSize: 8, MaxMemory: 2}, "", 8},
+		{"value beyond the total", []testPart{{name: "doc", fileName: "a.txt", content: "hello"},
+			{name: "title", content: "world"}},
+			UploadConfig{MaxPartSize: 8, MaxTotalSize: 8, MaxMemory: 2}, "", 8},
+		{"unnamed part larger than the total", []testPart{{content: "hello world"}},
+			UploadConfig{MaxTotalSize: 8}, "", 8},
+		{"unnamed part counted in the total", []testPart{{content: "hello"},
+			{name: "doc", fileName: "a.txt", content: "world"}},
+			UploadConfig{MaxTotalSize: 8, MaxMemory: 2}, "", 8},
+	}
+	for _, test := range tests {
+		dir := newUploadDir(t)
+		test.conf.TempDir = dir
+		form, err := ParseMultipart(newUploadRequest(t, test.parts...), test.conf)
+		limitErr, ok := err.(*UploadLimitError)
+		if !ok || limitErr.Part != test.part || limitErr.Limit != test.limit {
+			t.Errorf("%s: got %v %v, want the limit %d of part %q", test.name, form, err, test.limit, test.part)
+		}
+		// the files spooled before the limit is exceeded are removed
+		assertSpooled(t, dir, 0)
+	}
+
+	form, err := ParseMultipart(newUploadRequest(t, testPart{content: "hello"}, testPart{name: "title", content: "abc"}),
+		UploadConfig{MaxTotalSize: 8})
+	if err != nil || form.Values["title"][0] != "abc" {
+		t.Errorf("Upload within the total limit got %v %v", form, err)
+	}
+}
+
+func TestUploadConfigWithDefaults(t *testing.T) {
+	conf := UploadConfig{MaxPartSize: 1}.WithDefaults(UploadConfig{MaxPartSize: 2, MaxTotalSize: 3, TempDir: "/tmp"})
+	want := UploadConfig{MaxPartSize: 1, MaxTotalSize: 3, MaxMemory: DefaultMaxUploadMemory, TempDir: "/tmp"}
+	if conf != want {
+		t.Errorf("Config %+v, want %+v", conf, want)
+	}
+	conf = UploadConfig{TempDir: "/var/tmp"}.WithDefaults(UploadConfig{TempDir: "/tmp"})
+	want = UploadConfig{MaxPartSize: DefaultMaxUploadPartSize, MaxTotalSize: DefaultMaxUploadTotalSize,
+		MaxMemory: DefaultMaxUploadMemory, TempDir: "/var/tmp"}
+	if conf != want {
+		t.Errorf("Config %+v, want %+v", conf, want)
+	}
+}
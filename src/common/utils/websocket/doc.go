@@ -0,0 +1,3 @@
+// Package websocket implements the server side of the WebSocket protocol (RFC 6455) - the opening handshake,
+// framing, fragmented messages, ping/pong keepalive and the closing handshake
+package websocket
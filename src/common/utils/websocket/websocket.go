@@ -0,0 +1,432 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message types, the opcodes of the frames
+const (
+	continuationFrame = 0
+	TextMessage       = 1
+	BinaryMessage     = 2
+	CloseMessage      = 8
+	PingMessage       = 9
+	PongMessage       = 10
+)
+
+// Close codes, RFC 6455 section 7.4.1
+const (
+	CloseNormalClosure      = 1000
+	CloseGoingAway          = 1001
+	CloseProtocolError      = 1002
+	CloseUnsupportedData    = 1003
+	CloseNoStatusReceived   = 1005
+	CloseInvalidPayloadData = 1007
+	ClosePolicyViolation    = 1008
+	CloseMessageTooBig      = 1009
+	CloseInternalServerErr  = 1011
+)
+
+// acceptGUID is appended to the key of the client to compute the accept key of the handshake
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxControlPayload is the maximum payload size of the control frames
+const maxControlPayload = 125
+
+// Defaults of the connection config
+const (
+	DefaultMaxMessageSize int64 = 64 << 10
+	DefaultPingInterval         = 30 * time.Second
+	DefaultPongWait             = 60 * time.Second
+	DefaultWriteWait            = 10 * time.Second
+)
+
+// Config of a connection. The fields not specified take the default values
+type Config struct {
+	// MaxMessageSize is the maximum size in bytes of a message, the connection is closed with
+	// CloseMessageTooBig if a message exceeds it
+	MaxMessageSize int64
+	// PingInterval is the interval at which the client is pinged
+	PingInterval time.Duration
+	// PongWait is the time within which a frame, typically the pong to a ping, must be received from
+	// the client or the connection is closed
+	PongWait time.Duration
+	// WriteWait is the time within which a frame must be written
+	WriteWait time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = DefaultMaxMessageSize
+	}
+	if c.PingInterval <= 0 {
+		c.PingInterval = DefaultPingInterval
+	}
+	if c.PongWait <= 0 {
+		c.PongWait = DefaultPongWait
+	}
+	if c.WriteWait <= 0 {
+		c.WriteWait = DefaultWriteWait
+	}
+	return c
+}
+
+// HandshakeError is returned when a request is not a valid opening handshake
+type HandshakeError struct {
+	message string
+}
+
+func (e *HandshakeError) Error() string {
+	return e.message
+}
+
+// CloseError is returned by ReadMessage once the connection is closed, by either of the endpoints
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("WebSocket closed with code %d %s", e.Code, e.Text)
+}
+
+// ErrClosed is returned when writing to a closed connection
+var ErrClosed = errors.New("WebSocket connection closed")
+
+// IsUpgradeRequest checks if the request asks for an upgrade to the WebSocket protocol
+func IsUpgradeRequest(r *http.Request) bool {
+	return headerContains(r.Header, "Upgrade", "websocket") && headerContains(r.Header, "Connection", "upgrade")
+}
+
+// CheckHandshake checks if the request is a valid opening handshake, RFC 6455 section 4.2.1
+func CheckHandshake(r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return &HandshakeError{"WebSocket handshake must be a GET request"}
+	}
+	if !IsUpgradeRequest(r) {
+		return &HandshakeError{"WebSocket handshake must request an upgrade to websocket"}
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return &HandshakeError{"WebSocket version not supported, expected 13"}
+	}
+	key, err := base64.StdEncoding.DecodeString(r.Header.Get("Sec-WebSocket-Key"))
+	if err != nil || len(key) != 16 {
+		return &HandshakeError{"Invalid Sec-WebSocket-Key"}
+	}
+	return nil
+}
+
+// Upgrade completes the opening handshake of the request and returns the connection. The response writer
+// must not be used after a successful upgrade. The client is pinged as per the config until the
+// connection is closed
+func Upgrade(w http.ResponseWriter, r *http.Request, conf Config) (*Conn, error) {
+	if err := CheckHandshake(r); err != nil {
+		return nil, err
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("Response writer does not support hijacking the connection")
+	}
+	netConn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	c := newConn(netConn, rw.Reader, true, conf)
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n"
+	netConn.SetWriteDeadline(time.Now().Add(c.conf.WriteWait))
+	if _, err := netConn.Write([]byte(response)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	netConn.SetWriteDeadline(time.Time{})
+	go c.keepAlive()
+	return c, nil
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerContains checks if the comma separated values of the header contain the token, case insensitively
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Conn is a WebSocket connection. Messages must be read from a single goroutine, writes are safe for
+// concurrent use
+type Conn struct {
+	conn      net.Conn
+	br        *bufio.Reader
+	conf      Config
+	isServer  bool
+	wmu       sync.Mutex
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, isServer bool, conf Config) *Conn {
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+	return &Conn{conn: conn, br: br, conf: conf.withDefaults(), isServer: isServer, done: make(chan struct{})}
+}
+
+// RemoteAddr returns the address of the client
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// Done is closed once the connection is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// ReadMessage reads the next text or binary message, assembling its fragments. The control frames received
+// in the meantime are handled - pings are answered with pongs and a close frame closes the connection.
+// A *CloseError is returned once the connection is closed
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(c.conf.PongWait))
+		f, err := c.readFrame(c.conf.MaxMessageSize - int64(len(p)))
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+		switch f.opcode {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, f.payload); err != nil {
+				return 0, nil, c.fail(err)
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			return 0, nil, c.closeReceived(f.payload)
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(&CloseError{CloseProtocolError, "Message started before the previous ended"})
+			}
+			messageType = f.opcode
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(&CloseError{CloseProtocolError, "Continuation frame without a message"})
+			}
+		default:
+			return 0, nil, c.fail(&CloseError{CloseProtocolError, fmt.Sprintf("Unknown opcode %d", f.opcode)})
+		}
+		p = append(p, f.payload...)
+		if !f.fin {
+			continue
+		}
+		if messageType == TextMessage && !utf8.Valid(p) {
+			return 0, nil, c.fail(&CloseError{CloseInvalidPayloadData, "Text message is not valid utf-8"})
+		}
+		return messageType, p, nil
+	}
+}
+
+// WriteMessage writes the message as a single frame
+func (c *Conn) WriteMessage(messageType int, p []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("Invalid message type %d", messageType)
+	}
+	return c.writeFrame(messageType, p)
+}
+
+// Close sends a close frame with the code and the text, and closes the connection
+func (c *Conn) Close(code int, text string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.writeFrame(CloseMessage, closePayload(code, text))
+		close(c.done)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// fail closes the connection for the error and returns the error to be reported by ReadMessage
+func (c *Conn) fail(err error) error {
+	closeErr, ok := err.(*CloseError)
+	if !ok {
+		// the connection is broken or timed out, a close frame can not be delivered
+		c.closeOnce.Do(func() {
+			close(c.done)
+			c.conn.Close()
+		})
+		return &CloseError{Code: CloseGoingAway, Text: err.Error()}
+	}
+	c.Close(closeErr.Code, closeErr.Text)
+	return closeErr
+}
+
+// closeReceived echoes the close frame of the client and closes the connection
+func (c *Conn) closeReceived(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	if len(payload) >= 2 {
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Text = string(payload[2:])
+	}
+	code := closeErr.Code
+	if code == CloseNoStatusReceived {
+		code = CloseNormalClosure
+	}
+	c.Close(code, "")
+	return closeErr
+}
+
+func closePayload(code int, text string) []byte {
+	if len(text) > maxControlPayload-2 {
+		text = text[:maxControlPayload-2]
+	}
+	payload := make([]byte, 2, 2+len(text))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	return append(payload, text...)
+}
+
+// keepAlive pings the client at the configured interval until the connection is closed
+func (c *Conn) keepAlive() {
+	ticker := time.NewTicker(c.conf.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.writeFrame(PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+type frame struct {
+	fin     bool
+	opcode  int
+	payload []byte
+}
+
+// readFrame reads a frame, limit is the maximum payload size of a data frame
+func (c *Conn) readFrame(limit int64) (frame, error) {
+	var f frame
+	var header [8]byte
+	if _, err := io.ReadFull(c.br, header[:2]); err != nil {
+		return f, err
+	}
+	f.fin = header[0]&0x80 != 0
+	f.opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	if header[0]&0x70 != 0 {
+		return f, &CloseError{CloseProtocolError, "Reserved bits must not be set"}
+	}
+	switch length {
+	case 126:
+		if _, err := io.ReadFull(c.br, header[:2]); err != nil {
+			return f, err
+		}
+		length = uint64(binary.BigEndian.Uint16(header[:2]))
+	case 127:
+		if _, err := io.ReadFull(c.br, header[:8]); err != nil {
+			return f, err
+		}
+		length = binary.BigEndian.Uint64(header[:8])
+	}
+	isControl := f.opcode >= CloseMessage
+	if isControl && (length > maxControlPayload || !f.fin) {
+		return f, &CloseError{CloseProtocolError, "Invalid control frame"}
+	}
+	if masked != c.isServer {
+		return f, &CloseError{CloseProtocolError, "Frames from the client must be masked"}
+	}
+	if !isControl && length > uint64(limit) {
+		return f, &CloseError{CloseMessageTooBig, fmt.Sprintf("Message exceeds %d bytes", c.conf.MaxMessageSize)}
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, key[:]); err != nil {
+			return f, err
+		}
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, f.payload); err != nil {
+		return f, err
+	}
+	if masked {
+		maskBytes(key, f.payload)
+	}
+	return f, nil
+}
+
+// writeFrame writes the payload as a single frame, masked if written by a client
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+	buf := make([]byte, 0, 14+len(payload))
+	buf = append(buf, 0x80|byte(opcode))
+	var maskBit byte
+	if !c.isServer {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xffff:
+		buf = append(buf, maskBit|126, byte(n>>8), byte(n))
+	default:
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(n))
+		buf = append(append(buf, maskBit|127), length[:]...)
+	}
+	if c.isServer {
+		buf = append(buf, payload...)
+	} else {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		buf = append(buf, key[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		maskBytes(key, buf[start:])
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(c.conf.WriteWait))
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i%4]
+	}
+}
@@ -0,0 +1,113 @@
+package websocket
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoServer echoes the messages until the connection is closed
+func echoServer(t *testing.T, conf Config) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r, conf)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for {
+			messageType, p, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			c.WriteMessage(messageType, p)
+		}
+	}))
+}
+
+// dial performs the opening handshake and returns the client end of the connection
+func dial(t *testing.T, url string) *Conn {
+	netConn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to connect. Err : %s", err)
+	}
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	netConn.Write([]byte("GET /chat HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	br := bufio.NewReader(netConn)
+	res, err := http.ReadResponse(br, nil)
+	if err != nil || res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Handshake failed, got %v %v", res, err)
+	}
+	if accept := res.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Accept key mismatch, got %s", accept)
+	}
+	return newConn(netConn, br, false, Config{})
+}
+
+/*
+Test the handshake validation
+*/
+func TestCheckHandshake(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/chat", nil)
+	r.Header.Set("Upgrade", "WebSocket")
+	r.Header.Set("Connection", "keep-alive, Upgrade")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if err := CheckHandshake(r); err != nil {
+		t.Errorf("Unexpected handshake error %s", err)
+	}
+	r.Header.Set("Sec-WebSocket-Version", "8")
+	if err := CheckHandshake(r); err == nil {
+		t.Error("Expected error for an unsupported version")
+	}
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Del("Upgrade")
+	if err := CheckHandshake(r); err == nil || IsUpgradeRequest(r) {
+		t.Error("Expected error for a request without upgrade")
+	}
+}
+
+/*
+Test messages, fragments and control frames on a connection
+*/
+func TestConn(t *testing.T) {
+	server := echoServer(t, Config{MaxMessageSize: 1024})
+	defer server.Close()
+	client := dial(t, server.URL)
+	client.conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	client.WriteMessage(TextMessage, []byte("hello"))
+	if messageType, p, err := client.ReadMessage(); err != nil || messageType != TextMessage || string(p) != "hello" {
+		t.Errorf("Echo mismatch, got %d %s %v", messageType, p, err)
+	}
+
+	// a fragmented message interleaved with a ping
+	client.writeRaw(false, BinaryMessage, []byte("ab"))
+	client.writeFrame(PingMessage, []byte("p"))
+	client.writeRaw(true, continuationFrame, []byte("cd"))
+	if messageType, p, err := client.ReadMessage(); err != nil || messageType != BinaryMessage || string(p) != "abcd" {
+		t.Errorf("Fragmented echo mismatch, got %d %s %v", messageType, p, err)
+	}
+
+	client.WriteMessage(TextMessage, make([]byte, 2048))
+	_, _, err := client.ReadMessage()
+	if closeErr, ok := err.(*CloseError); !ok || closeErr.Code != CloseMessageTooBig {
+		t.Errorf("Expected close for a message too big, got %v", err)
+	}
+}
+
+// writeRaw writes a frame with the fin bit as given, to test fragmentation
+func (c *Conn) writeRaw(fin bool, opcode int, payload []byte) {
+	var key [4]byte
+	masked := append([]byte{}, payload...)
+	maskBytes(key, masked)
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	c.conn.Write(append([]byte{first, 0x80 | byte(len(payload)), 0, 0, 0, 0}, masked...))
+}
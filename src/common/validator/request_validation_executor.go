@@ -37,6 +37,9 @@ func (n RequestValidationExecutor) Execute(data workflow.WorkFlowData) (workflow
 			n.createAppErr(prmVldErr, appErrs)
 		}
 	}
+	if len(appErrs.Errors) == 0 {
+		return data, nil
+	}
 	return data, appErrs
 }
 
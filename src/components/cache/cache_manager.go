@@ -35,3 +35,12 @@ func Get(key string) (CInterface, error) {
 		return val.(CInterface), nil
 	}
 }
+
+// Keys() - returns the keys of the caches set
+func Keys() []string {
+	keys := make([]string, 0, cacheMap.Size())
+	for _, k := range cacheMap.Keys() {
+		keys = append(keys, k.(string))
+	}
+	return keys
+}
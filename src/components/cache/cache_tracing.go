@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+// tracedCache records a span for each operation of the cache, as a child of the parent span
+type tracedCache struct {
+	CInterface
+	parent tracer.SpanContext
+}
+
+// WithTrace returns the cache recording its operations as children of parent, e.g. the span of the request
+// got with RequestContext.GetSpanContext(). The cache is returned as is if the tracer is not started
+func WithTrace(c CInterface, parent tracer.SpanContext) CInterface {
+	if c == nil || !tracer.Enabled() {
+		return c
+	}
+	return &tracedCache{CInterface: c, parent: parent}
+}
+
+func (c *tracedCache) start(operation string, key string) *tracer.Span {
+	span := tracer.Start(c.parent, "cache "+operation, tracer.SpanKindClient)
+	span.SetAttribute(tracer.AttrDBOperation, operation)
+	span.SetAttribute(tracer.AttrCacheKey, key)
+	return span
+}
+
+func finish(span *tracer.Span, err error) {
+	span.SetError(err)
+	span.Finish()
+}
+
+func (c *tracedCache) Get(key string, serialize bool, compress bool) (*Item, error) {
+	span := c.start("Get", key)
+	item, err := c.CInterface.Get(key, serialize, compress)
+	finish(span, err)
+	return item, err
+}
+
+func (c *tracedCache) Set(item Item, serialize bool, compress bool) error {
+	span := c.start("Set", item.Key)
+	err := c.CInterface.Set(item, serialize, compress)
+	finish(span, err)
+	return err
+}
+
+func (c *tracedCache) SetWithTimeout(item Item, serialize bool, compress bool, ttl int32) error {
+	span := c.start("SetWithTimeout", item.Key)
+	err := c.CInterface.SetWithTimeout(item, serialize, compress, ttl)
+	finish(span, err)
+	return err
+}
+
+func (c *tracedCache) Delete(key string) error {
+	span := c.start("Delete", key)
+	err := c.CInterface.Delete(key)
+	finish(span, err)
+	return err
+}
+
+func (c *tracedCache) DeleteBatch(keys []string) error {
+	span := c.start("DeleteBatch", strings.Join(keys, ","))
+	err := c.CInterface.DeleteBatch(keys)
+	finish(span, err)
+	return err
+}
+
+func (c *tracedCache) GetBatch(keys []string, serialize bool, compress bool) (map[string]*Item, error) {
+	span := c.start("GetBatch", strings.Join(keys, ","))
+	items, err := c.CInterface.GetBatch(keys, serialize, compress)
+	finish(span, err)
+	return items, err
+}
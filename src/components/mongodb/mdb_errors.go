@@ -19,6 +19,7 @@ const (
 	ErrUpdateFailure  = "Failure in Update() method"
 	ErrUpsertFailure  = "Failure in Upsert() method"
 	ErrRemoveFailure  = "Failure in Remove() method"
+	ErrPingFailure    = "Failure in Ping() method"
 	ErrKeyPresent     = "Key is already present"
 	ErrKeyNotPresent  = "Key is not present"
 	ErrWrongType      = "Incorrect type sent"
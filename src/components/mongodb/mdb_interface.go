@@ -43,4 +43,7 @@ type MDBInterface interface {
 
 	// CloseMasterSession closes the master session
 	CloseMasterSession()
+
+	// Ping checks the connection
+	Ping() *MDBError
 }
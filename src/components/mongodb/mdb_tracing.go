@@ -0,0 +1,126 @@
+package mongodb
+
+import (
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+// dbSystem is the db.system attribute of the spans
+const dbSystem = "mongodb"
+
+// tracedMDB records a span for each operation on the collections, as a child of the parent span
+type tracedMDB struct {
+	MDBInterface
+	parent tracer.SpanContext
+}
+
+// WithTrace returns the mongodb recording its operations as children of parent, e.g. the span of the
+// request got with RequestContext.GetSpanContext(). The db is returned as is if the tracer is not started
+func WithTrace(db MDBInterface, parent tracer.SpanContext) MDBInterface {
+	if db == nil || !tracer.Enabled() {
+		return db
+	}
+	return &tracedMDB{MDBInterface: db, parent: parent}
+}
+
+func (db *tracedMDB) start(operation string, collection string) *tracer.Span {
+	span := tracer.Start(db.parent, "mongodb "+operation+" "+collection, tracer.SpanKindClient)
+	span.SetAttribute(tracer.AttrDBSystem, dbSystem)
+	span.SetAttribute(tracer.AttrDBOperation, operation)
+	span.SetAttribute(tracer.AttrDBCollection, collection)
+	return span
+}
+
+func finish(span *tracer.Span, err *MDBError) {
+	if err != nil {
+		span.SetError(err)
+	}
+	span.Finish()
+}
+
+func (db *tracedMDB) FindOne(collection string, query map[string]interface{}) (interface{}, *MDBError) {
+	span := db.start("FindOne", collection)
+	res, err := db.MDBInterface.FindOne(collection, query)
+	finish(span, err)
+	return res, err
+}
+
+func (db *tracedMDB) FindOneUsingSession(s *MSession, collection string,
+	query map[string]interface{}) (interface{}, *MDBError) {
+	span := db.start("FindOne", collection)
+	res, err := db.MDBInterface.FindOneUsingSession(s, collection, query)
+	finish(span, err)
+	return res, err
+}
+
+func (db *tracedMDB) FindAll(collection string, query map[string]interface{}) ([]interface{}, *MDBError) {
+	span := db.start("FindAll", collection)
+	res, err := db.MDBInterface.FindAll(collection, query)
+	finish(span, err)
+	return res, err
+}
+
+func (db *tracedMDB) FindAllUsingSession(s *MSession, collection string,
+	query map[string]interface{}) ([]interface{}, *MDBError) {
+	span := db.start("FindAll", collection)
+	res, err := db.MDBInterface.FindAllUsingSession(s, collection, query)
+	finish(span, err)
+	return res, err
+}
+
+func (db *tracedMDB) Insert(collection string, value interface{}) *MDBError {
+	span := db.start("Insert", collection)
+	err := db.MDBInterface.Insert(collection, value)
+	finish(span, err)
+	return err
+}
+
+func (db *tracedMDB) InsertUsingSession(s *MSession, collection string, value interface{}) *MDBError {
+	span := db.start("Insert", collection)
+	err := db.MDBInterface.InsertUsingSession(s, collection, value)
+	finish(span, err)
+	return err
+}
+
+func (db *tracedMDB) Update(collection string, query map[string]interface{}, value interface{}) *MDBError {
+	span := db.start("Update", collection)
+	err := db.MDBInterface.Update(collection, query, value)
+	finish(span, err)
+	return err
+}
+
+func (db *tracedMDB) UpdateUsingSession(s *MSession, collection string, query map[string]interface{},
+	value interface{}) *MDBError {
+	span := db.start("Update", collection)
+	err := db.MDBInterface.UpdateUsingSession(s, collection, query, value)
+	finish(span, err)
+	return err
+}
+
+func (db *tracedMDB) Upsert(collection string, query map[string]interface{}, value interface{}) *MDBError {
+	span := db.start("Upsert", collection)
+	err := db.MDBInterface.Upsert(collection, query, value)
+	finish(span, err)
+	return err
+}
+
+func (db *tracedMDB) UpsertUsingSession(s *MSession, collection string, query map[string]interface{},
+	value interface{}) *MDBError {
+	span := db.start("Upsert", collection)
+	err := db.MDBInterface.UpsertUsingSession(s, collection, query, value)
+	finish(span, err)
+	return err
+}
+
+func (db *tracedMDB) Remove(collection string, query map[string]interface{}) *MDBError {
+	span := db.start("Remove", collection)
+	err := db.MDBInterface.Remove(collection, query)
+	finish(span, err)
+	return err
+}
+
+func (db *tracedMDB) RemoveUsingSession(s *MSession, collection string, query map[string]interface{}) *MDBError {
+	span := db.start("Remove", collection)
+	err := db.MDBInterface.RemoveUsingSession(s, collection, query)
+	finish(span, err)
+	return err
+}
@@ -57,6 +57,16 @@ func (obj *MongoDriver) CloseMasterSession() {
 	obj.session.Close()
 }
 
+// Ping checks the connection with a copy of the master session
+func (obj *MongoDriver) Ping() *MDBError {
+	session := obj.session.Copy()
+	defer session.Close()
+	if err := session.Ping(); err != nil {
+		return getErrObj(ErrPingFailure, err.Error())
+	}
+	return nil
+}
+
 // FindOne queries the mongo DB using the session and returns only single result/collection
 func (obj *MongoDriver) FindOneUsingSession(session *MSession, collection string, query map[string]interface{}) (ret interface{}, aerr *MDBError) {
 	sess := session.mgoSession
@@ -35,3 +35,12 @@ func Get(key string) (MDBInterface, *MDBError) {
 		return val.(MDBInterface), nil
 	}
 }
+
+// Keys() - returns the keys of the mongodbs set
+func Keys() []string {
+	keys := make([]string, 0, mongoMap.Size())
+	for _, k := range mongoMap.Keys() {
+		keys = append(keys, k.(string))
+	}
+	return keys
+}
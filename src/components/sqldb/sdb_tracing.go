@@ -0,0 +1,50 @@
+package sqldb
+
+import (
+	"database/sql"
+
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+// tracedSDB records a span for each query and execution of the sql db, as a child of the parent span
+type tracedSDB struct {
+	SDBInterface
+	parent tracer.SpanContext
+}
+
+// WithTrace returns the sql db recording its statements as children of parent, e.g. the span of the request
+// got with RequestContext.GetSpanContext(). The db is returned as is if the tracer is not started
+func WithTrace(db SDBInterface, parent tracer.SpanContext) SDBInterface {
+	if db == nil || !tracer.Enabled() {
+		return db
+	}
+	return &tracedSDB{SDBInterface: db, parent: parent}
+}
+
+func (db *tracedSDB) start(operation string, statement string) *tracer.Span {
+	span := tracer.Start(db.parent, "sqldb "+operation, tracer.SpanKindClient)
+	span.SetAttribute(tracer.AttrDBSystem, MYSQL)
+	span.SetAttribute(tracer.AttrDBOperation, operation)
+	span.SetAttribute(tracer.AttrDBStatement, statement)
+	return span
+}
+
+func (db *tracedSDB) Query(query string, args ...interface{}) (*sql.Rows, *SDBError) {
+	span := db.start("Query", query)
+	rows, err := db.SDBInterface.Query(query, args...)
+	if err != nil {
+		span.SetError(err)
+	}
+	span.Finish()
+	return rows, err
+}
+
+func (db *tracedSDB) Execute(query string, args ...interface{}) (sql.Result, *SDBError) {
+	span := db.start("Execute", query)
+	res, err := db.SDBInterface.Execute(query, args...)
+	if err != nil {
+		span.SetError(err)
+	}
+	span.Finish()
+	return res, err
+}
@@ -35,3 +35,12 @@ func Get(key string) (SDBInterface, *SDBError) {
 		return val.(SDBInterface), nil
 	}
 }
+
+// Keys() - returns the keys of the sql dbs set
+func Keys() []string {
+	keys := make([]string, 0, sdbMap.Size())
+	for _, k := range sdbMap.Keys() {
+		keys = append(keys, k.(string))
+	}
+	return keys
+}
@@ -42,7 +42,10 @@ func execExecuteNode(execNodeID string,
 
 	nextwfData = wfData
 
+	span := startNodeSpan(execNodeID, execNode.Name(), wfData)
 	outputData, err := execNode.Execute(*wfData)
+	span.SetError(err)
+	span.Finish()
 	if err != nil {
 		nextwfData.setWorkflowState(execNode.Name(), err)
 		return "", nextwfData
@@ -66,7 +69,10 @@ func execDecisionNode(decisionNodeID string,
 
 	nextwfData = wfData
 
+	span := startNodeSpan(decisionNodeID, decisionNode.Name(), wfData)
 	yes, err := decisionNode.GetDecision(*wfData)
+	span.SetError(err)
+	span.Finish()
 	if err != nil {
 		nextwfData.setWorkflowState(decisionNode.Name(), err)
 		return "", nextwfData
@@ -148,7 +154,10 @@ func executeJoinNode(joinNodeID string,
 	wfDefinition *WorkFlowDefinition) (nextNodeID string, nextwfData *WorkFlowData) {
 
 	nextwfData = forkWfData
+	span := startNodeSpan(joinNodeID, joinNode.Name(), forkWfData)
 	outputData, err := joinNode.Join(joinWfData)
+	span.SetError(err)
+	span.Finish()
 	if err != nil {
 		nextwfData.setWorkflowState(joinNode.Name(), err)
 		return "", nextwfData
@@ -39,6 +39,20 @@ func (ec *WorkFlowECInMemoryImpl) Set(key string, value interface{}) (err error)
 	return nil
 }
 
+// Clone returns a copy of the execution context. The values are not deep copied
+func (ec *WorkFlowECInMemoryImpl) Clone() *WorkFlowECInMemoryImpl {
+	ecClone := new(WorkFlowECInMemoryImpl)
+	if ec.store == nil {
+		return ecClone
+	}
+
+	ecClone.store = make(map[string]interface{}, len(ec.store))
+	for k, v := range ec.store {
+		ecClone.store[k] = v
+	}
+	return ecClone
+}
+
 func (ec *WorkFlowECInMemoryImpl) SetBuckets(bucketIDMap map[string]string) (err error) {
 	return ec.Set(buckets, bucketIDMap)
 }
@@ -112,3 +112,24 @@ func TestWorkflowECInmemoryImplThreadId(t *testing.T) {
 		t.Error("Error in workflow definition Execution Context Inmemory implementation theread id retrieval")
 	}
 }
+
+/*
+Test workflow execution context inmemory implementation Clone
+*/
+func TestWorkflowECInmemoryImplClone(t *testing.T) {
+	testWorkflowECInstance := new(WorkFlowECInMemoryImpl)
+	testWorkflowECInstance.Set(testKey1, testValue1)
+
+	ecClone := testWorkflowECInstance.Clone()
+	ecClone.Set(testKey2, testValue2)
+
+	if value, err := ecClone.Get(testKey1); err != nil || value != testValue1 {
+		t.Error("Failed to Get cloned key in Workflow Execution Context")
+	}
+	if _, err := testWorkflowECInstance.Get(testKey2); err == nil {
+		t.Error("Set on the clone modified the original Workflow Execution Context")
+	}
+	if new(WorkFlowECInMemoryImpl).Clone() == nil {
+		t.Error("Failed to Clone an empty Workflow Execution Context")
+	}
+}
@@ -0,0 +1,27 @@
+package orchestrator
+
+import (
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/tracer"
+)
+
+// spanContextProvider is implemented by the request context to give the span of the request
+type spanContextProvider interface {
+	GetSpanContext() tracer.SpanContext
+}
+
+// startNodeSpan starts the span of a node as a child of the span of the request being processed.
+// Returns nil if tracing is disabled or the workflow is not run for a request
+func startNodeSpan(nodeID string, name string, wfData *WorkFlowData) *tracer.Span {
+	if !tracer.Enabled() || wfData == nil || wfData.ExecContext == nil {
+		return nil
+	}
+	rc, _ := wfData.ExecContext.Get(constants.RequestContext)
+	provider, ok := rc.(spanContextProvider)
+	if !ok {
+		return nil
+	}
+	span := tracer.Start(provider.GetSpanContext(), name, tracer.SpanKindInternal)
+	span.SetAttribute(tracer.AttrNodeID, nodeID)
+	return span
+}
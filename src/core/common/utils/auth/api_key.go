@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+// defaultAPIKeyHeader is the header carrying the API key if not configured
+const defaultAPIKeyHeader = "X-Api-Key"
+
+// APIKeyConfig is where the API key is carried
+type APIKeyConfig struct {
+	// Header carrying the API key, X-Api-Key if not specified
+	Header string
+	// QueryParam carrying the API key if the header is not set, not looked up when empty
+	QueryParam string
+}
+
+// apiKeyAuthenticator authenticates the callers by API key
+type apiKeyAuthenticator struct {
+	conf  APIKeyConfig
+	store KeyStore
+}
+
+// NewAPIKeyAuthenticator returns an authenticator looking up the API keys in the store
+func NewAPIKeyAuthenticator(conf APIKeyConfig, store KeyStore) Authenticator {
+	if conf.Header == "" {
+		conf.Header = defaultAPIKeyHeader
+	}
+	return &apiKeyAuthenticator{conf: conf, store: store}
+}
+
+func (a *apiKeyAuthenticator) Authenticate(req *utilhttp.Request) (*Principal, *constants.AppError) {
+	if req.OriginalRequest == nil {
+		return nil, nil
+	}
+	apiKey := req.OriginalRequest.Header.Get(a.conf.Header)
+	if apiKey == "" && a.conf.QueryParam != "" {
+		apiKey = req.OriginalRequest.URL.Query().Get(a.conf.QueryParam)
+	}
+	if apiKey == "" {
+		return nil, nil
+	}
+	key, err := a.store.GetKey(apiKey)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to look up API key. Err : %s", err))
+		return nil, &constants.AppError{Code: constants.ResourceErrorCode, Message: "API key can not be verified",
+			DeveloperMessage: err.Error()}
+	}
+	if key == nil {
+		return nil, Unauthorized("Invalid API key")
+	}
+	if key.Disabled {
+		return nil, Forbidden("API key is disabled")
+	}
+	return key.principal(MethodAPIKey), nil
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+)
+
+// Authentication methods
+const (
+	MethodJWT    = "JWT"
+	MethodHMAC   = "HMAC"
+	MethodAPIKey = "APIKey"
+)
+
+// Principal is the caller authenticated by an authenticator
+type Principal struct {
+	UserID      string
+	ClientAppID string
+	Scopes      []string
+	Roles       []string
+	// Method is the authentication method, JWT, HMAC or APIKey
+	Method string
+	// Claims are the claims of the JWT the caller is authenticated with
+	Claims map[string]interface{} `json:",omitempty"`
+}
+
+// Authenticator authenticates the caller of a request
+type Authenticator interface {
+	// Authenticate returns the caller of the request, nil if the request does not carry the credentials
+	// of the authenticator. Returns an Unauthorized error for invalid credentials and a Forbidden error
+	// for valid credentials which are not allowed
+	Authenticate(req *utilhttp.Request) (*Principal, *constants.AppError)
+}
+
+// Authenticate authenticates the request with the first of the authenticators whose credentials it carries.
+// Returns an Unauthorized error if it carries none
+func Authenticate(req *utilhttp.Request, authenticators []Authenticator) (*Principal, *constants.AppError) {
+	for _, a := range authenticators {
+		p, err := a.Authenticate(req)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			return p, nil
+		}
+	}
+	return nil, Unauthorized("Credentials are required")
+}
+
+// Unauthorized returns the error of a request without valid credentials
+func Unauthorized(developerMessage string) *constants.AppError {
+	return &constants.AppError{Code: constants.UnauthorizedErrorCode, Message: "Unauthorized",
+		DeveloperMessage: developerMessage}
+}
+
+// Forbidden returns the error of a request whose caller is not allowed to make it
+func Forbidden(developerMessage string) *constants.AppError {
+	return &constants.AppError{Code: constants.ForbiddenErrorCode, Message: "Forbidden",
+		DeveloperMessage: developerMessage}
+}
+
+// SetPrincipal sets the caller in the IO data, and its user and client app in the request context
+func SetPrincipal(data workflow.WorkFlowData, p *Principal) error {
+	data.IOData.Set(constants.Principal, p)
+	v, _ := data.ExecContext.Get(constants.RequestContext)
+	rc, ok := v.(utilhttp.RequestContext)
+	if !ok {
+		return fmt.Errorf("Request context not found")
+	}
+	if p.UserID != "" {
+		rc.UserID = p.UserID
+	}
+	if p.ClientAppID != "" {
+		rc.ClientAppID = p.ClientAppID
+	}
+	return data.ExecContext.Set(constants.RequestContext, rc)
+}
+
+// GetPrincipal returns the caller of the request, nil if not authenticated
+func GetPrincipal(data workflow.WorkFlowData) *Principal {
+	v, _ := data.IOData.Get(constants.Principal)
+	p, _ := v.(*Principal)
+	return p
+}
@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+func newRequest(method string, target string, body string, headers map[string]string) *utilhttp.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return &utilhttp.Request{OriginalRequest: r, HTTPVerb: utilhttp.Method(method)}
+}
+
+func encodeSegment(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signHS256(secret string, claims map[string]interface{}) string {
+	signed := encodeSegment(map[string]string{"alg": HS256, "typ": "JWT"}) + "." + encodeSegment(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encodeSegment(map[string]string{"alg": RS256, "kid": kid}) + "." + encodeSegment(claims)
+	hash := sha256.Sum256([]byte(signed))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func jwksOf(keys map[string]*rsa.PrivateKey) []byte {
+	set := jsonWebKeySet{}
+	for kid, k := range keys {
+		set.Keys = append(set.Keys, jsonWebKey{Kty: "RSA", Kid: kid, Use: "sig",
+			N: base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())})
+	}
+	b, _ := json.Marshal(set)
+	return b
+}
+
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func assertCode(t *testing.T, name string, err *constants.AppError, code constants.APPErrorCode) {
+	if err == nil || err.Code != code {
+		t.Errorf("%s: expected error %d, got %v", name, code, err)
+	}
+}
+
+func TestJWTAuthenticatorHS256(t *testing.T) {
+	a, err := NewJWTAuthenticator(JWTConfig{Secret: "secret", Issuer: "idp", Audience: "orders"})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator. Err : %s", err)
+	}
+	exp := float64(time.Now().Add(time.Hour).Unix())
+	claims := map[string]interface{}{"sub": "u1", "client_id": "app1", "iss": "idp", "aud": []string{"orders"},
+		"exp": exp, "scope": "orders:read orders:write", "roles": []string{"admin"}}
+	p, appErr := a.Authenticate(newRequest("GET", "/orders", "", bearer(signHS256("secret", claims))))
+	if appErr != nil {
+		t.Fatalf("Valid token rejected. Err : %v", appErr)
+	}
+	if p.UserID != "u1" || p.ClientAppID != "app1" || p.Method != MethodJWT || len(p.Scopes) != 2 ||
+		len(p.Roles) != 1 || p.Roles[0] != "admin" {
+		t.Errorf("Unexpected principal %+v", p)
+	}
+
+	if p, appErr := a.Authenticate(newRequest("GET", "/orders", "", nil)); p != nil || appErr != nil {
+		t.Errorf("Request without token should not be authenticated, got %v %v", p, appErr)
+	}
+	_, appErr = a.Authenticate(newRequest("GET", "/orders", "", bearer(signHS256("other", claims))))
+	assertCode(t, "wrong secret", appErr, constants.UnauthorizedErrorCode)
+
+	for name, c := range map[string]map[string]interface{}{
+		"expired":      {"iss": "idp", "aud": "orders", "exp": float64(time.Now().Add(-time.Minute).Unix())},
+		"not yet":      {"iss": "idp", "aud": "orders", "nbf": float64(time.Now().Add(time.Hour).Unix())},
+		"wrong issuer": {"iss": "other", "aud": "orders", "exp": exp},
+		"wrong aud":    {"iss": "idp", "aud": "other", "exp": exp},
+	} {
+		_, appErr = a.Authenticate(newRequest("GET", "/orders", "", bearer(signHS256("secret", c))))
+		assertCode(t, name, appErr, constants.UnauthorizedErrorCode)
+	}
+
+	none := encodeSegment(map[string]string{"alg": "none"}) + "." + encodeSegment(claims) + "."
+	_, appErr = a.Authenticate(newRequest("GET", "/orders", "", bearer(none)))
+	assertCode(t, "alg none", appErr, constants.UnauthorizedErrorCode)
+}
+
+func TestJWTAuthenticatorRS256(t *testing.T) {
+	k1, _ := rsa.GenerateKey(rand.Reader, 2048)
+	k2, _ := rsa.GenerateKey(rand.Reader, 2048)
+	dir, _ := ioutil.TempDir("", "jwks")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "jwks.json")
+	ioutil.WriteFile(file, jwksOf(map[string]*rsa.PrivateKey{"k1": k1}), 0600)
+
+	a, err := NewJWTAuthenticator(JWTConfig{JWKSFile: file})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator. Err : %s", err)
+	}
+	claims := map[string]interface{}{"sub": "u1"}
+	if _, appErr := a.Authenticate(newRequest("GET", "/", "", bearer(signRS256(k1, "k1", claims)))); appErr != nil {
+		t.Errorf("Valid token rejected. Err : %v", appErr)
+	}
+	if _, appErr := a.Authenticate(newRequest("GET", "/", "", bearer(signRS256(k1, "", claims)))); appErr != nil {
+		t.Errorf("Token without kid should be verified with the only key. Err : %v", appErr)
+	}
+	_, appErr := a.Authenticate(newRequest("GET", "/", "", bearer(signRS256(k2, "k1", claims))))
+	assertCode(t, "wrong key", appErr, constants.UnauthorizedErrorCode)
+	_, appErr = a.Authenticate(newRequest("GET", "/", "", bearer(signHS256("secret", claims))))
+	assertCode(t, "HS256 without secret", appErr, constants.UnauthorizedErrorCode)
+
+	// a key rotated in the set of the url is fetched on the first token signed with it
+	keys := map[string]*rsa.PrivateKey{"k1": k1}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwksOf(keys))
+	}))
+	defer srv.Close()
+	a, err = NewJWTAuthenticator(JWTConfig{JWKSURL: srv.URL})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator. Err : %s", err)
+	}
+	keys = map[string]*rsa.PrivateKey{"k1": k1, "k2": k2}
+	a.(*jwtAuthenticator).jwks.loadedAt = time.Now().Add(-2 * minJWKSRefreshInterval)
+	if _, appErr := a.Authenticate(newRequest("GET", "/", "", bearer(signRS256(k2, "k2", claims)))); appErr != nil {
+		t.Errorf("Token signed with a rotated key rejected. Err : %v", appErr)
+	}
+	_, appErr = a.Authenticate(newRequest("GET", "/", "", bearer(signRS256(k2, "k3", claims))))
+	assertCode(t, "unknown kid", appErr, constants.UnauthorizedErrorCode)
+
+	if _, err := NewJWTAuthenticator(JWTConfig{}); err == nil {
+		t.Error("Authenticator without secret or JWKS should not be created")
+	}
+}
+
+func TestHMACAuthenticator(t *testing.T) {
+	store := NewMemoryKeyStore([]Key{{ID: "k1", Secret: "secret", ClientAppID: "billing"},
+		{ID: "k2", Secret: "secret2", Disabled: true}})
+	a := NewHMACAuthenticator(HMACConfig{}, store)
+	body := `{"amount":10}`
+	signed := func(keyID string, secret string, ts time.Time, body string) *utilhttp.Request {
+		timestamp := strconv.FormatInt(ts.Unix(), 10)
+		return newRequest("POST", "/orders?x=1", body, map[string]string{"X-Key-Id": keyID,
+			"X-Timestamp": timestamp, "X-Signature": Sign(secret, "POST", "/orders?x=1", timestamp, []byte(body))})
+	}
+
+	req := signed("k1", "secret", time.Now(), body)
+	p, appErr := a.Authenticate(req)
+	if appErr != nil {
+		t.Fatalf("Valid signature rejected. Err : %v", appErr)
+	}
+	if p.ClientAppID != "billing" || p.Method != MethodHMAC {
+		t.Errorf("Unexpected principal %+v", p)
+	}
+	if b, _ := ioutil.ReadAll(req.OriginalRequest.Body); string(b) != body {
+		t.Errorf("Request body not restored, got %s", b)
+	}
+
+	_, appErr = a.Authenticate(signed("k1", "wrong", time.Now(), body))
+	assertCode(t, "wrong secret", appErr, constants.UnauthorizedErrorCode)
+	tampered := signed("k1", "secret", time.Now(), body)
+	tampered.OriginalRequest.Body = ioutil.NopCloser(strings.NewReader(`{"amount":1000}`))
+	_, appErr = a.Authenticate(tampered)
+	assertCode(t, "tampered body", appErr, constants.UnauthorizedErrorCode)
+	_, appErr = a.Authenticate(signed("k1", "secret", time.Now().Add(-10*time.Minute), body))
+	assertCode(t, "stale timestamp", appErr, constants.UnauthorizedErrorCode)
+	_, appErr = a.Authenticate(signed("k3", "secret", time.Now(), body))
+	assertCode(t, "unknown key", appErr, constants.UnauthorizedErrorCode)
+	_, appErr = a.Authenticate(signed("k2", "secret2", time.Now(), body))
+	assertCode(t, "disabled key", appErr, constants.ForbiddenErrorCode)
+	if p, appErr := a.Authenticate(newRequest("POST", "/orders", body, nil)); p != nil || appErr != nil {
+		t.Errorf("Unsigned request should not be authenticated, got %v %v", p, appErr)
+	}
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	store := NewMemoryKeyStore([]Key{{ID: "key1", UserID: "u1", Scopes: []string{"orders:read"}},
+		{ID: "key2", Disabled: true}})
+	a := NewAPIKeyAuthenticator(APIKeyConfig{QueryParam: "api_key"}, store)
+	p, appErr := a.Authenticate(newRequest("GET", "/", "", map[string]string{"X-Api-Key": "key1"}))
+	if appErr != nil || p.UserID != "u1" || p.Method != MethodAPIKey || len(p.Scopes) != 1 {
+		t.Errorf("Unexpected principal %+v, err %v", p, appErr)
+	}
+	if p, appErr = a.Authenticate(newRequest("GET", "/?api_key=key1", "", nil)); appErr != nil || p == nil {
+		t.Errorf("API key of the query param rejected. Err : %v", appErr)
+	}
+	_, appErr = a.Authenticate(newRequest("GET", "/", "", map[string]string{"X-Api-Key": "key3"}))
+	assertCode(t, "unknown key", appErr, constants.UnauthorizedErrorCode)
+	_, appErr = a.Authenticate(newRequest("GET", "/", "", map[string]string{"X-Api-Key": "key2"}))
+	assertCode(t, "disabled key", appErr, constants.ForbiddenErrorCode)
+}
+
+func TestAuthenticate(t *testing.T) {
+	keys := NewAPIKeyAuthenticator(APIKeyConfig{}, NewMemoryKeyStore([]Key{{ID: "key1", UserID: "u1"}}))
+	jwt, _ := NewJWTAuthenticator(JWTConfig{Secret: "secret"})
+	authenticators := []Authenticator{jwt, keys}
+
+	p, appErr := Authenticate(newRequest("GET", "/", "", map[string]string{"X-Api-Key": "key1"}), authenticators)
+	if appErr != nil || p.Method != MethodAPIKey {
+		t.Errorf("Request should be authenticated by API key, got %+v %v", p, appErr)
+	}
+	_, appErr = Authenticate(newRequest("GET", "/", "", nil), authenticators)
+	assertCode(t, "no credentials", appErr, constants.UnauthorizedErrorCode)
+	_, appErr = Authenticate(newRequest("GET", "/", "", map[string]string{"Authorization": "Bearer x.y.z",
+		"X-Api-Key": "key1"}), authenticators)
+	assertCode(t, "invalid token", appErr, constants.UnauthorizedErrorCode)
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+)
+
+// Decisions of the authorization
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// Rule is what the caller of a request should have to be allowed to make it
+type Rule struct {
+	// Scopes are the scopes the caller should have all of
+	Scopes []string
+	// Roles are the roles the caller should have any of
+	Roles []string
+	// Policy is an expression the caller should satisfy, over the variables claims, user_id, client_app_id,
+	// scopes, roles, method (the authentication method) and params (the path params), e.g.
+	// "admin" in roles || claims.tenant == params.tenant. See compilePolicy for the syntax
+	Policy string
+
+	once   sync.Once
+	policy policyNode
+	err    error
+}
+
+// auditRecord is the audit log record of an authorization decision
+type auditRecord struct {
+	Decision      string `json:"decision"`
+	Reason        string `json:"reason,omitempty"`
+	Target        string `json:"target"`
+	UserID        string `json:"user_id,omitempty"`
+	ClientAppID   string `json:"client_app_id,omitempty"`
+	AuthMethod    string `json:"auth_method,omitempty"`
+	Method        string `json:"method,omitempty"`
+	URI           string `json:"uri,omitempty"`
+	RequestID     string `json:"request_id,omitempty"`
+	TransactionID string `json:"transaction_id,omitempty"`
+}
+
+// auditLoggerKey is the key of the logger the decisions are written to, not written when empty
+var auditLoggerKey string
+
+// SetAuditLogger sets the key of the logger the authorization decisions are written to. The records are
+// JSON, hence the logger should have the raw FormatType
+func SetAuditLogger(key string) {
+	auditLoggerKey = key
+}
+
+// Compile compiles the policy of the rule. Returns an error if the policy is invalid
+func (r *Rule) Compile() error {
+	r.once.Do(func() {
+		if strings.TrimSpace(r.Policy) != "" {
+			r.policy, r.err = compilePolicy(r.Policy)
+		}
+	})
+	return r.err
+}
+
+// evaluate returns the reason the caller is denied by the rule, empty if allowed
+func (r *Rule) evaluate(p *Principal, params map[string]string) string {
+	for _, s := range r.Scopes {
+		if !hasAny(p.Scopes, s) {
+			return fmt.Sprintf("Scope %s is required", s)
+		}
+	}
+	if len(r.Roles) > 0 && !hasAny(p.Roles, r.Roles...) {
+		return fmt.Sprintf("One of the roles %s is required", strings.Join(r.Roles, ", "))
+	}
+	if err := r.Compile(); err != nil {
+		return fmt.Sprintf("Invalid policy. Err : %s", err)
+	}
+	if r.policy != nil && !isTruthy(r.policy.eval(&policyEnv{principal: p, params: params})) {
+		return "Policy is not satisfied"
+	}
+	return ""
+}
+
+// Authorize authorizes the caller set in the IO data by the authenticators as per the rule, writing the
+// decision to the audit log. target names what is authorized in the audit log, e.g. the api. Returns an
+// Unauthorized error if the request is not authenticated and a Forbidden error if the caller is denied
+func Authorize(data workflow.WorkFlowData, rule *Rule, target string) *constants.AppError {
+	if rule == nil {
+		return nil
+	}
+	req, _ := misc.GetRequestFromIO(data)
+	var params map[string]string
+	if req != nil && req.PathParameters != nil {
+		params = *req.PathParameters
+	}
+	p := GetPrincipal(data)
+	reason := "Request is not authenticated"
+	if p != nil {
+		reason = rule.evaluate(p, params)
+	}
+	audit(data, req, p, target, reason)
+	switch {
+	case p == nil:
+		return Unauthorized(reason)
+	case reason != "":
+		return Forbidden(reason)
+	}
+	return nil
+}
+
+// audit writes the decision on the request to the audit log
+func audit(data workflow.WorkFlowData, req *utilhttp.Request, p *Principal, target string, reason string) {
+	key := auditLoggerKey
+	if key == "" {
+		return
+	}
+	rec := auditRecord{Decision: Allow, Reason: reason, Target: target}
+	if reason != "" {
+		rec.Decision = Deny
+	}
+	if p != nil {
+		rec.UserID, rec.ClientAppID, rec.AuthMethod = p.UserID, p.ClientAppID, p.Method
+	}
+	if req != nil && req.OriginalRequest != nil {
+		rec.Method, rec.URI = req.OriginalRequest.Method, req.OriginalRequest.RequestURI
+	}
+	if v, _ := data.ExecContext.Get(constants.RequestContext); v != nil {
+		if rc, ok := v.(utilhttp.RequestContext); ok {
+			rec.RequestID, rec.TransactionID = rc.RequestID, rc.TransactionID
+		}
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to encode audit log record. Err : %s", err))
+		return
+	}
+	logger.AuditSpecific(key, string(b))
+}
+
+// hasAny checks if the values have any of the wanted ones
+func hasAny(values []string, wanted ...string) bool {
+	for _, w := range wanted {
+		for _, v := range values {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+)
+
+func newWorkFlowData(p *Principal, params map[string]string) workflow.WorkFlowData {
+	data := workflow.WorkFlowData{}
+	data.Create(new(workflow.WorkFlowIOInMemoryImpl), new(workflow.WorkFlowECInMemoryImpl))
+	req := newRequest("GET", "/orders", "", nil)
+	req.PathParameters = &params
+	data.IOData.Set(constants.Request, req)
+	data.ExecContext.Set(constants.RequestContext, utilhttp.RequestContext{RequestID: "r1"})
+	if p != nil {
+		SetPrincipal(data, p)
+	}
+	return data
+}
+
+func TestPolicy(t *testing.T) {
+	p := &Principal{UserID: "u1", Roles: []string{"admin"}, Scopes: []string{"orders:read"}, Method: MethodJWT,
+		Claims: map[string]interface{}{"tenant": "jabong", "level": float64(3), "org": map[string]interface{}{"id": "o1"}}}
+	params := map[string]string{"tenant": "jabong", "userId": "u2"}
+	tests := map[string]bool{
+		`"admin" in roles`:                                   true,
+		`"editor" in roles`:                                  false,
+		`claims.tenant == params.tenant`:                     true,
+		`claims.org.id == 'o1' && method == "JWT"`:           true,
+		`claims.level == 3`:                                  true,
+		`claims.level != 3 || !("orders:read" in scopes)`:    false,
+		`user_id == params.userId || "admin" in roles`:       true,
+		`(user_id == params.userId || "editor" in roles)`:    false,
+		`claims.missing`:                                     false,
+		`claims.missing == claims.unknown && claims.tenant`:  false,
+		`claims.missing != claims.unknown`:                   false,
+		`claims.missing != "jabong"`:                         false,
+		`claims.tenant != params.missing`:                    false,
+		`"tenant" in claims && !("level" in params) && true`: true,
+		`!"admin" in roles`:                                  false,
+		`client_app_id == "" && claims.org.id.x == false`:    false,
+	}
+	for expr, expected := range tests {
+		policy, err := compilePolicy(expr)
+		if err != nil {
+			t.Errorf("Failed to compile %s. Err : %s", expr, err)
+			continue
+		}
+		if got := isTruthy(policy.eval(&policyEnv{principal: p, params: params})); got != expected {
+			t.Errorf("%s evaluated to %v, expected %v", expr, got, expected)
+		}
+	}
+
+	for _, expr := range []string{`roles ==`, `"admin" in`, `(user_id == "u1"`, `tenant == "x"`, `user_id = "u1"`,
+		`"unterminated`, `user_id == "u1")`} {
+		if _, err := compilePolicy(expr); err == nil {
+			t.Errorf("Invalid policy %s compiled", expr)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	rule := &Rule{Scopes: []string{"orders:read", "orders:write"}, Roles: []string{"admin", "support"},
+		Policy: `claims.tenant == params.tenant`}
+	if err := rule.Compile(); err != nil {
+		t.Fatalf("Failed to compile rule. Err : %s", err)
+	}
+	allowed := &Principal{Scopes: []string{"orders:read", "orders:write"}, Roles: []string{"support"},
+		Claims: map[string]interface{}{"tenant": "t1"}}
+	if appErr := Authorize(newWorkFlowData(allowed, map[string]string{"tenant": "t1"}), rule, "ORDERS"); appErr != nil {
+		t.Errorf("Caller denied. Err : %v", appErr)
+	}
+
+	tests := map[string]*Principal{
+		"missing scope": {Scopes: []string{"orders:read"}, Roles: []string{"admin"},
+			Claims: map[string]interface{}{"tenant": "t1"}},
+		"missing role": {Scopes: []string{"orders:read", "orders:write"}, Roles: []string{"viewer"},
+			Claims: map[string]interface{}{"tenant": "t1"}},
+		"policy": {Scopes: []string{"orders:read", "orders:write"}, Roles: []string{"admin"},
+			Claims: map[string]interface{}{"tenant": "t2"}},
+	}
+	for name, p := range tests {
+		appErr := Authorize(newWorkFlowData(p, map[string]string{"tenant": "t1"}), rule, "ORDERS")
+		assertCode(t, name, appErr, constants.ForbiddenErrorCode)
+	}
+	appErr := Authorize(newWorkFlowData(nil, nil), rule, "ORDERS")
+	assertCode(t, "not authenticated", appErr, constants.UnauthorizedErrorCode)
+
+	if err := (&Rule{Policy: `"admin" in`}).Compile(); err == nil {
+		t.Error("Rule with an invalid policy compiled")
+	}
+	if _, err := NewAuthorizationNode(&Rule{Policy: `user_id ==`}); err == nil {
+		t.Error("Node with an invalid policy created")
+	}
+}
+
+func TestAuthorizeMissingClaims(t *testing.T) {
+	rule := &Rule{Policy: `claims.tenant == claims.owner_tenant`}
+	if err := rule.Compile(); err != nil {
+		t.Fatalf("Failed to compile rule. Err : %s", err)
+	}
+	owner := &Principal{UserID: "u1", Claims: map[string]interface{}{"tenant": "t1", "owner_tenant": "t1"}}
+	if appErr := Authorize(newWorkFlowData(owner, nil), rule, "ORDERS"); appErr != nil {
+		t.Errorf("Caller denied. Err : %v", appErr)
+	}
+	tests := map[string]*Principal{
+		"no claims":      {UserID: "u2"},
+		"missing claim":  {UserID: "u2", Claims: map[string]interface{}{"tenant": "t1"}},
+		"null claims":    {UserID: "u2", Claims: map[string]interface{}{"tenant": nil, "owner_tenant": nil}},
+		"other tenant":   {UserID: "u2", Claims: map[string]interface{}{"tenant": "t1", "owner_tenant": "t2"}},
+		"claim not text": {UserID: "u2", Claims: map[string]interface{}{"tenant": []interface{}{}, "owner_tenant": []interface{}{}}},
+	}
+	for name, p := range tests {
+		assertCode(t, name, Authorize(newWorkFlowData(p, nil), rule, "ORDERS"), constants.ForbiddenErrorCode)
+	}
+}
@@ -0,0 +1,27 @@
+// Package auth authenticates and authorizes the callers of the apis with JWTs (HS256 or RS256 with the keys of a JWKS),
+// HMAC signed requests or API keys. The authenticators are set per api in versionmanager.Version, or run
+// by a Node in an orchestrator. The authenticated caller, the Principal, is set in the IO data and its user
+// and client app in the request context.
+//
+//	jwtAuth, err := auth.NewJWTAuthenticator(auth.JWTConfig{JWKSURL: "https://idp.example.com/jwks.json",
+//		Issuer: "https://idp.example.com/", Audience: "orders"})
+//	keys := auth.NewMemoryKeyStore([]auth.Key{{ID: "k1", Secret: "secret", ClientAppID: "billing"}})
+//
+//	versionmanager.Version{Resource: "ORDERS", Version: "V1", Action: "POST",
+//		Authenticators: []auth.Authenticator{jwtAuth, auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{}, keys)}}
+//
+// The requests are signed for the HMAC authenticator as
+//
+//	X-Key-Id: k1
+//	X-Timestamp: 1500000000
+//	X-Signature: hex(HMAC-SHA256(secret, METHOD + "\n" + REQUEST_URI + "\n" + TIMESTAMP + "\n" + hex(SHA256(body))))
+//
+// The authenticated callers are authorized by the Rule of the api, set in versionmanager.Version or run by an
+// AuthorizationNode. The caller should have all the scopes, any of the roles and satisfy the policy of the rule.
+// Each decision is written to the audit log set with SetAuditLogger
+//
+//	versionmanager.Version{Resource: "ORDERS", Version: "V1", Action: "PUT", Path: "{tenant}/{id}",
+//		Authenticators: []auth.Authenticator{jwtAuth},
+//		Authorization: &auth.Rule{Scopes: []string{"orders:write"},
+//			Policy: `"admin" in roles || claims.tenant == params.tenant`}}
+package auth
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+// Defaults of the HMAC authenticator
+const (
+	defaultKeyIDHeader     = "X-Key-Id"
+	defaultTimestampHeader = "X-Timestamp"
+	defaultSignatureHeader = "X-Signature"
+	defaultClockSkew       = 5 * time.Minute
+)
+
+// HMACConfig is where the signature of a request is carried and how old it can be
+type HMACConfig struct {
+	// KeyIDHeader, TimestampHeader and SignatureHeader carry the id of the key, the unix time in seconds the
+	// request is signed at and the signature. Default to X-Key-Id, X-Timestamp and X-Signature
+	KeyIDHeader     string
+	TimestampHeader string
+	SignatureHeader string
+	// ClockSkew is how far the timestamp can be from the time of the server, 5 minutes if not specified
+	ClockSkew time.Duration
+}
+
+// hmacAuthenticator authenticates the callers by the HMAC signature of the requests
+type hmacAuthenticator struct {
+	conf  HMACConfig
+	store KeyStore
+	now   func() time.Time
+}
+
+// NewHMACAuthenticator returns an authenticator verifying the signatures with the secrets of the store
+func NewHMACAuthenticator(conf HMACConfig, store KeyStore) Authenticator {
+	if conf.KeyIDHeader == "" {
+		conf.KeyIDHeader = defaultKeyIDHeader
+	}
+	if conf.TimestampHeader == "" {
+		conf.TimestampHeader = defaultTimestampHeader
+	}
+	if conf.SignatureHeader == "" {
+		conf.SignatureHeader = defaultSignatureHeader
+	}
+	if conf.ClockSkew <= 0 {
+		conf.ClockSkew = defaultClockSkew
+	}
+	return &hmacAuthenticator{conf: conf, store: store, now: time.Now}
+}
+
+// Sign returns the signature of a request as per the package doc
+func Sign(secret string, method string, requestURI string, timestamp string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s", method, requestURI, timestamp, hex.EncodeToString(bodyHash[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (a *hmacAuthenticator) Authenticate(req *utilhttp.Request) (*Principal, *constants.AppError) {
+	if req.OriginalRequest == nil {
+		return nil, nil
+	}
+	r := req.OriginalRequest
+	signature := r.Header.Get(a.conf.SignatureHeader)
+	if signature == "" {
+		return nil, nil
+	}
+	keyID := r.Header.Get(a.conf.KeyIDHeader)
+	timestamp := r.Header.Get(a.conf.TimestampHeader)
+	if keyID == "" || timestamp == "" {
+		return nil, Unauthorized(fmt.Sprintf("%s and %s are required", a.conf.KeyIDHeader, a.conf.TimestampHeader))
+	}
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, Unauthorized(fmt.Sprintf("Invalid %s", a.conf.TimestampHeader))
+	}
+	if skew := a.now().Sub(time.Unix(secs, 0)); skew > a.conf.ClockSkew || skew < -a.conf.ClockSkew {
+		return nil, Unauthorized("Request signed too far from the server time")
+	}
+
+	key, err := a.store.GetKey(keyID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to look up signing key %s. Err : %s", keyID, err))
+		return nil, &constants.AppError{Code: constants.ResourceErrorCode, Message: "Signature can not be verified",
+			DeveloperMessage: err.Error()}
+	}
+	if key == nil {
+		return nil, Unauthorized("Invalid signing key")
+	}
+	body, err := readBody(req)
+	if err != nil {
+		return nil, Unauthorized(fmt.Sprintf("Failed to read request body. Err : %s", err))
+	}
+	expected := Sign(key.Secret, r.Method, r.URL.RequestURI(), timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, Unauthorized("Invalid signature")
+	}
+	if key.Disabled {
+		return nil, Forbidden("Signing key is disabled")
+	}
+	return key.principal(MethodHMAC), nil
+}
+
+// readBody reads the body of the request, which gets back the body read
+func readBody(req *utilhttp.Request) ([]byte, error) {
+	if req.OriginalRequest.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.OriginalRequest.Body)
+	req.OriginalRequest.Body.Close()
+	req.OriginalRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults of the key sets fetched from a url
+const (
+	defaultJWKSRefreshInterval = time.Hour
+	// minJWKSRefreshInterval is the minimum time between the fetches triggered by unknown keys
+	minJWKSRefreshInterval = time.Minute
+	jwksFetchTimeout       = 10 * time.Second
+)
+
+// jwks is a JSON Web Key Set loaded from a file or fetched from a url
+type jwks struct {
+	file            string
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+	mutex           sync.RWMutex
+	keys            map[string]*rsa.PublicKey
+	loadedAt        time.Time
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func newJWKS(file string, url string, refreshInterval time.Duration) *jwks {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+	return &jwks{file: file, url: url, refreshInterval: refreshInterval,
+		client: &http.Client{Timeout: jwksFetchTimeout}}
+}
+
+// get returns the key of the kid, fetching the set again if the key is not found or the set is stale.
+// A token without kid is verified with the only key of the set
+func (s *jwks) get(kid string) (*rsa.PublicKey, error) {
+	s.mutex.RLock()
+	key, found := s.find(kid)
+	age := time.Since(s.loadedAt)
+	s.mutex.RUnlock()
+	if s.url != "" && (age > s.refreshInterval || (!found && age > minJWKSRefreshInterval)) {
+		if err := s.load(); err != nil {
+			if !found {
+				return nil, err
+			}
+		} else {
+			s.mutex.RLock()
+			key, found = s.find(kid)
+			s.mutex.RUnlock()
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("Token key %s not found", kid)
+	}
+	return key, nil
+}
+
+func (s *jwks) find(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// load loads the set from the file or the url
+func (s *jwks) load() error {
+	var b []byte
+	var err error
+	if s.file != "" {
+		b, err = ioutil.ReadFile(s.file)
+	} else {
+		b, err = s.fetch()
+	}
+	if err != nil {
+		s.mutex.Lock()
+		// the fetches are not retried before the min refresh interval
+		if s.url != "" {
+			s.loadedAt = time.Now().Add(minJWKSRefreshInterval - s.refreshInterval)
+		}
+		s.mutex.Unlock()
+		return fmt.Errorf("Failed to load JWKS. Err : %s", err)
+	}
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	s.keys = keys
+	s.loadedAt = time.Now()
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *jwks) fetch() ([]byte, error) {
+	res, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS url responded with %d", res.StatusCode)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// parseJWKS returns the RSA signing keys of the set by kid
+func parseJWKS(b []byte) (map[string]*rsa.PublicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("Invalid JWKS. Err : %s", err)
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid modulus of key %s", k.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("Invalid exponent of key %s", k.Kid)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("No RSA signing key found in JWKS")
+	}
+	return keys, nil
+}
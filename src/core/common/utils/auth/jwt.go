@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+// Signing algorithms of the JWTs
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// bearerPrefix prefixes the token in the Authorization header
+const bearerPrefix = "Bearer "
+
+// JWTConfig is how the JWTs are verified
+type JWTConfig struct {
+	// Secret verifies the HS256 tokens, which are rejected when empty
+	Secret string
+	// JWKSFile or JWKSURL is the JSON Web Key Set whose RSA keys verify the RS256 tokens, which are rejected
+	// when neither is specified. The set of the url is fetched again after JWKSRefreshInterval (1 hour if
+	// not specified), or when a token is signed with a key not in the set
+	JWKSFile            string
+	JWKSURL             string
+	JWKSRefreshInterval time.Duration
+	// Issuer and Audience are checked against the iss and aud claims if specified
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew allowed for the exp, nbf and iat claims
+	Leeway time.Duration
+	// UserIDClaim, ClientAppIDClaim, ScopeClaim and RolesClaim are the claims the caller is read from,
+	// sub, azp (else client_id), scope and roles if not specified. The scopes are either a space separated
+	// string or an array
+	UserIDClaim      string
+	ClientAppIDClaim string
+	ScopeClaim       string
+	RolesClaim       string
+}
+
+// jwtAuthenticator authenticates the callers by the JWT in the Authorization header, or in the token header
+type jwtAuthenticator struct {
+	conf JWTConfig
+	jwks *jwks
+	now  func() time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// NewJWTAuthenticator returns an authenticator verifying the JWTs as per the config. Returns an error if
+// the key set can not be loaded
+func NewJWTAuthenticator(conf JWTConfig) (Authenticator, error) {
+	if conf.Secret == "" && conf.JWKSFile == "" && conf.JWKSURL == "" {
+		return nil, errors.New("Secret or JWKS is required to verify the tokens")
+	}
+	if conf.UserIDClaim == "" {
+		conf.UserIDClaim = "sub"
+	}
+	if conf.ClientAppIDClaim == "" {
+		conf.ClientAppIDClaim = "azp"
+	}
+	if conf.ScopeClaim == "" {
+		conf.ScopeClaim = "scope"
+	}
+	if conf.RolesClaim == "" {
+		conf.RolesClaim = "roles"
+	}
+	a := &jwtAuthenticator{conf: conf, now: time.Now}
+	if conf.JWKSFile != "" || conf.JWKSURL != "" {
+		a.jwks = newJWKS(conf.JWKSFile, conf.JWKSURL, conf.JWKSRefreshInterval)
+		if err := a.jwks.load(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (a *jwtAuthenticator) Authenticate(req *utilhttp.Request) (*Principal, *constants.AppError) {
+	token := getBearerToken(req)
+	if token == "" {
+		return nil, nil
+	}
+	claims, err := a.verify(token)
+	if err != nil {
+		return nil, Unauthorized(err.Error())
+	}
+	return a.principal(claims), nil
+}
+
+// getBearerToken returns the bearer token of the Authorization header, else the token of the token header
+func getBearerToken(req *utilhttp.Request) string {
+	if req.OriginalRequest != nil {
+		h := req.OriginalRequest.Header.Get("Authorization")
+		if len(h) > len(bearerPrefix) && strings.EqualFold(h[:len(bearerPrefix)], bearerPrefix) {
+			return strings.TrimSpace(h[len(bearerPrefix):])
+		}
+	}
+	return req.Headers.AuthToken
+}
+
+// verify verifies the signature and the claims of the token and returns its claims
+func (a *jwtAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("Malformed token")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("Malformed token header. Err : %s", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("Malformed token signature")
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case HS256:
+		if a.conf.Secret == "" {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, []byte(a.conf.Secret))
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), sig) {
+			return nil, errors.New("Invalid token signature")
+		}
+	case RS256:
+		if a.jwks == nil {
+			return nil, errors.New("RS256 tokens are not accepted")
+		}
+		key, err := a.jwks.get(header.Kid)
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig); err != nil {
+			return nil, errors.New("Invalid token signature")
+		}
+	default:
+		return nil, fmt.Errorf("Token algorithm %s is not accepted", header.Alg)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("Malformed token claims. Err : %s", err)
+	}
+	if err := a.verifyClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifyClaims verifies the times, the issuer and the audience of the token
+func (a *jwtAuthenticator) verifyClaims(claims map[string]interface{}) error {
+	now := a.now()
+	if exp, ok := claims["exp"].(float64); ok && !now.Before(time.Unix(int64(exp), 0).Add(a.conf.Leeway)) {
+		return errors.New("Token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(a.conf.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("Token is not valid yet")
+	}
+	if iat, ok := claims["iat"].(float64); ok && now.Add(a.conf.Leeway).Before(time.Unix(int64(iat), 0)) {
+		return errors.New("Token is issued in the future")
+	}
+	if a.conf.Issuer != "" && claims["iss"] != a.conf.Issuer {
+		return errors.New("Invalid token issuer")
+	}
+	if a.conf.Audience != "" && !hasString(claims["aud"], a.conf.Audience) {
+		return errors.New("Invalid token audience")
+	}
+	return nil
+}
+
+// principal returns the caller of the claims
+func (a *jwtAuthenticator) principal(claims map[string]interface{}) *Principal {
+	p := &Principal{Method: MethodJWT, Claims: claims}
+	p.UserID, _ = claims[a.conf.UserIDClaim].(string)
+	p.ClientAppID, _ = claims[a.conf.ClientAppIDClaim].(string)
+	if p.ClientAppID == "" && a.conf.ClientAppIDClaim == "azp" {
+		p.ClientAppID, _ = claims["client_id"].(string)
+	}
+	p.Scopes = getStrings(claims[a.conf.ScopeClaim])
+	p.Roles = getStrings(claims[a.conf.RolesClaim])
+	return p
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// getStrings returns the strings of a claim, either a space separated string or an array
+func getStrings(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		s := make([]string, 0, len(v))
+		for _, e := range v {
+			if str, ok := e.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	}
+	return nil
+}
+
+// hasString checks if a claim, either a string or an array, has the string
+func hasString(claim interface{}, s string) bool {
+	switch v := claim.(type) {
+	case string:
+		return v == s
+	case []interface{}:
+		for _, e := range v {
+			if e == s {
+				return true
+			}
+		}
+	}
+	return false
+}
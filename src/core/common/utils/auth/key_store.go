@@ -0,0 +1,49 @@
+package auth
+
+// Key is a secret of a client, an API key or the secret the requests of the client are signed with
+type Key struct {
+	// ID is the API key or the id of the signing secret
+	ID string
+	// Secret is the secret the requests are signed with
+	Secret      string
+	UserID      string
+	ClientAppID string
+	Scopes      []string
+	Roles       []string
+	// Disabled keys are valid but not allowed
+	Disabled bool
+}
+
+// KeyStore looks up the keys by id
+type KeyStore interface {
+	// GetKey returns the key of the id, nil if not found
+	GetKey(id string) (*Key, error)
+}
+
+// memoryKeyStore keeps the keys in memory
+type memoryKeyStore struct {
+	keys map[string]Key
+}
+
+// NewMemoryKeyStore returns a store of the keys
+func NewMemoryKeyStore(keys []Key) KeyStore {
+	s := &memoryKeyStore{keys: make(map[string]Key, len(keys))}
+	for _, k := range keys {
+		s.keys[k.ID] = k
+	}
+	return s
+}
+
+func (s *memoryKeyStore) GetKey(id string) (*Key, error) {
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, nil
+	}
+	return &k, nil
+}
+
+// principal returns the caller the key belongs to
+func (k *Key) principal(method string) *Principal {
+	return &Principal{UserID: k.UserID, ClientAppID: k.ClientAppID, Scopes: k.Scopes, Roles: k.Roles,
+		Method: method}
+}
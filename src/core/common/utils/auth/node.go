@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"github.com/jabong/florest-core/src/common/constants"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+)
+
+// Node is an execution node authenticating the request with the first of its authenticators whose
+// credentials the request carries
+type Node struct {
+	id             string
+	authenticators []Authenticator
+}
+
+// NewNode returns a node authenticating with the authenticators
+func NewNode(authenticators ...Authenticator) *Node {
+	return &Node{authenticators: authenticators}
+}
+
+func (n *Node) SetID(id string) {
+	n.id = id
+}
+
+func (n *Node) GetID() (id string, err error) {
+	return n.id, nil
+}
+
+func (n *Node) Name() string {
+	return "Authenticator"
+}
+
+func (n *Node) Execute(io workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+	req, err := misc.GetRequestFromIO(io)
+	if err != nil {
+		return io, &constants.AppError{Code: constants.ResourceErrorCode, Message: "Request not found",
+			DeveloperMessage: err.Error()}
+	}
+	p, appErr := Authenticate(req, n.authenticators)
+	if appErr != nil {
+		return io, appErr
+	}
+	if err := SetPrincipal(io, p); err != nil {
+		return io, &constants.AppError{Code: constants.ResourceErrorCode, Message: "Caller can not be set",
+			DeveloperMessage: err.Error()}
+	}
+	return io, nil
+}
+
+// AuthorizationNode is an execution node authorizing the caller set by a Node as per its rule
+type AuthorizationNode struct {
+	id   string
+	rule *Rule
+}
+
+// NewAuthorizationNode returns a node authorizing as per the rule. Returns an error if the policy of the
+// rule is invalid
+func NewAuthorizationNode(rule *Rule) (*AuthorizationNode, error) {
+	if err := rule.Compile(); err != nil {
+		return nil, err
+	}
+	return &AuthorizationNode{rule: rule}, nil
+}
+
+func (n *AuthorizationNode) SetID(id string) {
+	n.id = id
+}
+
+func (n *AuthorizationNode) GetID() (id string, err error) {
+	return n.id, nil
+}
+
+func (n *AuthorizationNode) Name() string {
+	return "Authorizer"
+}
+
+func (n *AuthorizationNode) Execute(io workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+	if appErr := Authorize(io, n.rule, n.id); appErr != nil {
+		return io, appErr
+	}
+	return io, nil
+}
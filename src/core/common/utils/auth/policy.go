@@ -0,0 +1,371 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// policyEnv is what a policy is evaluated against
+type policyEnv struct {
+	principal *Principal
+	params    map[string]string
+}
+
+// policyNode is a node of a compiled policy
+type policyNode interface {
+	eval(env *policyEnv) interface{}
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+// variableNode is a variable followed by the fields looked up in it, e.g. claims.org.id
+type variableNode struct {
+	name   string
+	fields []string
+}
+
+type notNode struct {
+	operand policyNode
+}
+
+type binaryNode struct {
+	op          string
+	left, right policyNode
+}
+
+// policyVariables are the variables of the policies
+var policyVariables = map[string]bool{
+	"claims":        true,
+	"user_id":       true,
+	"client_app_id": true,
+	"scopes":        true,
+	"roles":         true,
+	"method":        true,
+	"params":        true,
+}
+
+func (n literalNode) eval(env *policyEnv) interface{} {
+	return n.value
+}
+
+func (n variableNode) eval(env *policyEnv) interface{} {
+	var v interface{}
+	switch n.name {
+	case "claims":
+		v = env.principal.Claims
+	case "user_id":
+		v = env.principal.UserID
+	case "client_app_id":
+		v = env.principal.ClientAppID
+	case "scopes":
+		v = toList(env.principal.Scopes)
+	case "roles":
+		v = toList(env.principal.Roles)
+	case "method":
+		v = env.principal.Method
+	case "params":
+		params := make(map[string]interface{}, len(env.params))
+		for k, p := range env.params {
+			params[k] = p
+		}
+		v = params
+	}
+	for _, f := range n.fields {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[f]
+	}
+	return v
+}
+
+func (n notNode) eval(env *policyEnv) interface{} {
+	return !isTruthy(n.operand.eval(env))
+}
+
+func (n binaryNode) eval(env *policyEnv) interface{} {
+	switch n.op {
+	case "&&":
+		return isTruthy(n.left.eval(env)) && isTruthy(n.right.eval(env))
+	case "||":
+		return isTruthy(n.left.eval(env)) || isTruthy(n.right.eval(env))
+	case "==", "!=":
+		// a missing value, e.g. a claim the token does not have, satisfies neither comparison
+		left, right := n.left.eval(env), n.right.eval(env)
+		if left == nil || right == nil {
+			return false
+		}
+		return isEqual(left, right) == (n.op == "==")
+	case "in":
+		return contains(n.right.eval(env), n.left.eval(env))
+	}
+	return false
+}
+
+// compilePolicy compiles a policy expression. The expressions compare the variables, the strings, the
+// numbers and the booleans with == and !=, look up a value in a list or a map with in, and combine the
+// conditions with !, && and || and parentheses, e.g.
+//
+//	"admin" in roles || (claims.tenant == params.tenant && "orders:write" in scopes)
+//
+// A comparison with a missing value, e.g. a claim or a param the request does not have, is false whether
+// with == or !=
+func compilePolicy(expr string) (policyNode, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &policyParser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %s in policy", p.tokens[p.pos].text)
+	}
+	return n, nil
+}
+
+type tokenKind int
+
+const (
+	identToken tokenKind = iota
+	stringToken
+	numberToken
+	operatorToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits a policy expression into its tokens
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(expr) && expr[end] != expr[i] {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, errors.New("Unterminated string in policy")
+			}
+			s := expr[i+1 : end]
+			if c == '"' {
+				var err error
+				if s, err = strconv.Unquote(expr[i : end+1]); err != nil {
+					return nil, fmt.Errorf("Invalid string %s in policy", expr[i:end+1])
+				}
+			}
+			tokens = append(tokens, token{kind: stringToken, text: s})
+			i = end + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(expr) && unicode.IsDigit(rune(expr[i+1]))):
+			end := i + 1
+			for end < len(expr) && (unicode.IsDigit(rune(expr[end])) || expr[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: numberToken, text: expr[i:end]})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i + 1
+			for end < len(expr) && (unicode.IsLetter(rune(expr[end])) || unicode.IsDigit(rune(expr[end])) ||
+				expr[end] == '_' || expr[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: identToken, text: expr[i:end]})
+			i = end
+		default:
+			op := ""
+			for _, o := range []string{"&&", "||", "==", "!=", "!", "(", ")"} {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("Unexpected %c in policy", c)
+			}
+			tokens = append(tokens, token{kind: operatorToken, text: op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// policyParser parses the tokens of a policy by recursive descent, the precedence being ||, &&, !, then
+// the comparisons
+type policyParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *policyParser) peek(kind tokenKind, text string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind && p.tokens[p.pos].text == text
+}
+
+func (p *policyParser) parseOr() (policyNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.peek(operatorToken, "||") {
+		p.pos++
+		var right policyNode
+		if right, err = p.parseAnd(); err == nil {
+			left = binaryNode{op: "||", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *policyParser) parseAnd() (policyNode, error) {
+	left, err := p.parseNot()
+	for err == nil && p.peek(operatorToken, "&&") {
+		p.pos++
+		var right policyNode
+		if right, err = p.parseNot(); err == nil {
+			left = binaryNode{op: "&&", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *policyParser) parseNot() (policyNode, error) {
+	if p.peek(operatorToken, "!") {
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *policyParser) parseComparison() (policyNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	var op string
+	switch {
+	case p.peek(operatorToken, "=="), p.peek(operatorToken, "!="), p.peek(identToken, "in"):
+		op = p.tokens[p.pos].text
+	default:
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *policyParser) parseOperand() (policyNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("Unexpected end of policy")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case stringToken:
+		return literalNode{value: t.text}, nil
+	case numberToken:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number %s in policy", t.text)
+		}
+		return literalNode{value: f}, nil
+	case identToken:
+		switch t.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "in":
+			return nil, errors.New("Unexpected in in policy")
+		}
+		parts := strings.Split(t.text, ".")
+		if !policyVariables[parts[0]] {
+			return nil, fmt.Errorf("Unknown variable %s in policy", parts[0])
+		}
+		return variableNode{name: parts[0], fields: parts[1:]}, nil
+	}
+	if t.text == "(" {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(operatorToken, ")") {
+			return nil, errors.New("Missing ) in policy")
+		}
+		p.pos++
+		return n, nil
+	}
+	return nil, fmt.Errorf("Unexpected %s in policy", t.text)
+}
+
+func toList(s []string) []interface{} {
+	l := make([]interface{}, len(s))
+	for i, e := range s {
+		l[i] = e
+	}
+	return l
+}
+
+func isTruthy(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case float64:
+		return t != 0
+	case []interface{}:
+		return len(t) > 0
+	case map[string]interface{}:
+		return len(t) > 0
+	}
+	return false
+}
+
+// isEqual compares the values of the policies, the numbers of the claims being float64 as decoded from JSON.
+// A missing value is equal to none
+func isEqual(a interface{}, b interface{}) bool {
+	switch a.(type) {
+	case bool, string, float64:
+		return a == b
+	}
+	return false
+}
+
+// contains checks if a list has the value, or a map has the value as key
+func contains(container interface{}, v interface{}) bool {
+	switch c := container.(type) {
+	case []interface{}:
+		for _, e := range c {
+			if isEqual(e, v) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		if s, ok := v.(string); ok {
+			_, found := c[s]
+			return found
+		}
+	}
+	return false
+}
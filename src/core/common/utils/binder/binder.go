@@ -0,0 +1,357 @@
+package binder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Struct tags naming the source of a field, the field name is used when the tag is absent
+const (
+	formTag   = "form"
+	queryTag  = "query"
+	headerTag = "header"
+	pathTag   = "path"
+)
+
+// Types declares the struct types the parts of a request are bound to. Each field holds a value of the
+// struct type or a pointer to it, e.g. Types{Body: CreateUserRequest{}}. Parts left nil are not bound
+type Types struct {
+	Body   interface{}
+	Query  interface{}
+	Header interface{}
+	Path   interface{}
+}
+
+// Values holds the pointers to the structs bound from the parts of a request, nil for the parts not bound
+type Values struct {
+	Body   interface{}
+	Query  interface{}
+	Header interface{}
+	Path   interface{}
+}
+
+// Binder binds the parts of a request to the declared types and validates them using the validate tags
+type Binder struct {
+	body   reflect.Type
+	query  reflect.Type
+	header reflect.Type
+	path   reflect.Type
+}
+
+// validate is safe for concurrent use and caches the struct details, hence shared by all the binders
+var validate = validator.New()
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// New creates a binder for the types, returns an error if any of them is not a struct
+func New(types Types) (*Binder, error) {
+	b := new(Binder)
+	var err error
+	if b.body, err = getStructType("Body", types.Body); err != nil {
+		return nil, err
+	}
+	if b.query, err = getStructType("Query", types.Query); err != nil {
+		return nil, err
+	}
+	if b.header, err = getStructType("Header", types.Header); err != nil {
+		return nil, err
+	}
+	if b.path, err = getStructType("Path", types.Path); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func getStructType(part string, v interface{}) (reflect.Type, error) {
+	if v == nil {
+		return nil, nil
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s type %T is not a struct", part, v)
+	}
+	return t, nil
+}
+
+// Bind decodes the parts of the request into new instances of the declared types and validates them.
+// The request body is restored so that it can be read again
+func (b *Binder) Bind(req *utilhttp.Request) (Values, *constants.AppErrors) {
+	var values Values
+	appErrs := new(constants.AppErrors)
+	if b.path != nil {
+		values.Path = b.bindPart(b.path, pathTag, "path param", getPathValues(req), appErrs)
+	}
+	if b.query != nil {
+		var query url.Values
+		if req.OriginalRequest != nil {
+			query = req.OriginalRequest.URL.Query()
+		}
+		values.Query = b.bindPart(b.query, queryTag, "query param", query, appErrs)
+	}
+	if b.header != nil {
+		var header map[string][]string
+		if req.OriginalRequest != nil {
+			header = req.OriginalRequest.Header
+		}
+		values.Header = b.bindPart(b.header, headerTag, "header", headerValues(header), appErrs)
+	}
+	if b.body != nil {
+		values.Body = b.bindBody(req, appErrs)
+	}
+	if len(appErrs.Errors) > 0 {
+		return values, appErrs
+	}
+	return values, nil
+}
+
+// bindPart binds and validates the values of a part of the request
+func (b *Binder) bindPart(t reflect.Type, tag string, source string, values map[string][]string,
+	appErrs *constants.AppErrors) interface{} {
+	ptr := reflect.New(t)
+	if err := setFields(ptr.Elem(), tag, source, values); err != nil {
+		appErrs.Errors = append(appErrs.Errors, constants.AppError{Code: constants.ParamsInValidErrorCode,
+			Message: err.Error()})
+		return ptr.Interface()
+	}
+	validateStruct(ptr.Interface(), appErrs)
+	return ptr.Interface()
+}
+
+// bindBody decodes the body as json, url encoded form or multipart form as per the content type
+func (b *Binder) bindBody(req *utilhttp.Request, appErrs *constants.AppErrors) interface{} {
+	ptr := reflect.New(b.body)
+	// the values of a parsed multipart upload as the body has already been read
+	if req.OriginalRequest != nil && req.OriginalRequest.MultipartForm != nil {
+		if err := setFields(ptr.Elem(), formTag, "form field", req.OriginalRequest.MultipartForm.Value); err != nil {
+			appErrs.Errors = append(appErrs.Errors, constants.AppError{Code: constants.ParamsInValidErrorCode,
+				Message: err.Error()})
+			return ptr.Interface()
+		}
+		validateStruct(ptr.Interface(), appErrs)
+		return ptr.Interface()
+	}
+	var body []byte
+	if req.OriginalRequest != nil && req.OriginalRequest.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.OriginalRequest.Body)
+		req.OriginalRequest.Body.Close()
+		req.OriginalRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			appErrs.Errors = append(appErrs.Errors, constants.AppError{Code: constants.ParamsInValidErrorCode,
+				Message: "Failed to read request body", DeveloperMessage: err.Error()})
+			return ptr.Interface()
+		}
+	}
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := decodeBody(ptr, req.Headers.ContentType, body); err != nil {
+			appErrs.Errors = append(appErrs.Errors, *err)
+			return ptr.Interface()
+		}
+	}
+	validateStruct(ptr.Interface(), appErrs)
+	return ptr.Interface()
+}
+
+func decodeBody(ptr reflect.Value, contentType string, body []byte) *constants.AppError {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if err := json.Unmarshal(body, ptr.Interface()); err != nil {
+			return &constants.AppError{Code: constants.ParamsInValidErrorCode, Message: "Invalid json body",
+				DeveloperMessage: err.Error()}
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			err = setFields(ptr.Elem(), formTag, "form field", values)
+		}
+		if err != nil {
+			return &constants.AppError{Code: constants.ParamsInValidErrorCode, Message: err.Error()}
+		}
+	case mediaType == "multipart/form-data":
+		values, err := readMultipartValues(body, params["boundary"])
+		if err == nil {
+			err = setFields(ptr.Elem(), formTag, "form field", values)
+		}
+		if err != nil {
+			return &constants.AppError{Code: constants.ParamsInValidErrorCode, Message: err.Error()}
+		}
+	default:
+		return &constants.AppError{Code: constants.UnsupportedMediaTypeErrorCode,
+			Message: fmt.Sprintf("Unsupported content type %s", mediaType)}
+	}
+	return nil
+}
+
+// readMultipartValues returns the values of the non file parts of a multipart form
+func readMultipartValues(body []byte, boundary string) (map[string][]string, error) {
+	if boundary == "" {
+		return nil, errors.New("Invalid multipart form. Boundary not specified")
+	}
+	values := make(map[string][]string)
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid multipart form. %s", err.Error())
+		}
+		if part.FormName() == "" || part.FileName() != "" {
+			continue
+		}
+		value, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid multipart form. %s", err.Error())
+		}
+		values[part.FormName()] = append(values[part.FormName()], string(value))
+	}
+}
+
+func getPathValues(req *utilhttp.Request) map[string][]string {
+	values := make(map[string][]string)
+	if req.PathParameters != nil {
+		for k, v := range *req.PathParameters {
+			values[k] = []string{v}
+		}
+	}
+	return values
+}
+
+// headerValues keys the header values by lower case names as header names are case insensitive
+func headerValues(header map[string][]string) map[string][]string {
+	values := make(map[string][]string, len(header))
+	for k, v := range header {
+		values[strings.ToLower(k)] = v
+	}
+	return values
+}
+
+// setFields sets the exported fields of the struct from the values named by the tag, fields of
+// embedded structs are set as if they were fields of the struct
+func setFields(s reflect.Value, tag string, source string, values map[string][]string) error {
+	t := s.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name := field.Tag.Get(tag)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := setFields(s.Field(i), tag, source, values); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if tag == headerTag {
+			name = strings.ToLower(name)
+		}
+		v, found := values[name]
+		if !found || len(v) == 0 {
+			continue
+		}
+		if err := setValue(s.Field(i), v); err != nil {
+			return fmt.Errorf("Invalid value for %s %s. %s", source, name, err.Error())
+		}
+	}
+	return nil
+}
+
+// setValue converts the values to the type of the field. Slices get all the values, other types the first
+func setValue(v reflect.Value, values []string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(ptr)
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.String:
+		v.SetString(values[0])
+	case reflect.Bool:
+		b, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(values[0], 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(values[0], 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(values[0], v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("Unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// validateStruct runs the validations of the validate tags and adds the failures to the errors
+func validateStruct(ptr interface{}, appErrs *constants.AppErrors) {
+	err := validate.Struct(ptr)
+	if err == nil {
+		return
+	}
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		appErrs.Errors = append(appErrs.Errors, constants.AppError{Code: constants.RequestValidationFailedCode,
+			Message: err.Error()})
+		return
+	}
+	for _, e := range validationErrs {
+		appErrs.Errors = append(appErrs.Errors, constants.AppError{
+			Code:             constants.RequestValidationFailedCode,
+			Message:          "Validation Failed for Field = " + e.StructNamespace(),
+			DeveloperMessage: "Validation Condition = " + e.ActualTag(),
+		})
+	}
+}
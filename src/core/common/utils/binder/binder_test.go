@@ -0,0 +1,144 @@
+package binder
+
+import (
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+type testBody struct {
+	Name  string `json:"name" form:"name" validate:"required"`
+	Email string `json:"email" form:"email" validate:"required,email"`
+}
+
+type testPaging struct {
+	Limit int `query:"limit" validate:"max=100"`
+}
+
+type testQuery struct {
+	testPaging
+	Tags  []string   `query:"tag"`
+	Since *time.Time `query:"since"`
+	Debug bool       `query:"-"`
+}
+
+type testHeader struct {
+	ClientID string `header:"X-Client-Id" validate:"required"`
+}
+
+type testPath struct {
+	ID uint64 `path:"id"`
+}
+
+func newTestRequest(method string, url string, contentType string, body string,
+	pathParams map[string]string) *utilhttp.Request {
+	r, _ := http.NewRequest(method, url, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("x-client-id", "app1")
+	req, _ := utilhttp.GetRequest(r)
+	req.PathParameters = &pathParams
+	return &req
+}
+
+/*
+Test binding of all the parts of a request
+*/
+func TestBind(t *testing.T) {
+	b, err := New(Types{Body: testBody{}, Query: &testQuery{}, Header: testHeader{}, Path: testPath{}})
+	if err != nil {
+		t.Fatalf("Failed to create binder. Err : %s", err)
+	}
+	req := newTestRequest("POST", "/app/v1/users/42?limit=10&tag=a&tag=b&since=2020-01-01T00:00:00Z&Debug=true",
+		"application/json", `{"name":"a","email":"a@b.com"}`, map[string]string{"id": "42"})
+	values, appErrs := b.Bind(req)
+	if appErrs != nil {
+		t.Fatalf("Unexpected errors %v", appErrs.Errors)
+	}
+	if body := values.Body.(*testBody); body.Name != "a" || body.Email != "a@b.com" {
+		t.Errorf("Body mismatch, got %+v", body)
+	}
+	query := values.Query.(*testQuery)
+	if query.Limit != 10 || len(query.Tags) != 2 || query.Tags[1] != "b" || query.Since == nil ||
+		query.Since.Year() != 2020 || query.Debug {
+		t.Errorf("Query mismatch, got %+v", query)
+	}
+	if header := values.Header.(*testHeader); header.ClientID != "app1" {
+		t.Errorf("Header mismatch, got %+v", header)
+	}
+	if path := values.Path.(*testPath); path.ID != 42 {
+		t.Errorf("Path mismatch, got %+v", path)
+	}
+	if body, _ := req.GetBodyParameter(); body != `{"name":"a","email":"a@b.com"}` {
+		t.Errorf("Body not restored after binding, got %s", body)
+	}
+}
+
+/*
+Test binding of form bodies
+*/
+func TestBindForm(t *testing.T) {
+	b, _ := New(Types{Body: testBody{}})
+	req := newTestRequest("POST", "/app/v1/users", "application/x-www-form-urlencoded", "name=a&email=a%40b.com", nil)
+	if values, appErrs := b.Bind(req); appErrs != nil || values.Body.(*testBody).Email != "a@b.com" {
+		t.Errorf("Form body mismatch, got %+v %v", values.Body, appErrs)
+	}
+
+	multipartBody := "--XX\r\nContent-Disposition: form-data; name=\"name\"\r\n\r\na\r\n" +
+		"--XX\r\nContent-Disposition: form-data; name=\"email\"\r\n\r\na@b.com\r\n" +
+		"--XX\r\nContent-Disposition: form-data; name=\"name\"; filename=\"f.txt\"\r\n\r\nfile\r\n--XX--\r\n"
+	req = newTestRequest("POST", "/app/v1/users", "multipart/form-data; boundary=XX", multipartBody, nil)
+	if values, appErrs := b.Bind(req); appErrs != nil || values.Body.(*testBody).Name != "a" {
+		t.Errorf("Multipart body mismatch, got %+v %v", values.Body, appErrs)
+	}
+
+	// the values of the upload parsed by the service
+	req = newTestRequest("POST", "/app/v1/users", "multipart/form-data; boundary=XX", multipartBody, nil)
+	req.OriginalRequest.MultipartForm = &multipart.Form{Value: map[string][]string{"name": {"a"},
+		"email": {"a@b.com"}}}
+	if values, appErrs := b.Bind(req); appErrs != nil || values.Body.(*testBody).Email != "a@b.com" {
+		t.Errorf("Parsed multipart body mismatch, got %+v %v", values.Body, appErrs)
+	}
+}
+
+/*
+Test decoding and validation errors
+*/
+func TestBindErrors(t *testing.T) {
+	if _, err := New(Types{Body: "body"}); err == nil {
+		t.Error("Expected error for a type which is not a struct")
+	}
+
+	b, _ := New(Types{Body: testBody{}, Query: testQuery{}})
+	cases := []struct {
+		url         string
+		contentType string
+		body        string
+		codes       []constants.APPErrorCode
+	}{
+		{"/users", "application/json", `{"name":"a"`, []constants.APPErrorCode{constants.ParamsInValidErrorCode}},
+		{"/users", "text/plain", `name`, []constants.APPErrorCode{constants.UnsupportedMediaTypeErrorCode}},
+		{"/users", "application/json", `{"email":"x"}`, []constants.APPErrorCode{constants.RequestValidationFailedCode,
+			constants.RequestValidationFailedCode}},
+		{"/users?limit=x", "application/json", `{"name":"a","email":"a@b.com"}`,
+			[]constants.APPErrorCode{constants.ParamsInValidErrorCode}},
+		{"/users?limit=500", "", "", []constants.APPErrorCode{constants.RequestValidationFailedCode,
+			constants.RequestValidationFailedCode, constants.RequestValidationFailedCode}},
+	}
+	for _, c := range cases {
+		_, appErrs := b.Bind(newTestRequest("POST", c.url, c.contentType, c.body, nil))
+		if appErrs == nil || len(appErrs.Errors) != len(c.codes) {
+			t.Errorf("Errors mismatch for %s %s, got %v", c.url, c.body, appErrs)
+			continue
+		}
+		for i, code := range c.codes {
+			if appErrs.Errors[i].Code != code {
+				t.Errorf("Error code mismatch for %s %s, got %v", c.url, c.body, appErrs.Errors)
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Package binder binds the body, query params, headers and path params of a request to typed structs
+// and validates them with the validate tags of gopkg.in/go-playground/validator.v9.
+//
+// The body is decoded as json (default), application/x-www-form-urlencoded or multipart/form-data as per
+// the Content-Type. Json fields are named by the json tags, the other parts by the form, query, header
+// and path tags, defaulting to the field name. Header names are case insensitive.
+//
+//	type CreateUserRequest struct {
+//		Name  string `json:"name" validate:"required"`
+//		Email string `json:"email" validate:"required,email"`
+//	}
+//
+//	type CreateUserQuery struct {
+//		DryRun bool `query:"dryRun"`
+//	}
+package binder
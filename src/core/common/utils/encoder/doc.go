@@ -0,0 +1,6 @@
+// Package encoder encodes the response envelope in the media type negotiated from the Accept header.
+// JSON, XML, MessagePack and protobuf encoders are registered by default, custom encoders can be registered
+// for other media types. Except JSON, the encoders encode the json form of the value so that the envelope
+// keeps the same field names and omissions across the media types. Protobuf responses are encoded as
+// google.protobuf.Struct
+package encoder
@@ -0,0 +1,205 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Encoder encodes a value in a media type
+type Encoder interface {
+	Encode(v interface{}) ([]byte, error)
+}
+
+// EncoderFunc adapts a function to an Encoder
+type EncoderFunc func(v interface{}) ([]byte, error)
+
+// Encode calls f(v)
+func (f EncoderFunc) Encode(v interface{}) ([]byte, error) {
+	return f(v)
+}
+
+// Media types of the default encoders
+const (
+	MediaTypeJSON      = "application/json"
+	MediaTypeXML       = "application/xml"
+	MediaTypeTextXML   = "text/xml"
+	MediaTypeMsgPack   = "application/msgpack"
+	MediaTypeXMsgPack  = "application/x-msgpack"
+	MediaTypeProtobuf  = "application/protobuf"
+	MediaTypeXProtobuf = "application/x-protobuf"
+
+	// DefaultMediaType is used when the request does not specify an acceptable media type
+	DefaultMediaType = MediaTypeJSON
+)
+
+var encoders = map[string]Encoder{}
+
+// mediaTypes are the registered media types in the order of registration
+var mediaTypes []string
+
+func init() {
+	Register(MediaTypeJSON, EncoderFunc(json.Marshal))
+	Register(MediaTypeXML, EncoderFunc(encodeXML))
+	Register(MediaTypeTextXML, EncoderFunc(encodeXML))
+	Register(MediaTypeMsgPack, EncoderFunc(encodeMsgPack))
+	Register(MediaTypeXMsgPack, EncoderFunc(encodeMsgPack))
+	Register(MediaTypeProtobuf, EncoderFunc(encodeProtobuf))
+	Register(MediaTypeXProtobuf, EncoderFunc(encodeProtobuf))
+}
+
+// Register registers the encoder for the media type, replacing the encoder registered earlier if any.
+// Registration is not safe for concurrent use and should be done while initialising the app
+func Register(mediaType string, e Encoder) {
+	mediaType = strings.ToLower(mediaType)
+	if _, found := encoders[mediaType]; !found {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	encoders[mediaType] = e
+}
+
+// Get returns the encoder registered for the media type
+func Get(mediaType string) (Encoder, bool) {
+	e, found := encoders[strings.ToLower(mediaType)]
+	return e, found
+}
+
+// Available returns the registered media types among allowed, all of them if allowed is empty, with the default
+// media type first
+func Available(allowed []string) []string {
+	return getCandidates(allowed)
+}
+
+// mediaRange is a media range of the Accept header
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// Negotiate returns the media type to respond with for the Accept header. The media types are restricted
+// to allowed if it is not empty. ok is false if none of the acceptable media types can be encoded.
+// Structured syntax suffixes are accepted as their base type, e.g. application/vnd.app.v2+json as json
+func Negotiate(accept string, allowed []string) (mediaType string, ok bool) {
+	candidates := getCandidates(allowed)
+	if len(candidates) == 0 {
+		return "", false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return candidates[0], true
+	}
+
+	ranges, excluded := parseAccept(accept)
+	for _, r := range ranges {
+		for _, c := range candidates {
+			if !excluded[c] && matches(r.mediaType, c) {
+				return c, true
+			}
+		}
+	}
+	return "", false
+}
+
+// getCandidates returns the registered media types among allowed with the default media type first
+func getCandidates(allowed []string) []string {
+	if len(allowed) == 0 {
+		allowed = mediaTypes
+	}
+	candidates := make([]string, 0, len(allowed))
+	for _, m := range allowed {
+		m = strings.ToLower(m)
+		if _, found := encoders[m]; !found {
+			continue
+		}
+		if m == DefaultMediaType {
+			candidates = append([]string{m}, candidates...)
+		} else {
+			candidates = append(candidates, m)
+		}
+	}
+	return candidates
+}
+
+// parseAccept returns the acceptable media ranges ordered by quality and then specificity, along with
+// the media types excluded explicitly with a zero quality
+func parseAccept(accept string) (ranges []mediaRange, excluded map[string]bool) {
+	excluded = make(map[string]bool)
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		if r.mediaType == "" {
+			continue
+		}
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.TrimSpace(kv[0]) == "q" {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil {
+					r.quality = q
+				}
+			}
+		}
+		if r.quality > 0 {
+			ranges = append(ranges, r)
+		} else if specificity(r.mediaType) == 2 {
+			excluded[r.mediaType] = true
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].quality != ranges[j].quality {
+			return ranges[i].quality > ranges[j].quality
+		}
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+	return ranges, excluded
+}
+
+func specificity(mediaType string) int {
+	switch {
+	case mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(mediaType, "/*"):
+		return 1
+	}
+	return 2
+}
+
+// matches checks if the media range accepts the media type
+func matches(mediaRange string, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	if strings.HasSuffix(mediaRange, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*"))
+	}
+	if index := strings.LastIndex(mediaRange, "+"); index != -1 {
+		slash := strings.Index(mediaRange, "/")
+		return slash != -1 && mediaRange[:slash+1]+mediaRange[index+1:] == mediaType
+	}
+	return false
+}
+
+// toGeneric returns the json form of the value as maps, slices, strings, json numbers, bools and nil
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var g interface{}
+	if err := decoder.Decode(&g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// sortedKeys returns the keys of the map in sorted order so that the encoding is deterministic
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package encoder
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testEnvelope struct {
+	Status string                 `json:"status"`
+	Data   interface{}            `json:"data"`
+	Debug  []string               `json:"debugData,omitempty"`
+	Meta   map[string]interface{} `json:"_metaData"`
+}
+
+/*
+Test the media type selection from the Accept header
+*/
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		accept    string
+		allowed   []string
+		mediaType string
+		ok        bool
+	}{
+		{"", nil, MediaTypeJSON, true},
+		{"*/*", nil, MediaTypeJSON, true},
+		{"application/xml", nil, MediaTypeXML, true},
+		{"text/html, application/msgpack;q=0.5, application/xml;q=0.8", nil, MediaTypeXML, true},
+		{"application/*;q=0.9, application/x-protobuf", nil, MediaTypeXProtobuf, true},
+		{"application/vnd.florest.v2+json", nil, MediaTypeJSON, true},
+		{"application/xml;q=0, */*;q=0.1", []string{MediaTypeXML}, "", false},
+		{"application/xml", []string{MediaTypeJSON}, "", false},
+		{"", []string{MediaTypeMsgPack, "application/unknown"}, MediaTypeMsgPack, true},
+		{"image/png", nil, "", false},
+	}
+	for _, c := range cases {
+		mediaType, ok := Negotiate(c.accept, c.allowed)
+		if mediaType != c.mediaType || ok != c.ok {
+			t.Errorf("Negotiate(%q, %v) = %s %v, expected %s %v", c.accept, c.allowed, mediaType, ok,
+				c.mediaType, c.ok)
+		}
+	}
+}
+
+/*
+Test the media types available to respond with
+*/
+func TestAvailable(t *testing.T) {
+	all := Available(nil)
+	if len(all) != len(mediaTypes) || all[0] != DefaultMediaType {
+		t.Errorf("Expected all the media types with the default first, got %v", all)
+	}
+	if available := Available([]string{"application/unknown", MediaTypeXML}); len(available) != 1 ||
+		available[0] != MediaTypeXML {
+		t.Errorf("Expected the registered media types among the allowed, got %v", available)
+	}
+}
+
+/*
+Test the envelope is encoded with the same field names in all the media types
+*/
+func TestEncoders(t *testing.T) {
+	v := testEnvelope{Status: "ok", Data: []interface{}{1, -200, 1.5, true, nil, "a<b"},
+		Meta: map[string]interface{}{"1st": "x"}}
+
+	xmlBody, err := encodeXML(v)
+	expectedXML := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><_metaData><item key="1st">x</item>` +
+		`</_metaData><data><item>1</item><item>-200</item><item>1.5</item><item>true</item><item/>` +
+		`<item>a&lt;b</item></data><status>ok</status></response>`
+	if err != nil || string(xmlBody) != expectedXML {
+		t.Errorf("XML encoding mismatch, got %s %v", xmlBody, err)
+	}
+
+	msgPackBody, err := encodeMsgPack(v)
+	expectedMsgPack := []byte{0x83,
+		0xa9, '_', 'm', 'e', 't', 'a', 'D', 'a', 't', 'a', 0x81, 0xa3, '1', 's', 't', 0xa1, 'x',
+		0xa4, 'd', 'a', 't', 'a', 0x96, 0x01, 0xd1, 0xff, 0x38, 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0, 0xc3, 0xc0,
+		0xa3, 'a', '<', 'b',
+		0xa6, 's', 't', 'a', 't', 'u', 's', 0xa2, 'o', 'k'}
+	if err != nil || !bytes.Equal(msgPackBody, expectedMsgPack) {
+		t.Errorf("MessagePack encoding mismatch, got % x %v", msgPackBody, err)
+	}
+
+	protoBody, err := encodeProtobuf(map[string]interface{}{"a": true, "b": []interface{}{"x"}})
+	expectedProto := []byte{
+		0x0a, 0x07, 0x0a, 0x01, 'a', 0x12, 0x02, 0x20, 0x01,
+		0x0a, 0x0c, 0x0a, 0x01, 'b', 0x12, 0x07, 0x32, 0x05, 0x0a, 0x03, 0x1a, 0x01, 'x'}
+	if err != nil || !bytes.Equal(protoBody, expectedProto) {
+		t.Errorf("Protobuf encoding mismatch, got % x %v", protoBody, err)
+	}
+}
@@ -0,0 +1,121 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// field numbers of google.protobuf.Value
+const (
+	valueNull   = 1
+	valueNumber = 2
+	valueString = 3
+	valueBool   = 4
+	valueStruct = 5
+	valueList   = 6
+)
+
+// encodeProtobuf encodes the json form of the value as google.protobuf.Struct, or as
+// google.protobuf.Value if the value is not an object, so that it can be decoded without
+// a schema specific to the api
+func encodeProtobuf(v interface{}) ([]byte, error) {
+	g, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if m, ok := g.(map[string]interface{}); ok {
+		err = writeProtoStruct(buf, m)
+	} else {
+		err = writeProtoValue(buf, g)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeProtoStruct writes the fields of google.protobuf.Struct, map<string, Value> fields = 1
+func writeProtoStruct(buf *bytes.Buffer, m map[string]interface{}) error {
+	for _, k := range sortedKeys(m) {
+		entry := new(bytes.Buffer)
+		writeProtoBytes(entry, 1, []byte(k))
+		value := new(bytes.Buffer)
+		if err := writeProtoValue(value, m[k]); err != nil {
+			return err
+		}
+		writeProtoBytes(entry, 2, value.Bytes())
+		writeProtoBytes(buf, 1, entry.Bytes())
+	}
+	return nil
+}
+
+// writeProtoValue writes the fields of google.protobuf.Value
+func writeProtoValue(buf *bytes.Buffer, g interface{}) error {
+	switch value := g.(type) {
+	case nil:
+		writeProtoTag(buf, valueNull, wireVarint)
+		writeProtoVarint(buf, 0)
+	case bool:
+		writeProtoTag(buf, valueBool, wireVarint)
+		if value {
+			writeProtoVarint(buf, 1)
+		} else {
+			writeProtoVarint(buf, 0)
+		}
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return err
+		}
+		writeProtoTag(buf, valueNumber, wireFixed64)
+		binary.Write(buf, binary.LittleEndian, math.Float64bits(f))
+	case string:
+		writeProtoBytes(buf, valueString, []byte(value))
+	case map[string]interface{}:
+		s := new(bytes.Buffer)
+		if err := writeProtoStruct(s, value); err != nil {
+			return err
+		}
+		writeProtoBytes(buf, valueStruct, s.Bytes())
+	case []interface{}:
+		// google.protobuf.ListValue, repeated Value values = 1
+		l := new(bytes.Buffer)
+		for _, item := range value {
+			v := new(bytes.Buffer)
+			if err := writeProtoValue(v, item); err != nil {
+				return err
+			}
+			writeProtoBytes(l, 1, v.Bytes())
+		}
+		writeProtoBytes(buf, valueList, l.Bytes())
+	default:
+		return fmt.Errorf("Unsupported type %T for protobuf encoding", g)
+	}
+	return nil
+}
+
+func writeProtoTag(buf *bytes.Buffer, field uint64, wireType uint64) {
+	writeProtoVarint(buf, field<<3|wireType)
+}
+
+func writeProtoVarint(buf *bytes.Buffer, x uint64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	buf.Write(b[:binary.PutUvarint(b, x)])
+}
+
+func writeProtoBytes(buf *bytes.Buffer, field uint64, b []byte) {
+	writeProtoTag(buf, field, wireBytes)
+	writeProtoVarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
@@ -0,0 +1,90 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	xmlRootElement = "response"
+	xmlItemElement = "item"
+)
+
+// encodeXML encodes the json form of the value under the response element. Object keys become the
+// element names, array values are item elements and keys which are not valid element names are
+// encoded as item elements with a key attribute
+func encodeXML(v interface{}) ([]byte, error) {
+	g, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString(xml.Header)
+	if err := writeXMLElement(buf, xmlRootElement, "", g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeXMLElement(buf *bytes.Buffer, name string, key string, g interface{}) error {
+	buf.WriteString("<" + name)
+	if key != "" {
+		buf.WriteString(` key="`)
+		xml.EscapeText(buf, []byte(key))
+		buf.WriteString(`"`)
+	}
+	if g == nil {
+		buf.WriteString("/>")
+		return nil
+	}
+	buf.WriteString(">")
+
+	switch value := g.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(value) {
+			childName, childKey := k, ""
+			if !isXMLName(k) {
+				childName, childKey = xmlItemElement, k
+			}
+			if err := writeXMLElement(buf, childName, childKey, value[k]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if err := writeXMLElement(buf, xmlItemElement, "", item); err != nil {
+				return err
+			}
+		}
+	case string:
+		xml.EscapeText(buf, []byte(value))
+	case json.Number:
+		buf.WriteString(value.String())
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+	default:
+		return fmt.Errorf("Unsupported type %T for xml encoding", g)
+	}
+	buf.WriteString("</" + name + ">")
+	return nil
+}
+
+// isXMLName checks if the key can be used as an element name as is
+func isXMLName(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case i > 0 && (c == '-' || c == '.' || c >= '0' && c <= '9'):
+		default:
+			return false
+		}
+	}
+	// names starting with xml are reserved
+	return !strings.HasPrefix(strings.ToLower(key), "xml")
+}
@@ -0,0 +1,195 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultTimeout is the timeout of the checks if neither the check nor the set specifies one
+const defaultTimeout = 2 * time.Second
+
+// Statuses of the checks
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker checks the health of the app or of a dependency
+type Checker interface {
+	GetName() string
+	// Check returns an error if not healthy. It should give up once the context is done
+	Check(ctx context.Context) error
+}
+
+// CheckOptions are the timeout and the caching of a check, taken from the defaults of its set when zero
+type CheckOptions struct {
+	// Timeout is how long the check can take before it is reported down
+	Timeout time.Duration
+	// CacheTTL is how long the result of the check is reused by the next runs
+	CacheTTL time.Duration
+}
+
+// Result is the result of a check
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	// Cached is set if the result is reused from a previous run
+	Cached bool `json:"cached,omitempty"`
+}
+
+// Report is the result of a set of checks, up if all of them are up
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// checkerFunc is a Checker running a function
+type checkerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c checkerFunc) GetName() string {
+	return c.name
+}
+
+func (c checkerFunc) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+// NewChecker returns a checker running the function
+func NewChecker(name string, fn func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, fn: fn}
+}
+
+// check runs a checker, sharing a run in flight between the concurrent callers and caching its result
+type check struct {
+	checker   Checker
+	opts      CheckOptions
+	mutex     sync.Mutex
+	result    *Result
+	checkedAt time.Time
+	// running is closed once the run in flight is done, nil if none
+	running chan struct{}
+}
+
+// Checks is a set of checks run in parallel
+type Checks struct {
+	mutex    sync.RWMutex
+	checks   []*check
+	defaults CheckOptions
+}
+
+// SetDefaults sets the options of the checks added without
+func (cs *Checks) SetDefaults(opts CheckOptions) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.defaults = opts
+}
+
+// Add adds a checker to the set
+func (cs *Checks) Add(c Checker, opts CheckOptions) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.checks = append(cs.checks, &check{checker: c, opts: opts})
+}
+
+// Run runs the checks in parallel and returns their report
+func (cs *Checks) Run() Report {
+	cs.mutex.RLock()
+	checks := cs.checks
+	defaults := cs.defaults
+	cs.mutex.RUnlock()
+	if defaults.Timeout <= 0 {
+		defaults.Timeout = defaultTimeout
+	}
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(defaults)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.checker.GetName()] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run returns the cached result if fresh, else the result of the run in flight or of a new run. The
+// caller gets a down result once the timeout elapses, the run going on to be shared with the next callers
+func (c *check) run(defaults CheckOptions) Result {
+	opts := c.opts
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaults.Timeout
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = defaults.CacheTTL
+	}
+	c.mutex.Lock()
+	if c.result != nil && time.Since(c.checkedAt) < opts.CacheTTL {
+		r := *c.result
+		c.mutex.Unlock()
+		r.Cached = true
+		return r
+	}
+	done := c.running
+	if done == nil {
+		done = make(chan struct{})
+		c.running = done
+		go c.execute(done, opts.Timeout)
+	}
+	c.mutex.Unlock()
+
+	timer := time.NewTimer(opts.Timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return *c.result
+	case <-timer.C:
+		return Result{Status: StatusDown, Error: fmt.Sprintf("Timed out after %s", opts.Timeout),
+			DurationMs: int64(opts.Timeout / time.Millisecond)}
+	}
+}
+
+// execute runs the checker and records its result
+func (c *check) execute(done chan struct{}, timeout time.Duration) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	err := c.safeCheck(ctx)
+	cancel()
+	r := &Result{Status: StatusUp, DurationMs: int64(time.Since(start) / time.Millisecond)}
+	if err != nil {
+		r.Status = StatusDown
+		r.Error = err.Error()
+	}
+	c.mutex.Lock()
+	c.result = r
+	c.checkedAt = time.Now()
+	c.running = nil
+	c.mutex.Unlock()
+	close(done)
+}
+
+// safeCheck runs the checker, a panic being reported as an error
+func (c *check) safeCheck(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Check panicked. Err : %v", r)
+		}
+	}()
+	return c.checker.Check(ctx)
+}
@@ -0,0 +1,115 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChecksRun(t *testing.T) {
+	var cs Checks
+	cs.Add(NewChecker("up", func(ctx context.Context) error { return nil }), CheckOptions{})
+	report := cs.Run()
+	if report.Status != StatusUp || report.Checks["up"].Status != StatusUp {
+		t.Errorf("Expected up report, got %+v", report)
+	}
+
+	cs.Add(NewChecker("down", func(ctx context.Context) error { return errors.New("refused") }), CheckOptions{})
+	cs.Add(NewChecker("panic", func(ctx context.Context) error { panic("boom") }), CheckOptions{})
+	report = cs.Run()
+	if report.Status != StatusDown || report.Checks["down"].Error != "refused" ||
+		report.Checks["panic"].Status != StatusDown || report.Checks["up"].Status != StatusUp {
+		t.Errorf("Expected down report, got %+v", report)
+	}
+
+	var empty Checks
+	if report := empty.Run(); report.Status != StatusUp {
+		t.Errorf("Expected up report without checks, got %+v", report)
+	}
+}
+
+func TestChecksTimeoutAndParallel(t *testing.T) {
+	var cs Checks
+	cs.SetDefaults(CheckOptions{Timeout: 50 * time.Millisecond})
+	for _, name := range []string{"slow1", "slow2", "slow3"} {
+		cs.Add(NewChecker(name, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}), CheckOptions{})
+	}
+	cs.Add(NewChecker("fast", func(ctx context.Context) error { return nil }), CheckOptions{Timeout: time.Second})
+	start := time.Now()
+	report := cs.Run()
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Checks not run in parallel, took %s", elapsed)
+	}
+	if report.Status != StatusDown || report.Checks["slow1"].Status != StatusDown ||
+		report.Checks["fast"].Status != StatusUp {
+		t.Errorf("Expected slow checks down, got %+v", report)
+	}
+
+	// a check ignoring the context is reported down once its timeout elapses
+	var ignoring Checks
+	release := make(chan struct{})
+	defer close(release)
+	ignoring.Add(NewChecker("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	}), CheckOptions{Timeout: 20 * time.Millisecond})
+	if report := ignoring.Run(); report.Checks["stuck"].Status != StatusDown {
+		t.Errorf("Expected stuck check down, got %+v", report)
+	}
+}
+
+func TestChecksCacheAndCoalescing(t *testing.T) {
+	var runs int32
+	var cs Checks
+	cs.Add(NewChecker("db", func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}), CheckOptions{CacheTTL: time.Minute})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cs.Run()
+		}()
+	}
+	wg.Wait()
+	report := cs.Run()
+	if n := atomic.LoadInt32(&runs); n != 1 {
+		t.Errorf("Expected the concurrent runs to share one check, got %d", n)
+	}
+	if !report.Checks["db"].Cached {
+		t.Errorf("Expected cached result, got %+v", report)
+	}
+
+	var uncached Checks
+	uncached.Add(NewChecker("db", func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}), CheckOptions{})
+	uncached.Run()
+	uncached.Run()
+	if n := atomic.LoadInt32(&runs); n != 3 {
+		t.Errorf("Expected the results not to be cached, got %d runs", n)
+	}
+}
+
+func TestDiskChecker(t *testing.T) {
+	if err := NewDiskChecker("/", 1).Check(context.Background()); err != nil {
+		t.Errorf("Expected free space on /. Err : %s", err)
+	}
+	if err := NewDiskChecker("/", 1<<62).Check(context.Background()); err == nil {
+		t.Error("Expected not enough free space on /")
+	}
+	if err := NewDiskChecker("/does/not/exist", 1).Check(context.Background()); err == nil {
+		t.Error("Expected error for a missing path")
+	}
+}
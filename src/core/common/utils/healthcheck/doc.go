@@ -1,2 +1,5 @@
 // Package HealthCheck can used to verify the health of the app
+//
+// The liveness and readiness of the app are checked by the Checkers added with AddLivenessCheck and
+// AddReadinessCheck, run in parallel with a timeout and their results cached as per their CheckOptions
 package healthcheck
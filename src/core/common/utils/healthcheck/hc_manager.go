@@ -8,3 +8,38 @@ func Initialise(apiList []HCInterface) {
 		healthCheckAPIList = apiList
 	}
 }
+
+//Reset clears the health checks of the apis and the liveness and readiness checks, e.g. between tests
+func Reset() {
+	healthCheckAPIList = nil
+	liveness, readiness = Checks{}, Checks{}
+}
+
+//liveness and readiness are the checks of the liveness and the readiness of the app
+var liveness, readiness Checks
+
+//AddLivenessCheck adds a check of the liveness of the app, which should fail only if the app needs a restart
+func AddLivenessCheck(c Checker, opts CheckOptions) {
+	liveness.Add(c, opts)
+}
+
+//AddReadinessCheck adds a check of the readiness of the app to serve, e.g. of a dependency
+func AddReadinessCheck(c Checker, opts CheckOptions) {
+	readiness.Add(c, opts)
+}
+
+//SetDefaultOptions sets the options of the liveness and readiness checks added without
+func SetDefaultOptions(opts CheckOptions) {
+	liveness.SetDefaults(opts)
+	readiness.SetDefaults(opts)
+}
+
+//Live runs the liveness checks, up if there are none
+func Live() Report {
+	return liveness.Run()
+}
+
+//Ready runs the readiness checks, up if there are none
+func Ready() Report {
+	return readiness.Run()
+}
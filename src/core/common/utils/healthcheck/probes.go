@@ -0,0 +1,72 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	"github.com/jabong/florest-core/src/components/cache"
+	"github.com/jabong/florest-core/src/components/mongodb"
+	"github.com/jabong/florest-core/src/components/sqldb"
+)
+
+// cacheProbeKey is the key written by the cache checks
+const cacheProbeKey = "florest:healthcheck"
+
+// cacheProbeTTL is the expiry in seconds of the key written by the cache checks
+const cacheProbeTTL = 60
+
+// NewSQLDBChecker returns a checker pinging the sql db registered with the key
+func NewSQLDBChecker(key string) Checker {
+	return NewChecker("sqldb:"+key, func(ctx context.Context) error {
+		db, err := sqldb.Get(key)
+		if err != nil {
+			return err
+		}
+		if err := db.Ping(); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// NewMongoDBChecker returns a checker pinging the mongodb registered with the key
+func NewMongoDBChecker(key string) Checker {
+	return NewChecker("mongodb:"+key, func(ctx context.Context) error {
+		db, err := mongodb.Get(key)
+		if err != nil {
+			return err
+		}
+		if err := db.Ping(); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// NewCacheChecker returns a checker writing a key to the cache registered with the key, the caches having
+// no ping
+func NewCacheChecker(key string) Checker {
+	return NewChecker("cache:"+key, func(ctx context.Context) error {
+		c, err := cache.Get(key)
+		if err != nil {
+			return err
+		}
+		return c.SetWithTimeout(cache.Item{Key: cacheProbeKey, Value: "ok"}, false, false, cacheProbeTTL)
+	})
+}
+
+// NewDiskChecker returns a checker of the free space of the disk of the path
+func NewDiskChecker(path string, minFreeBytes uint64) Checker {
+	return NewChecker("disk:"+path, func(ctx context.Context) error {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(path, &stat); err != nil {
+			return err
+		}
+		free := stat.Bavail * uint64(stat.Bsize)
+		if free < minFreeBytes {
+			return fmt.Errorf("%d MB free, %d MB required", free>>20, minFreeBytes>>20)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jabong/florest-core/src/components/cache"
+)
+
+// cacheStore keeps the records as json in a cache of the cache component. As the cache has no atomic
+// set if absent, the requests are only guaranteed to begin once per key within an instance of the app
+type cacheStore struct {
+	cache     cache.CInterface
+	keyPrefix string
+	mutex     sync.Mutex
+}
+
+// NewCacheStore returns a store keeping the records in the cache, the keys prefixed by keyPrefix
+func NewCacheStore(c cache.CInterface, keyPrefix string) Store {
+	return &cacheStore{cache: c, keyPrefix: keyPrefix}
+}
+
+func (s *cacheStore) Begin(key string, bodyHash string, ttl time.Duration) (*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if record := s.get(key); record != nil {
+		return record, nil
+	}
+	return nil, s.set(key, &Record{BodyHash: bodyHash}, ttl)
+}
+
+func (s *cacheStore) Complete(key string, record *Record, ttl time.Duration) error {
+	r := *record
+	r.Completed = true
+	return s.set(key, &r, ttl)
+}
+
+func (s *cacheStore) Release(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if record := s.get(key); record == nil || record.Completed {
+		return nil
+	}
+	return s.cache.Delete(s.keyPrefix + key)
+}
+
+// get returns the record of the key, nil if not found. The cache does not tell a missing key from an error
+func (s *cacheStore) get(key string) *Record {
+	item, err := s.cache.Get(s.keyPrefix+key, false, false)
+	if err != nil || item == nil {
+		return nil
+	}
+	var b []byte
+	switch v := item.Value.(type) {
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return nil
+	}
+	record := new(Record)
+	if err := json.Unmarshal(b, record); err != nil {
+		return nil
+	}
+	return record
+}
+
+func (s *cacheStore) set(key string, record *Record, ttl time.Duration) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	// the cache expires the items in seconds
+	secs := int32(math.Ceil(ttl.Seconds()))
+	if secs < 1 {
+		return errors.New("TTL should be positive")
+	}
+	return s.cache.SetWithTimeout(cache.Item{Key: s.keyPrefix + key, Value: string(b)}, false, false, secs)
+}
@@ -0,0 +1,17 @@
+// Package idempotency stores the responses of the requests made with an idempotency key, so that the retries
+// of a request with the same key get the response of the first one. A store records the first request as in
+// flight, along with a hash of its body, till its response is stored.
+//
+// The stores keep the records in memory, in a cache registered with the cache component or in a sql table
+// of a db registered with the sqldb component:
+//
+//	CREATE TABLE idempotency_keys (
+//		idempotency_key VARCHAR(512) NOT NULL PRIMARY KEY,
+//		body_hash       VARCHAR(64) NOT NULL,
+//		completed       TINYINT NOT NULL,
+//		status          INT NOT NULL,
+//		headers         TEXT,
+//		body            MEDIUMBLOB,
+//		expires_at      BIGINT NOT NULL
+//	)
+package idempotency
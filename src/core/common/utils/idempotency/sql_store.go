@@ -0,0 +1,93 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jabong/florest-core/src/components/sqldb"
+)
+
+// sqlStore keeps the records in a sql table, the primary key on the idempotency key making the requests
+// begin once per key across the instances of the app. The table is described in the package doc
+type sqlStore struct {
+	db    sqldb.SDBInterface
+	table string
+}
+
+// NewSQLStore returns a store keeping the records in the table of the db
+func NewSQLStore(db sqldb.SDBInterface, table string) Store {
+	return &sqlStore{db: db, table: table}
+}
+
+func (s *sqlStore) Begin(key string, bodyHash string, ttl time.Duration) (*Record, error) {
+	now := time.Now()
+	// the expired record of the key, if any, is replaced
+	if _, err := s.db.Execute(fmt.Sprintf("DELETE FROM %s WHERE idempotency_key = ? AND expires_at < ?", s.table),
+		key, toMillis(now)); err != nil {
+		return nil, err
+	}
+	_, ierr := s.db.Execute(fmt.Sprintf("INSERT INTO %s (idempotency_key, body_hash, completed, status, "+
+		"expires_at) VALUES (?, ?, 0, 0, ?)", s.table), key, bodyHash, toMillis(now.Add(ttl)))
+	if ierr == nil {
+		return nil, nil
+	}
+	// the insert fails on a duplicate key, the record of which is returned
+	record, err := s.get(key)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ierr
+	}
+	return record, nil
+}
+
+func (s *sqlStore) Complete(key string, record *Record, ttl time.Duration) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.Execute(fmt.Sprintf("UPDATE %s SET completed = 1, status = ?, headers = ?, body = ?, "+
+		"expires_at = ? WHERE idempotency_key = ?", s.table), record.Status, string(headers), record.Body,
+		toMillis(time.Now().Add(ttl)), key); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *sqlStore) Release(key string) error {
+	if _, err := s.db.Execute(fmt.Sprintf("DELETE FROM %s WHERE idempotency_key = ? AND completed = 0", s.table),
+		key); err != nil {
+		return err
+	}
+	return nil
+}
+
+// get returns the record of the key, nil if not found
+func (s *sqlStore) get(key string) (*Record, error) {
+	rows, qerr := s.db.Query(fmt.Sprintf("SELECT body_hash, completed, status, headers, body FROM %s "+
+		"WHERE idempotency_key = ?", s.table), key)
+	if qerr != nil {
+		return nil, qerr
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	record := new(Record)
+	var headers []byte
+	if err := rows.Scan(&record.BodyHash, &record.Completed, &record.Status, &headers, &record.Body); err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &record.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
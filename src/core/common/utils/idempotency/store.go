@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Record is the outcome of the first request made with an idempotency key
+type Record struct {
+	BodyHash string
+	// Completed is false while the first request is in flight
+	Completed bool
+	Status    int
+	Headers   map[string]string `json:",omitempty"`
+	Body      []byte
+}
+
+// Store stores the records by idempotency key
+type Store interface {
+	// Begin records the request with the key as in flight, unless a record exists for the key which is
+	// then returned. The record expires after ttl
+	Begin(key string, bodyHash string, ttl time.Duration) (*Record, error)
+
+	// Complete stores the response of the request in flight with the key. The record expires after ttl
+	Complete(key string, record *Record, ttl time.Duration) error
+
+	// Release removes the record of the request in flight with the key, so that it can be retried
+	Release(key string) error
+}
+
+// HashBody returns the hash of a request body stored in the records
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// memoryStore keeps the records in memory, hence they are not shared by the instances of the app
+type memoryStore struct {
+	mutex   sync.Mutex
+	records map[string]*memoryRecord
+	// the expired records are pruned once as many records are stored
+	nextPrune int
+}
+
+type memoryRecord struct {
+	record  Record
+	expires time.Time
+}
+
+// minPrune is the number of records stored before the expired ones are pruned
+const minPrune = 1024
+
+// NewMemoryStore returns a store keeping the records in memory
+func NewMemoryStore() Store {
+	return &memoryStore{records: make(map[string]*memoryRecord), nextPrune: minPrune}
+}
+
+func (s *memoryStore) Begin(key string, bodyHash string, ttl time.Duration) (*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	if r, ok := s.records[key]; ok && now.Before(r.expires) {
+		record := r.record
+		return &record, nil
+	}
+	s.records[key] = &memoryRecord{record: Record{BodyHash: bodyHash}, expires: now.Add(ttl)}
+	if len(s.records) >= s.nextPrune {
+		for k, r := range s.records {
+			if now.After(r.expires) {
+				delete(s.records, k)
+			}
+		}
+		s.nextPrune = 2*len(s.records) + minPrune
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(key string, record *Record, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	r := *record
+	r.Completed = true
+	s.records[key] = &memoryRecord{record: r, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) Release(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if r, ok := s.records[key]; ok && !r.record.Completed {
+		delete(s.records, key)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package idempotency
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/components/cache"
+)
+
+// memCache is a cache.CInterface keeping the items in a map
+type memCache struct {
+	mutex sync.Mutex
+	items map[string]interface{}
+}
+
+func (m *memCache) Init(conf *cache.Config) error { return nil }
+
+func (m *memCache) Get(key string, serialize bool, compress bool) (*cache.Item, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	v, ok := m.items[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &cache.Item{Key: key, Value: v}, nil
+}
+
+func (m *memCache) Set(item cache.Item, serialize bool, compress bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.items[item.Key] = item.Value
+	return nil
+}
+
+func (m *memCache) SetWithTimeout(item cache.Item, serialize bool, compress bool, ttl int32) error {
+	return m.Set(item, serialize, compress)
+}
+
+func (m *memCache) Delete(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.items, key)
+	return nil
+}
+
+func (m *memCache) DeleteBatch(keys []string) error {
+	for _, k := range keys {
+		m.Delete(k)
+	}
+	return nil
+}
+
+func (m *memCache) GetBatch(keys []string, serialize bool, compress bool) (map[string]*cache.Item, error) {
+	return nil, errors.New("not supported")
+}
+
+func testStore(t *testing.T, s Store) {
+	hash := HashBody([]byte(`{"amount":10}`))
+	if r, err := s.Begin("pay-1", hash, time.Minute); r != nil || err != nil {
+		t.Fatalf("First request should begin, got %v %v", r, err)
+	}
+	r, err := s.Begin("pay-1", HashBody([]byte(`{"amount":20}`)), time.Minute)
+	if err != nil || r == nil || r.Completed || r.BodyHash != hash {
+		t.Fatalf("Expected the record in flight, got %+v %v", r, err)
+	}
+
+	if err := s.Release("pay-1"); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := s.Begin("pay-1", hash, time.Minute); r != nil {
+		t.Fatal("Released request should begin again")
+	}
+
+	record := &Record{BodyHash: hash, Status: 201, Headers: map[string]string{"Location": "/payments/1"},
+		Body: []byte(`{"id":1}`)}
+	if err := s.Complete("pay-1", record, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	r, err = s.Begin("pay-1", hash, time.Minute)
+	if err != nil || r == nil || !r.Completed || r.Status != 201 || string(r.Body) != `{"id":1}` ||
+		r.Headers["Location"] != "/payments/1" {
+		t.Fatalf("Expected the completed record, got %+v %v", r, err)
+	}
+	if err := s.Release("pay-1"); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := s.Begin("pay-1", hash, time.Minute); r == nil {
+		t.Fatal("Completed record should not be released")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	testStore(t, s)
+
+	s.Begin("expired", "", -time.Second)
+	if r, _ := s.Begin("expired", "", time.Minute); r != nil {
+		t.Fatal("Expired record should not be returned")
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	begun := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r, _ := s.Begin("concurrent", "", time.Minute); r == nil {
+				mutex.Lock()
+				begun++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if begun != 1 {
+		t.Fatalf("Expected one of the concurrent requests to begin, got %d", begun)
+	}
+}
+
+func TestCacheStore(t *testing.T) {
+	c := &memCache{items: make(map[string]interface{})}
+	s := NewCacheStore(c, "idem:")
+	testStore(t, s)
+
+	if _, ok := c.items["idem:pay-1"]; !ok {
+		t.Fatal("Expected the key prefixed")
+	}
+	if _, err := s.Begin("none", "", 0); err == nil {
+		t.Fatal("TTL of 0 should fail")
+	}
+}
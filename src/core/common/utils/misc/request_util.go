@@ -18,3 +18,13 @@ func GetRequestFromIO(io orchestrator.WorkFlowData) (*utilhttp.Request, error) {
 	}
 	return appHTTPReq, nil
 }
+
+// GetStreamFromIO returns the response stream from IO object, available only to the apis declaring a stream format
+func GetStreamFromIO(io orchestrator.WorkFlowData) (*utilhttp.Stream, error) {
+	s, _ := io.IOData.Get(constants.ResponseStream)
+	stream, ok := s.(*utilhttp.Stream)
+	if !ok || stream == nil {
+		return nil, errors.New("Response stream not available")
+	}
+	return stream, nil
+}
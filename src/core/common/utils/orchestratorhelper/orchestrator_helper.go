@@ -14,21 +14,32 @@ func GetOrchestrator(resource string,
 	version string,
 	action string,
 	bucketID string, pathParams string) (*workflow.Orchestrator, *ratelimiter.RateLimiter, *map[string]string, error) {
+	orchestrator, ratelimiter, parameters, _, err := ResolveOrchestrator(resource, version, action, bucketID, pathParams)
+	return orchestrator, ratelimiter, parameters, err
+}
+
+// ResolveOrchestrator is same as GetOrchestrator but also returns the version which serves the request
+// after applying the version fallback chain
+func ResolveOrchestrator(resource string,
+	version string,
+	action string,
+	bucketID string, pathParams string) (*workflow.Orchestrator, *ratelimiter.RateLimiter, *map[string]string, string, error) {
 
 	logFormatter := "GetOrchestrator ==== Resource: %v === Version: %v === Action: %v === BucketId: %v === PathParams: %v"
 	logger.Info(fmt.Sprintf(logFormatter, resource, version, action, bucketID, pathParams))
-	orchestratorVersion, ratelimiter, parameters, gerr := versionmanager.Get(resource, version, action, bucketID, pathParams)
+	orchestratorVersion, ratelimiter, parameters, servedVersion, gerr := versionmanager.Resolve(resource, version,
+		action, bucketID, pathParams)
 	if gerr != nil {
-		return nil, nil, nil, &constants.AppError{Code: constants.InvalidRequestURI, Message: gerr.Error()}
+		return nil, nil, nil, "", &constants.AppError{Code: constants.InvalidRequestURI, Message: gerr.Error()}
 	}
 
 	orchestrator, ok := orchestratorVersion.(workflow.Orchestrator)
 	if !ok {
-		return nil, nil, nil, &constants.AppError{Code: constants.ResourceErrorCode,
+		return nil, nil, nil, "", &constants.AppError{Code: constants.ResourceErrorCode,
 			Message: "Error retrieving orchestrator"}
 	}
 
-	return &orchestrator, ratelimiter, &parameters, nil
+	return &orchestrator, ratelimiter, &parameters, servedVersion, nil
 
 }
 
@@ -0,0 +1,13 @@
+// Package responsecache stores the final responses of the apis for a time to live, either in an in-process
+// LRU cache or in a cache registered with the cache component, and coalesces the concurrent requests
+// missing the cache so that only the first one is served by the orchestrator.
+//
+//	store, _ := responsecache.NewLRUStore(10000)
+//	if entry := store.Get(key); entry != nil {
+//		// serve the cached response
+//	}
+//	call, leader := group.Join(key)
+//	if !leader {
+//		entry, _ := call.Wait(5 * time.Second)
+//	}
+package responsecache
@@ -0,0 +1,65 @@
+package responsecache
+
+import (
+	"sync"
+	"time"
+)
+
+// Group coalesces the concurrent requests for a key, the first request (the leader) being served by the
+// orchestrator while the others wait for its response
+type Group struct {
+	mutex sync.Mutex
+	calls map[string]*Call
+}
+
+// Call is the request being served for a key
+type Call struct {
+	done  chan struct{}
+	once  sync.Once
+	entry *Entry
+}
+
+// NewGroup returns an empty group
+func NewGroup() *Group {
+	return &Group{calls: make(map[string]*Call)}
+}
+
+// Join returns the call in flight for the key, or a new one with leader true if there is none. The
+// leader must complete the call with Done
+func (g *Group) Join(key string) (call *Call, leader bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if c, ok := g.calls[key]; ok {
+		return c, false
+	}
+	c := &Call{done: make(chan struct{})}
+	g.calls[key] = c
+	return c, true
+}
+
+// Done completes the call of the key with the entry, nil if the response can not be shared, waking up
+// the requests waiting for it. Completing a call more than once has no effect
+func (g *Group) Done(key string, call *Call, entry *Entry) {
+	call.once.Do(func() {
+		g.mutex.Lock()
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+		g.mutex.Unlock()
+		call.entry = entry
+		close(call.done)
+	})
+}
+
+// Wait waits up to timeout for the call to complete and returns its entry. Returns false if the call
+// timed out or completed without an entry
+func (c *Call) Wait(timeout time.Duration) (*Entry, bool) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-c.done:
+		return c.entry, c.entry != nil
+	case <-t.C:
+		return nil, false
+	}
+}
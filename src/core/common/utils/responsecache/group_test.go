@@ -0,0 +1,56 @@
+package responsecache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	g := NewGroup()
+	call, leader := g.Join("key")
+	if !leader {
+		t.Fatal("First request should lead")
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan *Entry, 5)
+	for i := 0; i < 5; i++ {
+		c, l := g.Join("key")
+		if l || c != call {
+			t.Fatal("Concurrent requests should join the call in flight")
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry, _ := c.Wait(time.Second)
+			results <- entry
+		}()
+	}
+	entry := &Entry{Status: 200}
+	g.Done("key", call, entry)
+	g.Done("key", call, nil)
+	wg.Wait()
+	close(results)
+	for e := range results {
+		if e != entry {
+			t.Fatalf("Expected the entry of the leader, got %v", e)
+		}
+	}
+
+	if _, leader := g.Join("key"); !leader {
+		t.Fatal("Request after the call completed should lead")
+	}
+}
+
+func TestGroupWait(t *testing.T) {
+	g := NewGroup()
+	call, _ := g.Join("key")
+	if _, ok := call.Wait(10 * time.Millisecond); ok {
+		t.Fatal("Wait should time out")
+	}
+	g.Done("key", call, nil)
+	if _, ok := call.Wait(time.Second); ok {
+		t.Fatal("Call completed without an entry should not be shared")
+	}
+}
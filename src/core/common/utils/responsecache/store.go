@@ -0,0 +1,171 @@
+package responsecache
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/jabong/florest-core/src/components/cache"
+)
+
+// Entry is a cached response
+type Entry struct {
+	Status  int
+	Headers map[string]string `json:",omitempty"`
+	Body    []byte
+}
+
+// Store stores the cached responses by key
+type Store interface {
+	// Get returns the entry stored for the key, nil if not found or expired
+	Get(key string) *Entry
+
+	// Set stores the entry for the key till the ttl expires
+	Set(key string, entry *Entry, ttl time.Duration) error
+
+	// Purge removes the entries whose keys start with the prefix, all of them if the prefix is empty,
+	// and returns the number of entries removed
+	Purge(prefix string) (int, error)
+}
+
+// lruStore stores the entries in an in-process LRU cache
+type lruStore struct {
+	cache *lru.Cache
+}
+
+type lruEntry struct {
+	entry   *Entry
+	expires time.Time
+}
+
+// NewLRUStore returns a store keeping up to size entries in memory, the least recently used ones
+// being evicted first
+func NewLRUStore(size int) (Store, error) {
+	c, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &lruStore{cache: c}, nil
+}
+
+func (s *lruStore) Get(key string) *Entry {
+	v, ok := s.cache.Get(key)
+	if !ok {
+		return nil
+	}
+	e := v.(*lruEntry)
+	if time.Now().After(e.expires) {
+		s.cache.Remove(key)
+		return nil
+	}
+	return e.entry
+}
+
+func (s *lruStore) Set(key string, entry *Entry, ttl time.Duration) error {
+	s.cache.Add(key, &lruEntry{entry: entry, expires: time.Now().Add(ttl)})
+	return nil
+}
+
+func (s *lruStore) Purge(prefix string) (int, error) {
+	n := 0
+	for _, k := range s.cache.Keys() {
+		if key := k.(string); strings.HasPrefix(key, prefix) {
+			s.cache.Remove(key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// minPrune is the number of keys indexed before the expired ones are pruned from the index
+const minPrune = 1024
+
+// cacheStore stores the entries as json in a cache of the cache component. As the cache can not be
+// scanned, the keys set by the store are indexed to be purged by prefix. Hence a purge only removes
+// the entries set by this instance of the app
+type cacheStore struct {
+	cache     cache.CInterface
+	keyPrefix string
+	mutex     sync.Mutex
+	keys      map[string]time.Time
+	nextPrune int
+}
+
+// NewCacheStore returns a store keeping the entries in the cache, the keys prefixed by keyPrefix
+func NewCacheStore(c cache.CInterface, keyPrefix string) Store {
+	return &cacheStore{cache: c, keyPrefix: keyPrefix, keys: make(map[string]time.Time), nextPrune: minPrune}
+}
+
+func (s *cacheStore) Get(key string) *Entry {
+	item, err := s.cache.Get(s.keyPrefix+key, false, false)
+	if err != nil || item == nil {
+		return nil
+	}
+	var b []byte
+	switch v := item.Value.(type) {
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return nil
+	}
+	entry := new(Entry)
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil
+	}
+	return entry
+}
+
+func (s *cacheStore) Set(key string, entry *Entry, ttl time.Duration) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// the cache expires the items in seconds
+	secs := int32(math.Ceil(ttl.Seconds()))
+	if secs < 1 {
+		return errors.New("TTL should be positive")
+	}
+	if err := s.cache.SetWithTimeout(cache.Item{Key: s.keyPrefix + key, Value: string(b)}, false, false,
+		secs); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	s.keys[key] = now.Add(ttl)
+	if len(s.keys) >= s.nextPrune {
+		for k, expires := range s.keys {
+			if now.After(expires) {
+				delete(s.keys, k)
+			}
+		}
+		s.nextPrune = 2*len(s.keys) + minPrune
+	}
+	return nil
+}
+
+func (s *cacheStore) Purge(prefix string) (int, error) {
+	s.mutex.Lock()
+	var keys []string
+	for k := range s.keys {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, s.keyPrefix+k)
+			delete(s.keys, k)
+		}
+	}
+	s.mutex.Unlock()
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	if err := s.cache.DeleteBatch(keys); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
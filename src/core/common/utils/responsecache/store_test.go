@@ -0,0 +1,127 @@
+package responsecache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/components/cache"
+)
+
+// memCache is a cache.CInterface keeping the items in a map
+type memCache struct {
+	items map[string]interface{}
+	ttls  map[string]int32
+}
+
+func newMemCache() *memCache {
+	return &memCache{items: make(map[string]interface{}), ttls: make(map[string]int32)}
+}
+
+func (m *memCache) Init(conf *cache.Config) error { return nil }
+
+func (m *memCache) Get(key string, serialize bool, compress bool) (*cache.Item, error) {
+	v, ok := m.items[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &cache.Item{Key: key, Value: v}, nil
+}
+
+func (m *memCache) Set(item cache.Item, serialize bool, compress bool) error {
+	m.items[item.Key] = item.Value
+	return nil
+}
+
+func (m *memCache) SetWithTimeout(item cache.Item, serialize bool, compress bool, ttl int32) error {
+	m.ttls[item.Key] = ttl
+	return m.Set(item, serialize, compress)
+}
+
+func (m *memCache) Delete(key string) error {
+	delete(m.items, key)
+	return nil
+}
+
+func (m *memCache) DeleteBatch(keys []string) error {
+	for _, k := range keys {
+		delete(m.items, k)
+	}
+	return nil
+}
+
+func (m *memCache) GetBatch(keys []string, serialize bool, compress bool) (map[string]*cache.Item, error) {
+	return nil, errors.New("not supported")
+}
+
+func testStore(t *testing.T, s Store) {
+	entry := &Entry{Status: 200, Headers: map[string]string{"ETag": `"v1"`}, Body: []byte(`{"id":1}`)}
+	if err := s.Set("ORDERS/V1/GET/orchestratorBucketDefaultValue/1", entry, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("ORDERS/V1/GET/orchestratorBucketDefaultValue/2", entry, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("USERS/V1/GET/orchestratorBucketDefaultValue/1", entry, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	got := s.Get("ORDERS/V1/GET/orchestratorBucketDefaultValue/1")
+	if got == nil || got.Status != 200 || string(got.Body) != `{"id":1}` || got.Headers["ETag"] != `"v1"` {
+		t.Fatalf("Invalid entry %+v", got)
+	}
+	if s.Get("ORDERS/V1/GET/orchestratorBucketDefaultValue/3") != nil {
+		t.Fatal("Entry not set should not be found")
+	}
+	if n, err := s.Purge("ORDERS/"); err != nil || n != 2 {
+		t.Fatalf("Expected 2 entries purged, got %d %v", n, err)
+	}
+	if s.Get("ORDERS/V1/GET/orchestratorBucketDefaultValue/2") != nil {
+		t.Fatal("Purged entry should not be found")
+	}
+	if s.Get("USERS/V1/GET/orchestratorBucketDefaultValue/1") == nil {
+		t.Fatal("Entry not matching the prefix should not be purged")
+	}
+	if n, _ := s.Purge(""); n != 1 {
+		t.Fatalf("Expected all the entries purged, got %d", n)
+	}
+}
+
+func TestLRUStore(t *testing.T) {
+	s, err := NewLRUStore(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+
+	s.Set("expired", &Entry{Status: 200}, -time.Second)
+	if s.Get("expired") != nil {
+		t.Fatal("Expired entry should not be found")
+	}
+	small, _ := NewLRUStore(1)
+	small.Set("a", &Entry{Status: 200}, time.Minute)
+	small.Set("b", &Entry{Status: 200}, time.Minute)
+	if small.Get("a") != nil || small.Get("b") == nil {
+		t.Fatal("Least recently used entry should be evicted")
+	}
+	if _, err := NewLRUStore(0); err == nil {
+		t.Fatal("Invalid size should fail")
+	}
+}
+
+func TestCacheStore(t *testing.T) {
+	c := newMemCache()
+	s := NewCacheStore(c, "rc:")
+	testStore(t, s)
+
+	s.Set("key", &Entry{Status: 200}, 1500*time.Millisecond)
+	if _, ok := c.items["rc:key"]; !ok || c.ttls["rc:key"] != 2 {
+		t.Fatalf("Expected the key prefixed and the ttl rounded up to 2 seconds, got %v", c.ttls)
+	}
+	if err := s.Set("none", &Entry{Status: 200}, 0); err == nil {
+		t.Fatal("TTL of 0 should fail")
+	}
+	c.items["rc:invalid"] = "{"
+	if s.Get("invalid") != nil {
+		t.Fatal("Invalid entry should not be found")
+	}
+}
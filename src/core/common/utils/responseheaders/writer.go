@@ -6,6 +6,11 @@ import (
 	"github.com/jabong/florest-core/src/common/constants"
 	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
 	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/encoder"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -19,6 +24,9 @@ const (
 	cacheControl string = "Cache-Control"
 	contentType  string = "Content-Type"
 	maxAge       string = "max-age"
+	deprecation  string = "Deprecation"
+	sunset       string = "Sunset"
+	link         string = "Link"
 )
 
 //IdentifierExecutor joins the result retrieved from multiple nodes
@@ -39,18 +47,109 @@ func (r *Writer) Name() string {
 }
 
 func (r *Writer) Execute(io workflow.WorkFlowData) (workflow.WorkFlowData, error) {
-	rh, _ := io.IOData.Get(constants.ResponseHeadersConfig)
 	res := utilhttp.NewAPIResponse()
-	if rh != nil {
+	res.Headers = GetHeaders(io)
+	io.IOData.Set(constants.APIResponse, res)
+	return io, nil
+}
+
+//GetHeaders returns the response headers of the request. Streamed responses use it to write the headers
+//before the Writer node is reached
+func GetHeaders(io workflow.WorkFlowData) map[string]string {
+	headers := make(map[string]string)
+	if c := getCacheControlHeader(GetResponseHeaderFields(io).CacheControl); c != "" {
+		headers[cacheControl] = c
+	}
+
+	headers[contentType] = getMediaType(io)
+	if a, _ := io.IOData.Get(constants.AssignedBuckets); a != nil {
+		if assigned, ok := a.(map[string]string); ok && len(assigned) > 0 {
+			headers[constants.AssignedBucketsHeader] = getBucketsHeader(assigned)
+		}
+	}
+	if d, _ := io.IOData.Get(constants.Deprecation); d != nil {
+		if dep, ok := d.(*versionmanager.Deprecation); ok && dep != nil {
+			setDeprecationHeaders(headers, dep)
+		}
+	}
+	if rc, _ := io.ExecContext.Get(constants.RequestContext); rc != nil {
+		if reqContext, ok := rc.(utilhttp.RequestContext); ok {
+			setRequestIDHeaders(headers, reqContext)
+		}
+	}
+	return headers
+}
+
+//GetResponseHeaderFields returns the response header config of the api serving the request. If the api does
+//not override it, the config set in the IO data by the nodes is used and then the app config
+func GetResponseHeaderFields(io workflow.WorkFlowData) config.ResponseHeaderFields {
+	if v, _ := io.IOData.Get(constants.APIVersion); v != nil {
+		if apiVersion, ok := v.(*versionmanager.Version); ok && apiVersion != nil && apiVersion.ResponseHeaders != nil {
+			return *apiVersion.ResponseHeaders
+		}
+	}
+	if rh, _ := io.IOData.Get(constants.ResponseHeadersConfig); rh != nil {
 		if resHeaderConf, ok := rh.(config.ResponseHeaderFields); ok {
-			cHeaders := resHeaderConf.CacheControl
-			res.Headers[cacheControl] = getCacheControlHeader(cHeaders)
+			return resHeaderConf
 		}
 	}
+	return config.GlobalAppConfig.ResponseHeaders
+}
 
-	res.Headers[contentType] = "application/json"
-	io.IOData.Set(constants.APIResponse, res)
-	return io, nil
+//setRequestIDHeaders echoes the request and transaction ids, and the trace context with the span of the request
+func setRequestIDHeaders(headers map[string]string, rc utilhttp.RequestContext) {
+	if name := utilhttp.CustomHeaderMap[utilhttp.RequestID]; name != "" && rc.RequestID != "" {
+		headers[name] = rc.RequestID
+	}
+	if name := utilhttp.CustomHeaderMap[utilhttp.TransactionID]; name != "" && rc.TransactionID != "" {
+		headers[name] = rc.TransactionID
+	}
+	if tp, ok := rc.GetTraceParent(); ok {
+		headers[utilhttp.TraceParentHeader] = tp.String()
+	}
+}
+
+//getMediaType returns the media type negotiated for the api. If the request did not reach an api, it is
+//negotiated among all the registered encoders falling back to the default media type
+func getMediaType(io workflow.WorkFlowData) string {
+	if m, _ := io.IOData.Get(constants.ResponseMediaType); m != nil {
+		if v, ok := m.(string); ok && v != "" {
+			return v
+		}
+	}
+	mediaType := encoder.DefaultMediaType
+	if req, err := misc.GetRequestFromIO(io); err == nil {
+		if v, ok := encoder.Negotiate(req.Headers.Accept, nil); ok {
+			mediaType = v
+		}
+	}
+	io.IOData.Set(constants.ResponseMediaType, mediaType)
+	return mediaType
+}
+
+//getBucketsHeader returns the buckets in the bucket header format, e.g. Algo:New,Layout:B
+func getBucketsHeader(buckets map[string]string) string {
+	params := make([]string, 0, len(buckets))
+	for k, v := range buckets {
+		params = append(params, k+constants.KeyValueSeperator+v)
+	}
+	sort.Strings(params)
+	return strings.Join(params, constants.FieldSeperator)
+}
+
+//setDeprecationHeaders sets the Deprecation, Sunset and Link headers for a deprecated version
+func setDeprecationHeaders(headers map[string]string, dep *versionmanager.Deprecation) {
+	if dep.Date.IsZero() {
+		headers[deprecation] = "true"
+	} else {
+		headers[deprecation] = fmt.Sprintf("@%d", dep.Date.Unix())
+	}
+	if !dep.Sunset.IsZero() {
+		headers[sunset] = dep.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if dep.Link != "" {
+		headers[link] = fmt.Sprintf("<%s>; rel=\"successor-version\"", dep.Link)
+	}
 }
 
 func getCacheControlHeader(cHeaders config.CacheControlHeaders) string {
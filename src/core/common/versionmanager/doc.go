@@ -1,2 +1,19 @@
 // Package versionmanager helps in maintaining multiple versions of a REST endpoint
+//
+// The path of a version can contain literal segments and named params :
+//
+//	buckets/{bucketId}        - matches any single segment
+//	items/{id:int}            - matches a segment satisfying a built-in constraint (int, uint, alpha, alnum, uuid)
+//	skus/{sku:[A-Z0-9-]+}     - matches a segment satisfying the regular expression
+//	files/{path*}             - catch-all, matches all the remaining segments
+//
+// While resolving a path, a literal segment takes precedence over a constrained param, which
+// takes precedence over an unconstrained param, which takes precedence over a catch-all param.
+// The next in the order is tried when none of the paths under a segment match the rest of the
+// path, e.g. users/me/orders resolves to users/{id}/orders when users/me/settings is registered.
+// The constrained params whose constraints both match a segment, e.g. {id:int} and {sku:[A-Z0-9-]+}
+// for 12, are tried in the order they are registered. The paths sharing a position can name their
+// params differently, e.g. keys/{keyId}/values and keys/{key}/meta. Registering a path that is
+// ambiguous with an already registered path, i.e. the same but for the names of the params, results
+// in an error.
 package versionmanager
@@ -0,0 +1,134 @@
+package versionmanager
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+Built-in constraints that can be used in a named param, e.g. {id:int}. Any other constraint
+is treated as a regular expression that must match the complete path segment, e.g. {sku:[A-Z0-9-]+}
+*/
+var builtInConstraints = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[a-zA-Z]+`,
+	"alnum": `[a-zA-Z0-9]+`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+const catchAllSuffix = "*"
+
+/*
+Kind of a path segment. The order of declaration is the order of precedence while matching
+*/
+type segmentKind int
+
+const (
+	literalSegment segmentKind = iota
+	constrainedSegment
+	unconstrainedSegment
+	catchAllSegment
+)
+
+/*
+A parsed path segment of a registered path
+*/
+type pathSegment struct {
+	kind       segmentKind
+	value      string
+	constraint string
+	matcher    *regexp.Regexp
+}
+
+/*
+Parses a single path segment which can be a literal, {name}, {name:constraint} or {name*}
+*/
+func parseSegment(segment string) (*pathSegment, error) {
+	if !strings.HasPrefix(segment, "{") {
+		if strings.Contains(segment, "{") || strings.Contains(segment, "}") {
+			return nil, fmt.Errorf("Invalid path segment %s. Named param should be in between { and }", segment)
+		}
+		return &pathSegment{kind: literalSegment, value: segment}, nil
+	}
+	if !strings.HasSuffix(segment, "}") {
+		return nil, errors.New("Invalid named param. Named param should be in between { and }")
+	}
+	inner := segment[1 : len(segment)-1]
+	name := inner
+	constraint := ""
+	if index := strings.Index(inner, ":"); index != -1 {
+		name = inner[:index]
+		constraint = inner[index+1:]
+		if constraint == "" {
+			return nil, fmt.Errorf("Invalid named param %s. Constraint can not be empty", segment)
+		}
+	}
+	if strings.HasSuffix(name, catchAllSuffix) {
+		if constraint != "" {
+			return nil, fmt.Errorf("Invalid named param %s. Catch-all param can not have a constraint", segment)
+		}
+		name = strings.TrimSuffix(name, catchAllSuffix)
+		if name == "" {
+			return nil, fmt.Errorf("Invalid named param %s. Name can not be empty", segment)
+		}
+		return &pathSegment{kind: catchAllSegment, value: name}, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf("Invalid named param %s. Name can not be empty", segment)
+	}
+	if constraint == "" {
+		return &pathSegment{kind: unconstrainedSegment, value: name}, nil
+	}
+
+	pattern, found := builtInConstraints[constraint]
+	if !found {
+		pattern = constraint
+	}
+	matcher, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("Invalid constraint %s in named param %s. Err : %s", constraint, segment, err.Error())
+	}
+	return &pathSegment{kind: constrainedSegment, value: name, constraint: constraint, matcher: matcher}, nil
+}
+
+/*
+Parses a complete registered path into its segments
+*/
+func parsePath(path string) ([]*pathSegment, error) {
+	pathArr := strings.Split(path, "/")
+	segments := make([]*pathSegment, len(pathArr))
+	names := make(map[string]bool)
+	for i, pathParamKey := range pathArr {
+		segment, err := parseSegment(pathParamKey)
+		if err != nil {
+			return nil, err
+		}
+		if segment.kind != literalSegment {
+			if names[segment.value] {
+				return nil, fmt.Errorf("Invalid path %s. Named param %s is used more than once", path, segment.value)
+			}
+			names[segment.value] = true
+		}
+		if segment.kind == catchAllSegment && i != len(pathArr)-1 {
+			return nil, fmt.Errorf("Invalid path %s. Catch-all param {%s*} should be the last segment", path, segment.value)
+		}
+		segments[i] = segment
+	}
+	return segments, nil
+}
+
+/*
+Returns the names of the named params of the segments in order
+*/
+func getNames(segments []*pathSegment) []string {
+	var names []string
+	for _, segment := range segments {
+		if segment.kind != literalSegment {
+			names = append(names, segment.value)
+		}
+	}
+	return names
+}
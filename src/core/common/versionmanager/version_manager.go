@@ -3,6 +3,8 @@ package versionmanager
 import (
 	"errors"
 	"github.com/jabong/florest-core/src/common/ratelimiter"
+	"sort"
+	"strings"
 )
 
 /*
@@ -15,6 +17,9 @@ type versionManager struct {
 
 var vmgr *versionManager
 
+// Versions to fall back to, ordered from the newest to the oldest
+var fallbackChain []string
+
 /*
 Constructor for the version manager
 */
@@ -26,19 +31,74 @@ func Initialize(m VersionMap) {
 	vmgr.mapping = m
 }
 
+/*
+Clears the versions and the fallback chain so that the version manager can be initialised again,
+e.g. between tests
+*/
+func Reset() {
+	vmgr = nil
+	fallbackChain = nil
+}
+
+/*
+Sets the version fallback chain ordered from the newest to the oldest version, e.g. V3, V2, V1.
+A version present in the chain falls back to the older versions if the resource, action and path
+is not registered for it
+*/
+func SetFallbackChain(chain []string) {
+	fallbackChain = make([]string, len(chain))
+	for i, v := range chain {
+		fallbackChain[i] = strings.ToUpper(v)
+	}
+}
+
 /*
 Get the executable for the resource, version, action, bucketId
 */
 func Get(resource string, version string, action string,
 	bucketID string, pathParams string) (Versionable, *ratelimiter.RateLimiter, map[string]string, error) {
+	versionable, ratelimiter, parameters, _, err := Resolve(resource, version, action, bucketID, pathParams)
+	return versionable, ratelimiter, parameters, err
+}
+
+/*
+Resolve the executable for the resource, version, action, bucketId trying the versions of the
+fallback chain in turn. Returns the version which is registered for the request as servedVersion
+*/
+func Resolve(resource string, version string, action string,
+	bucketID string, pathParams string) (versionable Versionable, rl *ratelimiter.RateLimiter,
+	parameters map[string]string, servedVersion string, err error) {
 	if vmgr == nil {
-		return nil, nil, nil, errors.New("Version manager not initialized")
+		return nil, nil, nil, "", errors.New("Version manager not initialized")
 	}
 
 	if vmgr.mapping == nil {
-		return nil, nil, nil, errors.New("No version mapping present")
+		return nil, nil, nil, "", errors.New("No version mapping present")
 	}
 
+	for _, v := range getVersionsToTry(version) {
+		versionable, rl, parameters, err = vmgr.get(resource, v, action, bucketID, pathParams)
+		if err == nil {
+			return versionable, rl, parameters, v, nil
+		}
+	}
+	return nil, nil, nil, "", err
+}
+
+/*
+Returns the version followed by its older versions in the fallback chain
+*/
+func getVersionsToTry(version string) []string {
+	for i, v := range fallbackChain {
+		if v == version {
+			return fallbackChain[i:]
+		}
+	}
+	return []string{version}
+}
+
+func (vm *versionManager) get(resource string, version string, action string,
+	bucketID string, pathParams string) (Versionable, *ratelimiter.RateLimiter, map[string]string, error) {
 	ver := BasicVersion{
 		Resource: resource,
 		Version:  version,
@@ -46,7 +106,7 @@ func Get(resource string, version string, action string,
 		BucketID: bucketID,
 	}
 
-	param, ok := vmgr.mapping[ver]
+	param, ok := vm.mapping[ver]
 	if !ok {
 		return nil, nil, nil, errors.New("Versionable not found in version manager")
 	}
@@ -60,3 +120,93 @@ func Get(resource string, version string, action string,
 
 	return versionable, ratelimiter, parameters, nil
 }
+
+/*
+Get the deprecation details for the resource, version, action, bucketId and path params.
+Returns nil if the version is not deprecated
+*/
+func GetDeprecation(resource string, version string, action string,
+	bucketID string, pathParams string) (*Deprecation, error) {
+	param, err := getParam(resource, version, action, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	return param.GetDeprecation(pathParams)
+}
+
+/*
+Get the version registered for the resource, version, action, bucketId and path params.
+Returns nil if the version is not set
+*/
+func GetVersion(resource string, version string, action string,
+	bucketID string, pathParams string) (*Version, error) {
+	param, err := getParam(resource, version, action, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	return param.GetVersion(pathParams)
+}
+
+/*
+A registered api - the resource, version, action and bucket along with the path
+*/
+type Route struct {
+	BasicVersion
+	Path        string
+	Deprecation *Deprecation `json:",omitempty"`
+	//API is the version the path is registered with, nil if it is not set
+	API         *Version                 `json:",omitempty"`
+	RateLimiter *ratelimiter.RateLimiter `json:"-"`
+}
+
+/*
+Returns the registered apis sorted by the resource, version, action and bucket
+*/
+func GetRoutes() []Route {
+	if vmgr == nil {
+		return nil
+	}
+	keys := make([]BasicVersion, 0, len(vmgr.mapping))
+	for k := range vmgr.mapping {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		if a.Action != b.Action {
+			return a.Action < b.Action
+		}
+		return a.BucketID < b.BucketID
+	})
+	var routes []Route
+	for _, k := range keys {
+		vmgr.mapping[k].walk(func(leaf *Param) {
+			routes = append(routes, Route{BasicVersion: k, Path: leaf.path, Deprecation: leaf.deprecation,
+				API: leaf.version, RateLimiter: leaf.rateLimiter})
+		})
+	}
+	return routes
+}
+
+func getParam(resource string, version string, action string, bucketID string) (*Param, error) {
+	if vmgr == nil {
+		return nil, errors.New("Version manager not initialized")
+	}
+
+	ver := BasicVersion{
+		Resource: resource,
+		Version:  version,
+		Action:   action,
+		BucketID: bucketID,
+	}
+	param, ok := vmgr.mapping[ver]
+	if !ok {
+		return nil, errors.New("Versionable not found in version manager")
+	}
+	return param, nil
+}
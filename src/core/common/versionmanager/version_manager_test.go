@@ -103,9 +103,11 @@ func TestVersionManager(t *testing.T) {
 	getVersionable(resource, version, action, bucketID, "1/buckets/2/keys", targetPmts, t)
 	targetPmts = map[string]string{"bucketId": "buckets", "keyId": "keys"}
 	getVersionable(resource, version, action, bucketID, "buckets/buckets/keys/keys", targetPmts, t)
+	// no path under the literal buckets matches, the named params are tried
+	targetPmts = map[string]string{"bucketId": "buckets", "keyId": "key"}
+	getVersionable(resource, version, action, bucketID, "buckets/key", targetPmts, t)
 
 	// Invalid URLs
-	getVersionableExpectingErrors(resource, version, action, bucketID, "buckets/key", t)
 	getVersionableExpectingErrors(resource, version, action, bucketID, "somepath", t)
 	getVersionableExpectingErrors(resource, version, action, bucketID, "buckets/1/2", t)
 	getVersionableExpectingErrors(resource, version, action, bucketID, "1/buckets/2", t)
@@ -145,3 +147,91 @@ func addTestVersions(version Version, vmap VersionMap, testVersionableImpl Versi
 	}
 	param.Update(version.Path, testVersionableImpl, nil)
 }
+
+/*
+Test version fallback chain
+*/
+func TestVersionFallback(t *testing.T) {
+	oldVmgr := vmgr
+	defer func() {
+		vmgr = oldVmgr
+		SetFallbackChain(nil)
+	}()
+
+	vmap := VersionMap{}
+	addTestVersions(Version{Resource: "ORDER", Version: "V1", Action: "GET", BucketID: "Old", Path: "{orderId}"},
+		vmap, namedVersionableImpl{"V1"})
+	addTestVersions(Version{Resource: "ORDER", Version: "V2", Action: "GET", BucketID: "Old", Path: "{orderId}/items"},
+		vmap, namedVersionableImpl{"V2"})
+	addTestVersions(Version{Resource: "CART", Version: "V3", Action: "GET", BucketID: "Old", Path: ""},
+		vmap, namedVersionableImpl{"V3"})
+	vmgr = &versionManager{mapping: vmap}
+
+	SetFallbackChain([]string{"v3", "v2", "v1"})
+
+	resolveVersion("ORDER", "V3", "1", "V1", t)
+	resolveVersion("ORDER", "V3", "1/items", "V2", t)
+	resolveVersion("ORDER", "V2", "1", "V1", t)
+	resolveVersion("ORDER", "V1", "1", "V1", t)
+	resolveVersion("CART", "V3", "", "V3", t)
+
+	for _, v := range []string{"V2", "V1", "V4"} {
+		if _, _, _, _, err := Resolve("CART", v, "GET", "Old", ""); err == nil {
+			t.Error("Expected error, but got a valid versionable for version - " + v)
+		}
+	}
+	if _, _, _, _, err := Resolve("ORDER", "V1", "GET", "Old", "1/items"); err == nil {
+		t.Error("Expected error, older version should not fall forward to a newer version")
+	}
+}
+
+func resolveVersion(resource string, version string, path string, targetVersion string, t *testing.T) {
+	versionable, _, _, servedVersion, err := Resolve(resource, version, "GET", "Old", path)
+	if err != nil {
+		t.Errorf("Failed to resolve %s %s %s", resource, version, path)
+		return
+	}
+	if servedVersion != targetVersion {
+		t.Errorf("Served version mismatch for %s %s %s, got %s", resource, version, path, servedVersion)
+	}
+	if v, ok := versionable.(namedVersionableImpl); !ok || v.name != targetVersion {
+		t.Errorf("Returned versionable instance mismatch for %s %s %s", resource, version, path)
+	}
+}
+
+/*
+Test listing of the registered routes
+*/
+func TestGetRoutes(t *testing.T) {
+	oldVmgr := vmgr
+	defer func() {
+		vmgr = oldVmgr
+	}()
+
+	vmap := VersionMap{}
+	addTestVersions(Version{Resource: "ORDER", Version: "V1", Action: "GET", BucketID: "Old", Path: "{id:int}/items"},
+		vmap, namedVersionableImpl{"V1"})
+	addTestVersions(Version{Resource: "ORDER", Version: "V1", Action: "GET", BucketID: "Old", Path: "{id}"},
+		vmap, namedVersionableImpl{"V1"})
+	addTestVersions(Version{Resource: "ORDER", Version: "V1", Action: "GET", BucketID: "Old", Path: "latest"},
+		vmap, namedVersionableImpl{"V1"})
+	addTestVersions(Version{Resource: "CART", Version: "V1", Action: "POST", BucketID: "Old", Path: ""},
+		vmap, namedVersionableImpl{"V1"})
+	deprecation := &Deprecation{Link: "/v2/cart"}
+	vmap[BasicVersion{"CART", "V1", "POST", "Old"}].SetDeprecation("", deprecation)
+	vmgr = &versionManager{mapping: vmap}
+
+	routes := GetRoutes()
+	expected := []string{"CART V1 POST ", "ORDER V1 GET latest", "ORDER V1 GET {id:int}/items", "ORDER V1 GET {id}"}
+	if len(routes) != len(expected) {
+		t.Fatalf("Expected %d routes, got %+v", len(expected), routes)
+	}
+	for i, r := range routes {
+		if got := r.Resource + " " + r.Version + " " + r.Action + " " + r.Path; got != expected[i] {
+			t.Errorf("Expected route %s, got %s", expected[i], got)
+		}
+	}
+	if routes[0].Deprecation != deprecation || routes[1].Deprecation != nil {
+		t.Error("Route deprecation mismatch")
+	}
+}
@@ -2,8 +2,15 @@ package versionmanager
 
 import (
 	"errors"
+	"fmt"
+	"github.com/jabong/florest-core/src/common/config"
 	"github.com/jabong/florest-core/src/common/ratelimiter"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	"github.com/jabong/florest-core/src/common/utils/websocket"
+	"github.com/jabong/florest-core/src/core/common/utils/auth"
+	"sort"
 	"strings"
+	"time"
 )
 
 /*
@@ -17,11 +24,92 @@ type BasicVersion struct {
 }
 
 type Version struct {
-	Resource string
-	Version  string
-	Action   string
-	BucketID string
-	Path     string
+	Resource    string
+	Version     string
+	Action      string
+	BucketID    string
+	Path        string
+	Deprecation *Deprecation
+	//MediaTypes restricts the media types the API responds with, all registered encoders are allowed when empty
+	MediaTypes []string
+	//ErrorFormat overrides the app level format of the error responses, Envelope or Problem
+	ErrorFormat string
+	//Stream streams the response of the API as JSONLines or SSE (server sent events), not streamed when empty
+	Stream string
+	//Upload parses the multipart uploads of the API, the limits not specified are taken from the app config
+	Upload *utilhttp.UploadConfig
+	//WebSocket serves the API over a WebSocket, running the orchestrator for each message received
+	WebSocket *websocket.Config
+	//ResponseHeaders overrides the app level cache control and ETag config of the responses of the API
+	ResponseHeaders *config.ResponseHeaderFields
+	//ResponseCache caches the successful responses of a GET API, not cached when nil
+	ResponseCache *ResponseCache
+	//Idempotency replays the response of the first POST or PATCH request with an Idempotency-Key to its retries
+	Idempotency *Idempotency
+	//Authenticators authenticate the callers of the API with the first one whose credentials the request carries,
+	//the API is public when empty
+	Authenticators []auth.Authenticator `json:"-"`
+	//Authorization is the scopes, the roles or the policy the authenticated caller should have, not authorized when nil
+	Authorization *auth.Rule
+	//ETagLookup gives the current ETag of the resource for the If-Match of the PUT, PATCH and DELETE requests of the
+	//API, the GET API of the resource being run for it when nil
+	ETagLookup ETagLookup `json:"-"`
+	//ShadowWrites mirrors the POST, PUT, PATCH and DELETE requests of the API to the candidate bucket of the shadow
+	//traffic config, only its GET requests are mirrored otherwise. The candidate nodes then run for real and must
+	//skip their side effects when constants.ShadowRequest is set in the execution context
+	ShadowWrites bool
+}
+
+/*
+Gives the current ETag of the resource of a request and whether the resource exists. The ETag is quoted
+unless it is already a quoted or a weak tag, no precondition is evaluated when it is empty
+*/
+type ETagLookup func(req *utilhttp.Request) (etag string, exists bool, err error)
+
+/*
+Caching of the responses of an API. The responses are keyed by the resource, version, action, bucket
+and path params, along with the query params and the headers listed, and the authenticated client app
+and user for the APIs having authenticators
+*/
+type ResponseCache struct {
+	//TTL is how long a response is served from the cache
+	TTL time.Duration
+	//QueryParams and Headers are the query params and the headers the response varies with
+	QueryParams []string
+	Headers     []string
+}
+
+/*
+Idempotency of an API. The retries of a request are identified by the Idempotency-Key header, along with
+the resource, version, action, path params and the caller - the authenticated client app and user, else the
+user id of the request
+*/
+type Idempotency struct {
+	//TTL is how long the response is replayed, 24 hours if not specified
+	TTL time.Duration
+	//Required rejects the requests without an Idempotency-Key
+	Required bool
+}
+
+/*
+Deprecation details of a version
+*/
+type Deprecation struct {
+	//Date since when the version is deprecated
+	Date time.Time
+	//Sunset is the date after which the version will not be available
+	Sunset time.Time
+	//Link to the replacement of the version
+	Link string
+	//RejectAfterSunset rejects the calls after the sunset date
+	RejectAfterSunset bool
+}
+
+/*
+Checks if the sunset date has passed
+*/
+func (d *Deprecation) IsSunset(now time.Time) bool {
+	return !d.Sunset.IsZero() && now.After(d.Sunset)
 }
 
 func (v *Version) GetBasicVersion() BasicVersion {
@@ -33,92 +121,279 @@ func (v *Version) GetBasicVersion() BasicVersion {
 	}
 }
 
+/*
+Node of the path tree. Literal children are looked up by the path segment, named children
+are kept in the order of precedence - constrained, unconstrained and then catch-all. The named
+params of the paths sharing a node can have different names, the names being kept per path
+*/
 type Param struct {
 	versionable Versionable
 	pathParams  map[string]*Param
-	namedParams map[string]*Param
+	namedParams []*namedParam
 	rateLimiter *ratelimiter.RateLimiter
+	deprecation *Deprecation
+	version     *Version
+	//path registered at the node and the names of its named params in order
+	path  string
+	names []string
+}
+
+/*
+A named child of a path tree node, shared by the named params of the same kind and constraint
+*/
+type namedParam struct {
+	*pathSegment
+	param *Param
 }
 
 func NewParam() *Param {
 	p := Param{
-		pathParams:  make(map[string]*Param),
-		namedParams: make(map[string]*Param),
+		pathParams: make(map[string]*Param),
 	}
 
 	return &p
 }
 
+/*
+Registers the versionable for the path. Path segments can either be literals or named params
+in the form {name}, {name:constraint} or {name*}. Returns an error if the path is invalid or if it
+is ambiguous with an already registered path, i.e. the same but for the names of the named params.
+The constrained params whose constraints overlap can not be told apart at registration, the ones
+matching the same segment being tried in the order of registration
+*/
 func (param *Param) Update(path string, versionable Versionable,
 	rl *ratelimiter.RateLimiter) error {
-	if path == "" {
-		param.versionable = versionable
-		param.rateLimiter = rl
-		return nil
-	}
-	pathArr := strings.Split(path, "/")
-	for _, pathParamKey := range pathArr {
-		if strings.Index(pathParamKey, "{") == 0 {
-			lastIndex := len(pathParamKey) - 1
-			if strings.Index(pathParamKey, "}") != lastIndex {
-				return errors.New("Invalid named param. Named param should be in between { and }")
-			}
-			namedParamKey := pathParamKey[1:lastIndex]
-			namedParam := param.namedParams[namedParamKey]
-			if namedParam == nil {
-				namedParam = NewParam()
-				param.namedParams[namedParamKey] = namedParam
-			}
-			param = namedParam
-		} else {
-			pathParam := param.pathParams[pathParamKey]
-			if pathParam == nil {
-				pathParam = NewParam()
-				param.pathParams[pathParamKey] = pathParam
-			}
-			param = pathParam
+	var segments []*pathSegment
+	if path != "" {
+		var err error
+		if segments, err = parsePath(path); err != nil {
+			return err
 		}
 	}
+
+	//Validate against the registered paths before modifying the tree
+	if existing := param.getNode(segments); existing != nil && existing.versionable != nil {
+		return fmt.Errorf("Path %s is ambiguous with the already registered path %s", path, existing.path)
+	}
+
+	for _, segment := range segments {
+		param = param.addChild(segment)
+	}
 	param.versionable = versionable
 	param.rateLimiter = rl
+	param.path = path
+	param.names = getNames(segments)
+	return nil
+}
+
+/*
+Marks the already registered path as deprecated
+*/
+func (param *Param) SetDeprecation(path string, deprecation *Deprecation) error {
+	leaf, err := param.getRegisteredLeaf(path)
+	if err != nil {
+		return err
+	}
+	leaf.deprecation = deprecation
+	return nil
+}
+
+/*
+Sets the version the already registered path is registered with
+*/
+func (param *Param) SetVersion(path string, version *Version) error {
+	leaf, err := param.getRegisteredLeaf(path)
+	if err != nil {
+		return err
+	}
+	leaf.version = version
+	return nil
+}
+
+/*
+Returns the node of the registered path
+*/
+func (param *Param) getRegisteredLeaf(path string) (*Param, error) {
+	var segments []*pathSegment
+	if path != "" {
+		var err error
+		if segments, err = parsePath(path); err != nil {
+			return nil, err
+		}
+	}
+	leaf := param.getNode(segments)
+	if leaf == nil || leaf.versionable == nil || leaf.path != path {
+		return nil, fmt.Errorf("Path %s is not registered", path)
+	}
+	return leaf, nil
+}
+
+/*
+Returns the node of the segments if present
+*/
+func (param *Param) getNode(segments []*pathSegment) *Param {
+	for _, segment := range segments {
+		if param = param.getChild(segment); param == nil {
+			return nil
+		}
+	}
+	return param
+}
+
+/*
+Returns the child for the segment if present, the named params of the same kind and constraint
+sharing the child whatever their names
+*/
+func (param *Param) getChild(segment *pathSegment) *Param {
+	if segment.kind == literalSegment {
+		return param.pathParams[segment.value]
+	}
+	for _, named := range param.namedParams {
+		if named.kind == segment.kind && named.constraint == segment.constraint {
+			return named.param
+		}
+	}
 	return nil
 }
 
+/*
+Returns the child for the segment, creating it if not present
+*/
+func (param *Param) addChild(segment *pathSegment) *Param {
+	if child := param.getChild(segment); child != nil {
+		return child
+	}
+	child := NewParam()
+	if segment.kind == literalSegment {
+		param.pathParams[segment.value] = child
+		return child
+	}
+
+	//Insert after the named params of the same or higher precedence
+	index := len(param.namedParams)
+	for i, named := range param.namedParams {
+		if named.kind > segment.kind {
+			index = i
+			break
+		}
+	}
+	param.namedParams = append(param.namedParams, nil)
+	copy(param.namedParams[index+1:], param.namedParams[index:])
+	param.namedParams[index] = &namedParam{pathSegment: segment, param: child}
+	return child
+}
+
 func (param *Param) GetVersionable(pathParams string,
 	parameters *map[string]string) (Versionable, *ratelimiter.RateLimiter, error) {
+	leaf, err := param.getLeaf(pathParams, parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+	return leaf.versionable, leaf.rateLimiter, nil
+}
+
+/*
+Returns the deprecation details of the path, nil if the path is not deprecated
+*/
+func (param *Param) GetDeprecation(pathParams string) (*Deprecation, error) {
+	parameters := make(map[string]string)
+	leaf, err := param.getLeaf(pathParams, &parameters)
+	if err != nil {
+		return nil, err
+	}
+	return leaf.deprecation, nil
+}
+
+/*
+Returns the version the path is registered with, nil if it is not set
+*/
+func (param *Param) GetVersion(pathParams string) (*Version, error) {
+	parameters := make(map[string]string)
+	leaf, err := param.getLeaf(pathParams, &parameters)
+	if err != nil {
+		return nil, err
+	}
+	return leaf.version, nil
+}
+
+func (param *Param) getLeaf(pathParams string, parameters *map[string]string) (*Param, error) {
 	if pathParams == "" {
 		if param.versionable == nil {
-			return nil, nil, errors.New("Versionable not found in version manager")
+			return nil, errors.New("Versionable not found in version manager")
 		}
-		return param.versionable, param.rateLimiter, nil
+		return param, nil
 	}
 	pathParamsArr := strings.Split(pathParams, "/")
-	return param.getVersionableObj(pathParamsArr, parameters)
+	leaf, values, err := param.getVersionableObj(pathParamsArr, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range leaf.names {
+		(*parameters)[name] = values[i]
+	}
+	return leaf, nil
 }
 
-func (param *Param) getVersionableObj(pathParams []string,
-	parameters *map[string]string) (Versionable, *ratelimiter.RateLimiter, error) {
-	tempParams := pathParams
+/*
+Matches the path params against the tree. A literal match takes precedence, the named params being
+tried in the order of precedence when there is no literal child for the segment or no registered path
+under it matches the remaining segments
+*/
+func (param *Param) getVersionableObj(pathParams []string, values []string) (*Param, []string, error) {
 	notFoundError := errors.New("Versionable not found in version manager")
-	for _, pathParam := range pathParams {
-		tempParams = tempParams[1:]
-		if param.pathParams[pathParam] != nil {
-			param = param.pathParams[pathParam]
-		} else {
-			for key, namedParam := range param.namedParams {
-				versionable, rl, err := namedParam.getVersionableObj(tempParams, parameters)
-				if err == nil {
-					(*parameters)[key] = pathParam
-					return versionable, rl, nil
-				}
-			}
+	if len(pathParams) == 0 {
+		if param.versionable == nil {
 			return nil, nil, notFoundError
 		}
+		return param, values, nil
+	}
+
+	pathParam := pathParams[0]
+	if literal := param.pathParams[pathParam]; literal != nil {
+		if leaf, leafValues, err := literal.getVersionableObj(pathParams[1:], values); err == nil {
+			return leaf, leafValues, nil
+		}
+	}
+
+	for _, named := range param.namedParams {
+		switch named.kind {
+		case catchAllSegment:
+			if named.param.versionable == nil {
+				continue
+			}
+			return named.param, append(values, strings.Join(pathParams, "/")), nil
+		case constrainedSegment:
+			if !named.matcher.MatchString(pathParam) {
+				continue
+			}
+		}
+		leaf, leafValues, err := named.param.getVersionableObj(pathParams[1:], append(values, pathParam))
+		if err == nil {
+			return leaf, leafValues, nil
+		}
+	}
+	return nil, nil, notFoundError
+}
+
+/*
+Calls fn for each registered node of the tree, in the order of the literal segments and then the
+named params in the order of precedence
+*/
+func (param *Param) walk(fn func(leaf *Param)) {
+	if param.versionable != nil {
+		fn(param)
+	}
+	literals := make([]string, 0, len(param.pathParams))
+	for literal := range param.pathParams {
+		literals = append(literals, literal)
+	}
+	sort.Strings(literals)
+	for _, literal := range literals {
+		param.pathParams[literal].walk(fn)
 	}
-	if param.versionable == nil {
-		return nil, nil, notFoundError
+	for _, named := range param.namedParams {
+		named.param.walk(fn)
 	}
-	return param.versionable, param.rateLimiter, nil
 }
 
 type VersionMap map[BasicVersion]*Param
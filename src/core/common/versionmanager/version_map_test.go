@@ -0,0 +1,268 @@
+package versionmanager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+/*
+Test Versionable implementation identified by its name
+*/
+type namedVersionableImpl struct {
+	name string
+}
+
+func (o namedVersionableImpl) GetInstance() interface{} {
+	return o
+}
+
+/*
+Test precedence of literal, constrained, unconstrained and catch-all params
+*/
+func TestParamPrecedence(t *testing.T) {
+	param := NewParam()
+	paths := []string{
+		"items/{name}",
+		"items/{id:int}",
+		"items/latest",
+		"items/{sku:[A-Z0-9-]+}",
+		"items/{id:int}/reviews",
+		"files/{path*}",
+		"files/{name}/meta",
+		"orders/{orderId:uuid}",
+	}
+	for _, path := range paths {
+		if err := param.Update(path, namedVersionableImpl{path}, nil); err != nil {
+			t.Fatalf("Failed to register path %s. Err : %s", path, err)
+		}
+	}
+
+	matchParam(param, "items/latest", "items/latest", map[string]string{}, t)
+	matchParam(param, "items/42", "items/{id:int}", map[string]string{"id": "42"}, t)
+	matchParam(param, "items/-42", "items/{id:int}", map[string]string{"id": "-42"}, t)
+	matchParam(param, "items/AB-12", "items/{sku:[A-Z0-9-]+}", map[string]string{"sku": "AB-12"}, t)
+	matchParam(param, "items/shirt", "items/{name}", map[string]string{"name": "shirt"}, t)
+	matchParam(param, "items/42/reviews", "items/{id:int}/reviews", map[string]string{"id": "42"}, t)
+	matchParam(param, "files/a/b/c.txt", "files/{path*}", map[string]string{"path": "a/b/c.txt"}, t)
+	matchParam(param, "files/a/meta", "files/{name}/meta", map[string]string{"name": "a"}, t)
+	matchParam(param, "files/a", "files/{path*}", map[string]string{"path": "a"}, t)
+	matchParam(param, "orders/0d5c2a4e-7b1f-4c1a-9a53-3f2f6d1e8b90", "orders/{orderId:uuid}",
+		map[string]string{"orderId": "0d5c2a4e-7b1f-4c1a-9a53-3f2f6d1e8b90"}, t)
+
+	for _, path := range []string{"items/shirt/reviews", "items", "files", "orders/42", "orders/abc"} {
+		parameters := make(map[string]string)
+		if _, _, err := param.GetVersionable(path, &parameters); err == nil {
+			t.Error("Expected error, but got a valid versionable for this path - " + path)
+		}
+	}
+}
+
+/*
+Test invalid and ambiguous registrations are rejected without modifying the tree
+*/
+func TestParamRejectedRegistrations(t *testing.T) {
+	param := NewParam()
+	for _, path := range []string{"items/{id:int}", "items/{name}", "files/{path*}", "keys/{keyId}/values"} {
+		if err := param.Update(path, namedVersionableImpl{path}, nil); err != nil {
+			t.Fatalf("Failed to register path %s. Err : %s", path, err)
+		}
+	}
+
+	rejected := []string{
+		"items/{id:int}",
+		"items/{itemId:int}",
+		"items/{sku}",
+		"files/{rest*}",
+		"files/{path*}/meta",
+		"keys/{key}/values",
+		"items/{id:[0-9}",
+		"items/{id",
+		"items/id}",
+		"items/{:int}",
+		"items/{id:}",
+		"items/{path*:int}",
+		"items/{id}/{id}",
+	}
+	for _, path := range rejected {
+		if err := param.Update(path, namedVersionableImpl{path}, nil); err == nil {
+			t.Error("Expected error, but path got registered - " + path)
+		}
+	}
+
+	matchParam(param, "items/42", "items/{id:int}", map[string]string{"id": "42"}, t)
+	matchParam(param, "keys/1/values", "keys/{keyId}/values", map[string]string{"keyId": "1"}, t)
+	parameters := make(map[string]string)
+	if _, _, err := param.GetVersionable("items/42/reviews", &parameters); err == nil {
+		t.Error("Rejected registration modified the path tree")
+	}
+}
+
+/*
+Test the paths sharing a position keep the names of their named params, and the constrained params
+matching the same segment are tried in the order of registration
+*/
+func TestParamNamesPerPath(t *testing.T) {
+	param := NewParam()
+	paths := []string{
+		"keys/{keyId}/values",
+		"keys/{key}/other",
+		"keys/{k}",
+		"items/{id:int}",
+		"items/{sku:[A-Z0-9-]+}",
+		"items/{itemId:int}/reviews/{reviewId}",
+	}
+	for _, path := range paths {
+		if err := param.Update(path, namedVersionableImpl{path}, nil); err != nil {
+			t.Fatalf("Failed to register path %s. Err : %s", path, err)
+		}
+	}
+
+	matchParam(param, "keys/1/values", "keys/{keyId}/values", map[string]string{"keyId": "1"}, t)
+	matchParam(param, "keys/1/other", "keys/{key}/other", map[string]string{"key": "1"}, t)
+	matchParam(param, "keys/1", "keys/{k}", map[string]string{"k": "1"}, t)
+	matchParam(param, "items/12", "items/{id:int}", map[string]string{"id": "12"}, t)
+	matchParam(param, "items/AB-12", "items/{sku:[A-Z0-9-]+}", map[string]string{"sku": "AB-12"}, t)
+	matchParam(param, "items/12/reviews/r1", "items/{itemId:int}/reviews/{reviewId}",
+		map[string]string{"itemId": "12", "reviewId": "r1"}, t)
+
+	if err := param.Update("keys/{other}/values", namedVersionableImpl{"keys"}, nil); err == nil {
+		t.Error("Expected error, but a path differing by the param name got registered")
+	}
+	if err := param.SetVersion("keys/{key}/values", &Version{}); err == nil {
+		t.Error("Expected error while setting the version of a path registered with other param names")
+	}
+	if err := param.SetVersion("keys/{key}/other", &Version{}); err != nil {
+		t.Errorf("Failed to set the version of a registered path. Err : %s", err)
+	}
+}
+
+/*
+Test the named params are tried when no path under the literal segment matches the rest of the path
+*/
+func TestParamBacktracking(t *testing.T) {
+	param := NewParam()
+	paths := []string{
+		"users/me/settings",
+		"users/{id}/orders",
+		"users/{id:int}/cart",
+		"files/docs/{name}/meta",
+		"files/{path*}",
+	}
+	for _, path := range paths {
+		if err := param.Update(path, namedVersionableImpl{path}, nil); err != nil {
+			t.Fatalf("Failed to register path %s. Err : %s", path, err)
+		}
+	}
+
+	matchParam(param, "users/me/settings", "users/me/settings", map[string]string{}, t)
+	matchParam(param, "users/me/orders", "users/{id}/orders", map[string]string{"id": "me"}, t)
+	matchParam(param, "users/42/orders", "users/{id}/orders", map[string]string{"id": "42"}, t)
+	matchParam(param, "users/42/cart", "users/{id:int}/cart", map[string]string{"id": "42"}, t)
+	matchParam(param, "files/docs/a/meta", "files/docs/{name}/meta", map[string]string{"name": "a"}, t)
+	matchParam(param, "files/docs/a/b", "files/{path*}", map[string]string{"path": "docs/a/b"}, t)
+
+	for _, path := range []string{"users/me", "users/me/cart", "users/42/settings"} {
+		parameters := make(map[string]string)
+		if _, _, err := param.GetVersionable(path, &parameters); err == nil {
+			t.Error("Expected error, but got a valid versionable for this path - " + path)
+		}
+	}
+}
+
+/*
+Test the overlapping constraints take the segments they both match in the order of registration, the
+next one being tried when no path under the first one matches
+*/
+func TestParamOverlappingConstraints(t *testing.T) {
+	param := NewParam()
+	paths := []string{
+		"items/{sku:[A-Z0-9-]+}",
+		"items/{id:int}",
+		"items/{id:int}/reviews",
+	}
+	for _, path := range paths {
+		if err := param.Update(path, namedVersionableImpl{path}, nil); err != nil {
+			t.Fatalf("Failed to register path %s. Err : %s", path, err)
+		}
+	}
+
+	matchParam(param, "items/12", "items/{sku:[A-Z0-9-]+}", map[string]string{"sku": "12"}, t)
+	matchParam(param, "items/AB-12", "items/{sku:[A-Z0-9-]+}", map[string]string{"sku": "AB-12"}, t)
+	matchParam(param, "items/12/reviews", "items/{id:int}/reviews", map[string]string{"id": "12"}, t)
+}
+
+/*
+Test deprecation of registered paths
+*/
+func TestParamDeprecation(t *testing.T) {
+	param := NewParam()
+	for _, path := range []string{"", "items/{id:int}", "items/{name}"} {
+		if err := param.Update(path, namedVersionableImpl{path}, nil); err != nil {
+			t.Fatalf("Failed to register path %s. Err : %s", path, err)
+		}
+	}
+	sunset := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	dep := &Deprecation{Sunset: sunset, Link: "/v2/items", RejectAfterSunset: true}
+	if err := param.SetDeprecation("items/{id:int}", dep); err != nil {
+		t.Fatalf("Failed to deprecate path. Err : %s", err)
+	}
+	if err := param.SetDeprecation("items/{id:int}/reviews", dep); err == nil {
+		t.Error("Expected error while deprecating a path which is not registered")
+	}
+
+	if d, err := param.GetDeprecation("items/42"); err != nil || d != dep {
+		t.Errorf("Deprecation mismatch for items/42, got %v %v", d, err)
+	}
+	if d, err := param.GetDeprecation("items/shirt"); err != nil || d != nil {
+		t.Errorf("Expected no deprecation for items/shirt, got %v %v", d, err)
+	}
+	if d, err := param.GetDeprecation(""); err != nil || d != nil {
+		t.Errorf("Expected no deprecation for root, got %v %v", d, err)
+	}
+
+	if !dep.IsSunset(sunset.Add(time.Second)) || dep.IsSunset(sunset.Add(-time.Second)) {
+		t.Error("Sunset check mismatch")
+	}
+	if new(Deprecation).IsSunset(time.Now()) {
+		t.Error("Deprecation without a sunset date should never be sunset")
+	}
+}
+
+/*
+Test the registered version of a path
+*/
+func TestParamVersion(t *testing.T) {
+	param := NewParam()
+	if err := param.Update("items/{id:int}", namedVersionableImpl{"items"}, nil); err != nil {
+		t.Fatalf("Failed to register path. Err : %s", err)
+	}
+	version := &Version{Resource: "ITEMS", Version: "V1", Path: "items/{id:int}", MediaTypes: []string{"application/xml"}}
+	if err := param.SetVersion("items/{id}", version); err == nil {
+		t.Error("Expected error while setting the version of a path which is not registered")
+	}
+	if v, err := param.GetVersion("items/42"); err != nil || v != nil {
+		t.Errorf("Expected no version before it is set, got %v %v", v, err)
+	}
+	if err := param.SetVersion("items/{id:int}", version); err != nil {
+		t.Fatalf("Failed to set version. Err : %s", err)
+	}
+	if v, err := param.GetVersion("items/42"); err != nil || v != version {
+		t.Errorf("Version mismatch for items/42, got %v %v", v, err)
+	}
+}
+
+func matchParam(param *Param, path string, targetPath string, targetPmts map[string]string, t *testing.T) {
+	parameters := make(map[string]string)
+	versionable, _, err := param.GetVersionable(path, &parameters)
+	if err != nil {
+		t.Error("Failed to get versionable for this path - " + path)
+		return
+	}
+	if v, ok := versionable.(namedVersionableImpl); !ok || v.name != targetPath {
+		t.Errorf("Returned versionable instance mismatch for this path - %s, got %v", path, versionable)
+	}
+	if !reflect.DeepEqual(parameters, targetPmts) {
+		t.Errorf("Returned parameters mismatch for this path - %s, got %v", path, parameters)
+	}
+}
@@ -6,4 +6,8 @@ type Config struct {
 
 	// TaskQueueSize specifies the size of the task queue.
 	TaskQueueSize int
+
+	// Name registers the worker pool by the name so that its queue depth can be inspected with GetStats.
+	// The worker pool is not registered if the name is empty
+	Name string
 }
@@ -3,6 +3,7 @@ package workerpool
 import (
 	"fmt"
 	"strconv"
+	"sync"
 
 	"github.com/jabong/florest-core/src/common/logger"
 )
@@ -15,8 +16,29 @@ type WPExecutor struct {
 	taskQueueSize int
 	taskQueue     chan Task
 	workerPool    WPType
+	name          string
+	workers       []Worker
+	// dispatched is closed once the tasks of the task queue are dispatched after it is closed by Stop
+	dispatched chan struct{}
+	stopOnce   sync.Once
 }
 
+// Stats is the state of a worker pool at an instant
+type Stats struct {
+	// Workers is the number of workers and IdleWorkers the number of those waiting for a task
+	Workers     int
+	IdleWorkers int
+	// QueuedTasks is the number of tasks waiting in the task queue of size TaskQueueSize
+	QueuedTasks   int
+	TaskQueueSize int
+}
+
+// registry holds the named worker pools
+var registry = struct {
+	sync.RWMutex
+	executors map[string]*WPExecutor
+}{executors: make(map[string]*WPExecutor)}
+
 // Creates a new worker pool executor and initializes it.
 func NewWPExecutor(conf Config) (workerPoolExecutor *WPExecutor, errObj error) {
 	defer func() {
@@ -35,15 +57,19 @@ func NewWPExecutor(conf Config) (workerPoolExecutor *WPExecutor, errObj error) {
 	workerPoolExecutor.workerPool = make(WPType, conf.NWorkers)
 
 	workerPoolExecutor.taskQueue = make(chan Task, conf.TaskQueueSize)
+	workerPoolExecutor.name = conf.Name
+	workerPoolExecutor.dispatched = make(chan struct{})
 
 	// Now, create all the workers.
 	for i := 0; i < conf.NWorkers; i++ {
 		logger.Debug("Starting worker "+strconv.Itoa(i+1), false)
 		worker := NewWorker(i+1, workerPoolExecutor.workerPool)
 		worker.Start()
+		workerPoolExecutor.workers = append(workerPoolExecutor.workers, worker)
 	}
 
 	go func() {
+		defer close(workerPoolExecutor.dispatched)
 		defer func() {
 			// do recovery if error
 			if r := recover(); r != nil {
@@ -60,9 +86,54 @@ func NewWPExecutor(conf Config) (workerPoolExecutor *WPExecutor, errObj error) {
 		}
 	}()
 
+	if conf.Name != "" {
+		registry.Lock()
+		registry.executors[conf.Name] = workerPoolExecutor
+		registry.Unlock()
+	}
 	return workerPoolExecutor, nil
 }
 
+// Stop stops the worker pool once the queued tasks are executed, unregistering it if registered with a
+// name. No task should be dispatched to the worker pool once it is stopped
+func (workerPoolExecutor *WPExecutor) Stop() {
+	workerPoolExecutor.stopOnce.Do(func() {
+		close(workerPoolExecutor.taskQueue)
+		<-workerPoolExecutor.dispatched
+		for _, worker := range workerPoolExecutor.workers {
+			worker.Stop()
+		}
+		if workerPoolExecutor.name != "" {
+			registry.Lock()
+			if registry.executors[workerPoolExecutor.name] == workerPoolExecutor {
+				delete(registry.executors, workerPoolExecutor.name)
+			}
+			registry.Unlock()
+		}
+	})
+}
+
+// Stats returns the state of the worker pool
+func (workerPoolExecutor *WPExecutor) Stats() Stats {
+	return Stats{
+		Workers:       workerPoolExecutor.nWorkers,
+		IdleWorkers:   len(workerPoolExecutor.workerPool),
+		QueuedTasks:   len(workerPoolExecutor.taskQueue),
+		TaskQueueSize: workerPoolExecutor.taskQueueSize,
+	}
+}
+
+// GetStats returns the state of the worker pools registered with a name, indexed by the name
+func GetStats() map[string]Stats {
+	registry.RLock()
+	defer registry.RUnlock()
+	res := make(map[string]Stats, len(registry.executors))
+	for name, e := range registry.executors {
+		res[name] = e.Stats()
+	}
+	return res
+}
+
 // Dispatches the task to an available worker to execute it
 func (workerPoolExecutor *WPExecutor) ExecuteTask(t Task) {
 	if workerPoolExecutor.taskQueue == nil {
@@ -70,3 +141,17 @@ func (workerPoolExecutor *WPExecutor) ExecuteTask(t Task) {
 	}
 	workerPoolExecutor.taskQueue <- t
 }
+
+// Dispatches the task to an available worker without blocking the caller. Returns false if the
+// task queue is full and the task is dropped
+func (workerPoolExecutor *WPExecutor) TryExecuteTask(t Task) bool {
+	if workerPoolExecutor.taskQueue == nil {
+		panic(fmt.Sprintf("WPExecutor is not initalized. Use workerPool.NewWPExecutor method to create & initialize workerPoolExecutor"))
+	}
+	select {
+	case workerPoolExecutor.taskQueue <- t:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,39 @@
+package workerpool
+
+import (
+	"sync"
+	"testing"
+)
+
+type counter struct {
+	sync.Mutex
+	n int
+}
+
+func (c *counter) Increment() {
+	c.Lock()
+	c.n++
+	c.Unlock()
+}
+
+func TestWPExecutorStop(t *testing.T) {
+	wp, err := NewWPExecutor(Config{NWorkers: 2, TaskQueueSize: 5, Name: "stoppable"})
+	if err != nil {
+		t.Fatalf("Failed to create the worker pool. Err : %s", err)
+	}
+	if _, found := GetStats()["stoppable"]; !found {
+		t.Fatal("Worker pool not registered")
+	}
+	c := new(counter)
+	for i := 0; i < 5; i++ {
+		wp.ExecuteTask(Task{Instance: c, MethodName: "Increment"})
+	}
+	wp.Stop()
+	if c.n != 5 {
+		t.Errorf("Expected the 5 queued tasks to be executed before stopping, executed %d", c.n)
+	}
+	if _, found := GetStats()["stoppable"]; found {
+		t.Error("Stopped worker pool still registered")
+	}
+	wp.Stop()
+}
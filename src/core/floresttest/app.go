@@ -0,0 +1,150 @@
+package floresttest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/logger"
+	"github.com/jabong/florest-core/src/common/monitor"
+	"github.com/jabong/florest-core/src/core/service"
+)
+
+// loggerConfig logs the errors of the app to a file of the temporary directory of the test
+const loggerConfig = `{
+	"LogLevel": %d,
+	"DefaultLogType": "default",
+	"AppName": "floresttest-",
+	"FileLogger": [{"Key": "default", "Path": %q, "FormatType": "json", "FileNamePrefix": "app-"}]
+}`
+
+// appMutex lets a single app be served at a time
+var appMutex sync.Mutex
+
+// Config is what an app is initialised from
+type Config struct {
+	// AppConfig is the config of the app. The monitor is a disabled datadog agent if the platform is not
+	// specified, and the errors are logged to a temporary directory if LogConfFile is not
+	AppConfig config.AppConfig
+	// APIs are the apis served by the app
+	APIs []service.APIInterface
+	// Setup registers the rest with the service before the app is initialised, e.g. the resource bucket
+	// mappings with service.RegisterResourceBucketMapping
+	Setup func()
+}
+
+// App is an app serving its apis in process
+type App struct {
+	t       testing.TB
+	handler http.Handler
+	// logDir is the temporary directory the errors are logged to, removed once the app is closed
+	logDir string
+	once   sync.Once
+}
+
+// New initialises an app from the config, failing the test if the initialisation panics. The app is closed
+// once the test and its subtests are done
+func New(t testing.TB, conf Config) *App {
+	t.Helper()
+	appMutex.Lock()
+	app := &App{t: t}
+	t.Cleanup(app.Close)
+	service.Reset()
+
+	appConf := conf.AppConfig
+	if appConf.MonitorConfig.Platform == "" {
+		appConf.MonitorConfig.Platform = monitor.DatadogAgent
+	}
+	if appConf.LogConfFile == "" {
+		appConf.LogConfFile, app.logDir = writeLoggerConfig(t)
+	}
+	for _, api := range conf.APIs {
+		service.RegisterAPI(api)
+	}
+	if conf.Setup != nil {
+		conf.Setup()
+	}
+	if err := initialise(&appConf); err != nil {
+		t.Fatalf("Failed to initialise the app. Err : %v", err)
+	}
+	app.handler = service.Webserver{}.Handler()
+	return app
+}
+
+// initialise initialises the app, returning the panic of the initialisation as an error
+func initialise(conf *config.AppConfig) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	new(service.InitManager).ExecuteWithConfig(conf)
+	return nil
+}
+
+// writeLoggerConfig writes the config of a logger logging the errors to a temporary directory, returning the
+// config file and the directory
+func writeLoggerConfig(t testing.TB) (string, string) {
+	dir, err := ioutil.TempDir("", "floresttest")
+	if err != nil {
+		t.Fatalf("Failed to create the log directory. Err : %s", err)
+	}
+	file := filepath.Join(dir, "logger.json")
+	conf := fmt.Sprintf(loggerConfig, logger.ErrLevel, dir+string(filepath.Separator))
+	if err := ioutil.WriteFile(file, []byte(conf), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Failed to write the logger config. Err : %s", err)
+	}
+	return file, dir
+}
+
+// Close resets the service so that another app can be served. It is called once the test is done
+func (a *App) Close() {
+	a.once.Do(func() {
+		service.Reset()
+		if a.logDir != "" {
+			os.RemoveAll(a.logDir)
+		}
+		appMutex.Unlock()
+	})
+}
+
+// Handler returns the handler of the app, e.g. to be served by an httptest.Server
+func (a *App) Handler() http.Handler {
+	return a.handler
+}
+
+// NewRequest returns a request to the target of the app, a path with an optional query
+func (a *App) NewRequest(method string, target string) *Request {
+	return &Request{app: a, method: method, target: target, header: make(http.Header)}
+}
+
+// GET returns a GET request to the target
+func (a *App) GET(target string) *Request {
+	return a.NewRequest(http.MethodGet, target)
+}
+
+// POST returns a POST request to the target
+func (a *App) POST(target string) *Request {
+	return a.NewRequest(http.MethodPost, target)
+}
+
+// PUT returns a PUT request to the target
+func (a *App) PUT(target string) *Request {
+	return a.NewRequest(http.MethodPut, target)
+}
+
+// PATCH returns a PATCH request to the target
+func (a *App) PATCH(target string) *Request {
+	return a.NewRequest(http.MethodPatch, target)
+}
+
+// DELETE returns a DELETE request to the target
+func (a *App) DELETE(target string) *Request {
+	return a.NewRequest(http.MethodDelete, target)
+}
@@ -0,0 +1,16 @@
+// Package floresttest serves the apis of a florest app in process for the tests. New initialises an app
+// from a config and the apis, the app being reset once the test is done. The requests are built fluently
+// and their responses asserted on
+//
+//	func TestHello(t *testing.T) {
+//		app := floresttest.New(t, floresttest.Config{
+//			AppConfig: config.AppConfig{AppName: "florest"},
+//			APIs:      []service.APIInterface{new(hello.HelloAPI)},
+//		})
+//		app.GET("/florest/v1/hello/").Header("USER_ID", "u1").Bucket("hello", "2").Do().
+//			AssertStatus(200).AssertSuccess().AssertData("greeting", "Hello World")
+//	}
+//
+// The service being made of package level state, a single app is served at a time. New waits for the app
+// of another test to be closed, hence the tests serving an app should not run in parallel with t.Parallel
+package floresttest
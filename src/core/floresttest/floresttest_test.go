@@ -0,0 +1,174 @@
+package floresttest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/ratelimiter"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/healthcheck"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+	"github.com/jabong/florest-core/src/core/common/workerpool"
+	"github.com/jabong/florest-core/src/core/service"
+)
+
+type testNode struct {
+	id     string
+	result interface{}
+}
+
+func (n testNode) Name() string {
+	return "Test Node"
+}
+
+func (n *testNode) SetID(id string) {
+	n.id = id
+}
+
+func (n testNode) GetID() (id string, err error) {
+	return n.id, nil
+}
+
+func (n testNode) Execute(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+	if err, ok := n.result.(constants.AppError); ok {
+		data.IOData.Set(constants.APPError, &err)
+		return data, nil
+	}
+	data.IOData.Set(constants.Result, n.result)
+	return data, nil
+}
+
+type testAPI struct {
+	resource string
+	bucketID string
+	result   interface{}
+}
+
+func (a testAPI) GetVersion() versionmanager.Version {
+	bucketID := a.bucketID
+	if bucketID == "" {
+		bucketID = constants.OrchestratorBucketDefaultValue
+	}
+	return versionmanager.Version{
+		Resource: a.resource,
+		Version:  "V1",
+		Action:   "GET",
+		BucketID: bucketID,
+	}
+}
+
+func (a testAPI) GetOrchestrator() workflow.Orchestrator {
+	o := new(workflow.Orchestrator)
+	w := new(workflow.WorkFlowDefinition)
+	w.Create()
+	n := &testNode{result: a.result}
+	n.SetID("1")
+	w.AddExecutionNode(n)
+	w.SetStartNode(n)
+	o.Create(w)
+	return *o
+}
+
+func (a testAPI) GetHealthCheck() healthcheck.HCInterface {
+	return nil
+}
+
+func (a testAPI) GetRateLimiter() ratelimiter.RateLimiter {
+	return nil
+}
+
+func (a testAPI) Init() {
+}
+
+func newTestApp(t *testing.T, apis ...service.APIInterface) *App {
+	return New(t, Config{
+		AppConfig: config.AppConfig{AppName: "florest"},
+		APIs:      apis,
+		Setup: func() {
+			service.RegisterResourceBucketMapping("GREETING", "greeting")
+		},
+	})
+}
+
+func TestAppData(t *testing.T) {
+	app := newTestApp(t, testAPI{
+		resource: "GREETING",
+		result:   map[string]interface{}{"items": []map[string]interface{}{{"id": 7, "name": "hello"}}},
+	})
+	res := app.GET("/florest/v1/greeting/").Header("USER_ID", "u1").Do().
+		AssertStatus(http.StatusOK).
+		AssertSuccess().
+		AssertData("items.0.id", 7).
+		AssertData("items.0.name", "hello")
+	if _, err := res.Data("items.1"); err == nil {
+		t.Error("Expected items.1 not to be found")
+	}
+	var data struct {
+		Items []struct {
+			ID int `json:"id"`
+		} `json:"items"`
+	}
+	if err := res.DecodeData(&data); err != nil || len(data.Items) != 1 || data.Items[0].ID != 7 {
+		t.Errorf("Failed to decode the data %+v. Err : %v", data, err)
+	}
+}
+
+func TestAppError(t *testing.T) {
+	app := newTestApp(t, testAPI{
+		resource: "GREETING",
+		result:   constants.AppError{Code: constants.ParamsInValidErrorCode, Message: "Invalid name"},
+	})
+	app.GET("/florest/v1/greeting/").Do().
+		AssertStatus(http.StatusBadRequest).
+		AssertError(constants.ParamsInValidErrorCode)
+
+	app.GET("/florest/v1/unknown/").Do().AssertStatus(http.StatusNotFound)
+}
+
+func TestAppBucket(t *testing.T) {
+	app := newTestApp(t,
+		testAPI{resource: "GREETING", result: "Hello"},
+		testAPI{resource: "GREETING", bucketID: "2", result: "Hola"},
+	)
+	app.GET("/florest/v1/greeting/").Do().AssertData("", "Hello")
+	app.GET("/florest/v1/greeting/").Bucket("greeting", "2").Do().AssertData("", "Hola")
+}
+
+func TestAppReset(t *testing.T) {
+	t.Run("first", func(t *testing.T) {
+		app := newTestApp(t, testAPI{resource: "GREETING", result: "Hello"})
+		app.GET("/florest/v1/greeting/").Do().AssertStatus(http.StatusOK)
+	})
+	t.Run("second", func(t *testing.T) {
+		app := newTestApp(t, testAPI{resource: "FAREWELL", result: "Bye"})
+		app.GET("/florest/v1/greeting/").Do().AssertStatus(http.StatusNotFound)
+		app.GET("/florest/v1/farewell/").Do().AssertStatus(http.StatusOK).AssertData("", "Bye")
+	})
+}
+
+func TestAppProbes(t *testing.T) {
+	app := newTestApp(t)
+	app.GET("/live").Do().AssertStatus(http.StatusOK)
+	app.GET("/ready").Do().AssertStatus(http.StatusOK)
+}
+
+func TestAppCloseStopsShadowTraffic(t *testing.T) {
+	t.Run("app", func(t *testing.T) {
+		app := New(t, Config{
+			AppConfig: config.AppConfig{AppName: "florest", ShadowTraffic: config.ShadowTrafficConfig{
+				NWorkers: 2, TaskQueueSize: 2,
+				Resources: []config.ShadowResourceConfig{{Resource: "GREETING", SamplePercentage: 100}},
+			}},
+			APIs: []service.APIInterface{testAPI{resource: "GREETING", result: "Hello"}},
+		})
+		app.GET("/florest/v1/greeting/").Do().AssertStatus(http.StatusOK)
+		if _, found := workerpool.GetStats()["ShadowTraffic"]; !found {
+			t.Error("Shadow traffic worker pool not started")
+		}
+	})
+	if _, found := workerpool.GetStats()["ShadowTraffic"]; found {
+		t.Error("Shadow traffic worker pool not stopped once the app is closed")
+	}
+}
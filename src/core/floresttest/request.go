@@ -0,0 +1,88 @@
+package floresttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/constants"
+)
+
+// bucketHeader is the header carrying the buckets of the request
+const bucketHeader = "bucket"
+
+// Request is a request to an app built fluently
+type Request struct {
+	app     *App
+	method  string
+	target  string
+	header  http.Header
+	query   url.Values
+	buckets []string
+	body    []byte
+}
+
+// Header adds a header to the request
+func (r *Request) Header(name string, value string) *Request {
+	r.header.Add(name, value)
+	return r
+}
+
+// Query adds a query param to the request
+func (r *Request) Query(name string, value string) *Request {
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	r.query.Add(name, value)
+	return r
+}
+
+// Bucket adds the bucket of the key, mapped to a resource with service.RegisterResourceBucketMapping, to
+// the bucket header of the request
+func (r *Request) Bucket(key string, bucketID string) *Request {
+	r.buckets = append(r.buckets, key+constants.KeyValueSeperator+bucketID)
+	return r
+}
+
+// Body sets the body of the request and its content type
+func (r *Request) Body(body []byte, contentType string) *Request {
+	r.body = body
+	r.header.Set("Content-Type", contentType)
+	return r
+}
+
+// JSON sets the JSON encoding of the value as the body of the request, failing the test if it can not be
+// encoded
+func (r *Request) JSON(v interface{}) *Request {
+	b, err := json.Marshal(v)
+	if err != nil {
+		r.app.t.Helper()
+		r.app.t.Fatalf("Failed to encode the request body. Err : %s", err)
+	}
+	return r.Body(b, "application/json")
+}
+
+// Do serves the request by the app and returns its response
+func (r *Request) Do() *Response {
+	target := r.target
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, bytes.NewReader(r.body))
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	if len(r.buckets) > 0 {
+		req.Header.Set(bucketHeader, strings.Join(r.buckets, constants.FieldSeperator))
+	}
+	w := httptest.NewRecorder()
+	r.app.handler.ServeHTTP(w, req)
+	return &Response{t: r.app.t, Recorder: w}
+}
@@ -0,0 +1,173 @@
+package floresttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+// Response is the response of a request to an app, whose assertions fail the test and return the response
+// to be asserted on further
+type Response struct {
+	t testing.TB
+	// Recorder recorded the response
+	Recorder *httptest.ResponseRecorder
+}
+
+// Status returns the status of the response
+func (r *Response) Status() int {
+	return r.Recorder.Code
+}
+
+// Body returns the body of the response
+func (r *Response) Body() []byte {
+	return r.Recorder.Body.Bytes()
+}
+
+// Decode decodes the body of the response, an envelope of the status and the data
+func (r *Response) Decode() (*utilhttp.Response, error) {
+	res := new(utilhttp.Response)
+	if err := json.Unmarshal(r.Body(), res); err != nil {
+		return nil, fmt.Errorf("Failed to decode the response %s. Err : %s", r.Body(), err)
+	}
+	return res, nil
+}
+
+// DecodeData decodes the data of the response into v
+func (r *Response) DecodeData(v interface{}) error {
+	var res struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(r.Body(), &res); err != nil {
+		return fmt.Errorf("Failed to decode the response %s. Err : %s", r.Body(), err)
+	}
+	return json.Unmarshal(res.Data, v)
+}
+
+// Errors returns the errors of the response, either in the envelope or in the problem document
+func (r *Response) Errors() ([]constants.AppError, error) {
+	if strings.HasPrefix(r.Recorder.Header().Get("Content-Type"), "application/problem+json") {
+		var p utilhttp.Problem
+		if err := json.Unmarshal(r.Body(), &p); err != nil {
+			return nil, fmt.Errorf("Failed to decode the problem %s. Err : %s", r.Body(), err)
+		}
+		if len(p.Errors) > 0 {
+			return p.Errors, nil
+		}
+		return []constants.AppError{{Code: p.Code, Message: p.Title, DeveloperMessage: p.DeveloperMessage}}, nil
+	}
+	res, err := r.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return res.Status.Errors, nil
+}
+
+// Data returns the value at the path of the data of the response - the keys of the objects and the indexes
+// of the arrays separated by dots, e.g. items.0.id. The whole data is returned for an empty path
+func (r *Response) Data(path string) (interface{}, error) {
+	res, err := r.Decode()
+	if err != nil {
+		return nil, err
+	}
+	v := res.Data
+	if path == "" {
+		return v, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var found bool
+			if v, found = t[key]; !found {
+				return nil, fmt.Errorf("%s not found in the data", path)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, fmt.Errorf("%s not found in the data", path)
+			}
+			v = t[i]
+		default:
+			return nil, fmt.Errorf("%s not found in the data", path)
+		}
+	}
+	return v, nil
+}
+
+// AssertStatus asserts the status of the response
+func (r *Response) AssertStatus(status int) *Response {
+	r.t.Helper()
+	if r.Status() != status {
+		r.t.Errorf("Expected status %d, got %d. Body : %s", status, r.Status(), r.Body())
+	}
+	return r
+}
+
+// AssertHeader asserts a header of the response
+func (r *Response) AssertHeader(name string, value string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Header().Get(name); got != value {
+		r.t.Errorf("Expected header %s %q, got %q", name, value, got)
+	}
+	return r
+}
+
+// AssertSuccess asserts the response is successful, without errors
+func (r *Response) AssertSuccess() *Response {
+	r.t.Helper()
+	res, err := r.Decode()
+	if err != nil {
+		r.t.Error(err)
+		return r
+	}
+	if !res.Status.Success || len(res.Status.Errors) > 0 {
+		r.t.Errorf("Expected success, got %+v", res.Status)
+	}
+	return r
+}
+
+// AssertError asserts the response has an error of the code
+func (r *Response) AssertError(code constants.APPErrorCode) *Response {
+	r.t.Helper()
+	errs, err := r.Errors()
+	if err != nil {
+		r.t.Error(err)
+		return r
+	}
+	for _, e := range errs {
+		if e.Code == code {
+			return r
+		}
+	}
+	r.t.Errorf("Expected error %d, got %+v", code, errs)
+	return r
+}
+
+// AssertData asserts the value at the path of the data of the response, see Data. The expected value is
+// compared once encoded to and decoded from JSON, e.g. the numbers as float64
+func (r *Response) AssertData(path string, expected interface{}) *Response {
+	r.t.Helper()
+	got, err := r.Data(path)
+	if err != nil {
+		r.t.Error(err)
+		return r
+	}
+	b, err := json.Marshal(expected)
+	if err != nil {
+		r.t.Errorf("Failed to encode the expected value of %s. Err : %s", path, err)
+		return r
+	}
+	var want interface{}
+	json.Unmarshal(b, &want)
+	if !reflect.DeepEqual(got, want) {
+		r.t.Errorf("Expected %s to be %v, got %v", path, want, got)
+	}
+	return r
+}
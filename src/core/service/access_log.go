@@ -0,0 +1,315 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+)
+
+// Access log formats
+const (
+	accessLogJSON     = "JSON"
+	accessLogCombined = "Combined"
+)
+
+// combinedTimeFormat is the time format of the combined log format
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// accessLog writes a record for each request served
+type accessLog struct {
+	loggerKey               string
+	combined                bool
+	successSamplePercentage float64
+	trustedProxies          []*net.IPNet
+}
+
+// accessLogInstance is nil when the access log is not written
+var accessLogInstance *accessLog
+
+// accessEntry is the part of the record known once the request is served by the service pipeline
+type accessEntry struct {
+	resource      string
+	version       string
+	action        string
+	bucket        string
+	requestID     string
+	transactionID string
+	traceID       string
+	userID        string
+}
+
+// accessRecord is the JSON access log record
+type accessRecord struct {
+	Time          string  `json:"time"`
+	Method        string  `json:"method"`
+	URI           string  `json:"uri"`
+	Protocol      string  `json:"protocol"`
+	Resource      string  `json:"resource,omitempty"`
+	Version       string  `json:"version,omitempty"`
+	Action        string  `json:"action,omitempty"`
+	Bucket        string  `json:"bucket,omitempty"`
+	Status        int     `json:"status"`
+	Bytes         int64   `json:"bytes"`
+	DurationInMs  float64 `json:"durationInMs"`
+	ClientIP      string  `json:"clientIp"`
+	UserAgent     string  `json:"userAgent,omitempty"`
+	Referer       string  `json:"referer,omitempty"`
+	RequestID     string  `json:"reqId,omitempty"`
+	TransactionID string  `json:"tId,omitempty"`
+	TraceID       string  `json:"traceId,omitempty"`
+	UserID        string  `json:"userId,omitempty"`
+}
+
+// accessEntryKey is the key of the access entry in the context of the request
+type accessEntryKey struct{}
+
+// accessResponseWriter records the status and the size of the response
+type accessResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// InitAccessLog validates the access log config
+func InitAccessLog() {
+	conf := config.GlobalAppConfig.AccessLog
+	if conf.LoggerKey == "" {
+		accessLogInstance = nil
+		return
+	}
+	if _, err := logger.GetLoggerHandle(conf.LoggerKey); err != nil {
+		logger.Error(fmt.Sprintf("Invalid access log logger key. Err : %s", err))
+		panic(fmt.Sprintf("Invalid access log logger key. Err : %s", err))
+	}
+	a := &accessLog{loggerKey: conf.LoggerKey, successSamplePercentage: 100}
+	switch {
+	case conf.Format == "" || strings.EqualFold(conf.Format, accessLogJSON):
+	case strings.EqualFold(conf.Format, accessLogCombined):
+		a.combined = true
+	default:
+		logger.Error(fmt.Sprintf("Invalid access log format %s", conf.Format))
+		panic(fmt.Sprintf("Invalid access log format %s", conf.Format))
+	}
+	if p := conf.SuccessSamplePercentage; p != nil {
+		if *p < 0 || *p > 100 {
+			logger.Error(fmt.Sprintf("Invalid access log sample percentage %v", *p))
+			panic(fmt.Sprintf("Invalid access log sample percentage %v", *p))
+		}
+		a.successSamplePercentage = *p
+	}
+	for _, proxy := range conf.TrustedProxies {
+		network, err := parseTrustedProxy(proxy)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Invalid access log trusted proxy %s. Err : %s", proxy, err))
+			panic(fmt.Sprintf("Invalid access log trusted proxy %s. Err : %s", proxy, err))
+		}
+		a.trustedProxies = append(a.trustedProxies, network)
+	}
+	accessLogInstance = a
+}
+
+// parseTrustedProxy parses the address or the CIDR range of a trusted proxy
+func parseTrustedProxy(proxy string) (*net.IPNet, error) {
+	if strings.Contains(proxy, "/") {
+		_, network, err := net.ParseCIDR(proxy)
+		return network, err
+	}
+	ip := net.ParseIP(proxy)
+	if ip == nil {
+		return nil, errors.New("Invalid address")
+	}
+	bits := 8 * len(ip)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// makeAccessLogHandler writes the access log record of each request served by fn
+func makeAccessLogHandler(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a := accessLogInstance
+		if a == nil {
+			fn(w, r)
+			return
+		}
+		start := time.Now()
+		entry := new(accessEntry)
+		aw := &accessResponseWriter{ResponseWriter: w}
+		fn(aw, r.WithContext(context.WithValue(r.Context(), accessEntryKey{}, entry)))
+		a.write(r, aw, entry, start)
+	}
+}
+
+// setAccessEntry sets the resolved api and the ids of the request in the access entry of the request if any
+func setAccessEntry(r *http.Request, data *workflow.WorkFlowData) {
+	entry, ok := r.Context().Value(accessEntryKey{}).(*accessEntry)
+	if !ok {
+		return
+	}
+	entry.resource, entry.version, entry.action, entry.bucket, _ = getServiceVersion(*data)
+	rc, _ := data.ExecContext.Get(constants.RequestContext)
+	if reqContext, ok := rc.(utilhttp.RequestContext); ok {
+		entry.requestID = reqContext.RequestID
+		entry.transactionID = reqContext.TransactionID
+		entry.traceID = reqContext.TraceID
+		entry.userID = reqContext.UserID
+	}
+}
+
+// write writes the record if sampled
+func (a *accessLog) write(r *http.Request, w *accessResponseWriter, entry *accessEntry, start time.Time) {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if !a.sampled(status) {
+		return
+	}
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	rec := accessRecord{
+		Time:          start.Format(time.RFC3339),
+		Method:        r.Method,
+		URI:           uri,
+		Protocol:      r.Proto,
+		Resource:      entry.resource,
+		Version:       entry.version,
+		Action:        entry.action,
+		Bucket:        entry.bucket,
+		Status:        status,
+		Bytes:         w.bytes,
+		DurationInMs:  float64(time.Since(start)) / float64(time.Millisecond),
+		ClientIP:      a.getClientIP(r),
+		UserAgent:     r.UserAgent(),
+		Referer:       r.Referer(),
+		RequestID:     entry.requestID,
+		TransactionID: entry.transactionID,
+		TraceID:       entry.traceID,
+		UserID:        entry.userID,
+	}
+	if a.combined {
+		logger.AccessSpecific(a.loggerKey, formatCombined(rec, start))
+		return
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to encode access log record. Err : %s", err))
+		return
+	}
+	logger.AccessSpecific(a.loggerKey, string(b))
+}
+
+// sampled tells if the record of a response with the status is written, the 2xx responses being sampled
+func (a *accessLog) sampled(status int) bool {
+	if status < 200 || status >= 300 || a.successSamplePercentage >= 100 {
+		return true
+	}
+	return rand.Float64()*100 < a.successSamplePercentage
+}
+
+// formatCombined formats the record in the combined log format followed by the duration, the ids and the api
+func formatCombined(rec accessRecord, start time.Time) string {
+	bytes := "-"
+	if rec.Bytes > 0 {
+		bytes = fmt.Sprint(rec.Bytes)
+	}
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s \"%s\" \"%s\" %.3f %s %s %s %s %s %s",
+		rec.ClientIP, orDash(rec.UserID), start.Format(combinedTimeFormat), rec.Method, escape(rec.URI),
+		rec.Protocol, rec.Status, bytes, escape(orDash(rec.Referer)), escape(orDash(rec.UserAgent)),
+		rec.DurationInMs, orDash(rec.RequestID), orDash(rec.TransactionID), orDash(rec.Resource),
+		orDash(rec.Version), orDash(rec.Action), orDash(rec.Bucket))
+}
+
+// getClientIP returns the remote address of the request. For the requests from a trusted proxy, it is the last
+// address of X-Forwarded-For which is not of a trusted proxy, the first one if all of them are
+func (a *accessLog) getClientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	fwd := strings.Join(r.Header.Values("X-Forwarded-For"), ",")
+	if fwd == "" || !a.isTrustedProxy(remote) {
+		return remote
+	}
+	hops := strings.Split(fwd, ",")
+	for i := len(hops) - 1; i > 0; i-- {
+		if hop := strings.TrimSpace(hops[i]); hop != "" && !a.isTrustedProxy(hop) {
+			return hop
+		}
+	}
+	return strings.TrimSpace(hops[0])
+}
+
+// isTrustedProxy checks if the address is of a trusted proxy
+func (a *accessLog) isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range a.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func escape(s string) string {
+	return strings.Replace(strings.Replace(s, `\`, `\\`, -1), `"`, `\"`, -1)
+}
+
+func (w *accessResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush flushes the streamed responses
+func (w *accessResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hands the connection over to the WebSocket sessions
+func (w *accessResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("Response writer does not support hijacking")
+	}
+	w.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
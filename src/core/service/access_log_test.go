@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// hijackRecorder is a response recorder handing over one end of a pipe when hijacked
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.conn, bufio.NewReadWriter(bufio.NewReader(h.conn), bufio.NewWriter(h.conn)), nil
+}
+
+func TestFormatCombined(t *testing.T) {
+	start := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rec := accessRecord{
+		Method:        "GET",
+		URI:           `/florest/v1/item/?q="a\b"`,
+		Protocol:      "HTTP/1.1",
+		Resource:      "ITEM",
+		Version:       "V1",
+		Action:        "GET",
+		Bucket:        "1",
+		Status:        200,
+		Bytes:         42,
+		DurationInMs:  1.5,
+		ClientIP:      "10.0.0.1",
+		UserAgent:     `agent "x"`,
+		Referer:       `http://example.com/\`,
+		RequestID:     "req",
+		TransactionID: "tx",
+		UserID:        "user",
+	}
+	want := `10.0.0.1 - user [02/Jan/2020:03:04:05 +0000] "GET /florest/v1/item/?q=\"a\\b\" HTTP/1.1" 200 42 ` +
+		`"http://example.com/\\" "agent \"x\"" 1.500 req tx ITEM V1 GET 1`
+	if got := formatCombined(rec, start); got != want {
+		t.Errorf("Combined record %s, want %s", got, want)
+	}
+
+	rec = accessRecord{Method: "GET", URI: "/", Protocol: "HTTP/1.1", Status: 404, ClientIP: "10.0.0.1"}
+	want = `10.0.0.1 - - [02/Jan/2020:03:04:05 +0000] "GET / HTTP/1.1" 404 - "-" "-" 0.000 - - - - - -`
+	if got := formatCombined(rec, start); got != want {
+		t.Errorf("Combined record %s, want %s", got, want)
+	}
+}
+
+func TestAccessLogSampled(t *testing.T) {
+	none := &accessLog{successSamplePercentage: 0}
+	all := &accessLog{successSamplePercentage: 100}
+	for _, status := range []int{http.StatusOK, http.StatusCreated, http.StatusNoContent} {
+		if none.sampled(status) {
+			t.Errorf("Status %d sampled at 0%%", status)
+		}
+		if !all.sampled(status) {
+			t.Errorf("Status %d not sampled at 100%%", status)
+		}
+	}
+	for _, status := range []int{http.StatusSwitchingProtocols, http.StatusNotModified, http.StatusBadRequest,
+		http.StatusInternalServerError} {
+		if !none.sampled(status) {
+			t.Errorf("Status %d not logged at 0%%", status)
+		}
+	}
+}
+
+func TestGetClientIP(t *testing.T) {
+	a := new(accessLog)
+	for _, proxy := range []string{"10.0.0.0/8", "::1", "172.16.0.1"} {
+		network, err := parseTrustedProxy(proxy)
+		if err != nil {
+			t.Fatalf("Failed to parse trusted proxy %s. Err : %s", proxy, err)
+		}
+		a.trustedProxies = append(a.trustedProxies, network)
+	}
+	for _, proxy := range []string{"proxy", "10.0.0.0/33", ""} {
+		if _, err := parseTrustedProxy(proxy); err == nil {
+			t.Errorf("Expected error, but trusted proxy %q got parsed", proxy)
+		}
+	}
+	tests := []struct {
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"10.0.0.1:1234", "", "10.0.0.1"},
+		{"[::1]:1234", "", "::1"},
+		{"@", "", "@"},
+		{"10.0.0.1:1234", "192.168.0.1", "192.168.0.1"},
+		{"[::1]:1234", "192.168.0.1", "192.168.0.1"},
+		{"10.0.0.1:1234", " 192.168.0.1 , 172.16.0.1", "192.168.0.1"},
+		// the addresses prepended by the client are not trusted
+		{"10.0.0.1:1234", "1.1.1.1, 192.168.0.1, 10.0.0.2", "192.168.0.1"},
+		{"10.0.0.1:1234", "10.0.0.3, 172.16.0.1", "10.0.0.3"},
+		// X-Forwarded-For is not trusted from the other remote addresses
+		{"192.168.0.2:1234", "1.1.1.1", "192.168.0.2"},
+		{"@", "1.1.1.1", "@"},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remoteAddr
+		if test.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", test.forwarded)
+		}
+		if got := a.getClientIP(r); got != test.want {
+			t.Errorf("Client ip of %s forwarded for %q is %s, want %s", test.remoteAddr, test.forwarded, got,
+				test.want)
+		}
+	}
+}
+
+func TestAccessResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &accessResponseWriter{ResponseWriter: rec}
+	w.Write([]byte("hello "))
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte("world"))
+	if w.status != http.StatusOK || w.bytes != 11 {
+		t.Errorf("Recorded status %d and %d bytes, want 200 and 11 bytes", w.status, w.bytes)
+	}
+
+	rec = httptest.NewRecorder()
+	w = &accessResponseWriter{ResponseWriter: rec}
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusAccepted)
+	if w.status != http.StatusCreated || w.bytes != 0 {
+		t.Errorf("Recorded status %d and %d bytes, want 201 and no bytes", w.status, w.bytes)
+	}
+	w.Flush()
+	if !rec.Flushed {
+		t.Error("Flush not passed through")
+	}
+
+	if _, _, err := w.Hijack(); err == nil {
+		t.Error("Hijacked a response writer which does not support hijacking")
+	}
+	client, server := net.Pipe()
+	defer client.Close()
+	w = &accessResponseWriter{ResponseWriter: &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server}}
+	conn, _, err := w.Hijack()
+	if err != nil || conn != server {
+		t.Fatalf("Hijacked %v, %v", conn, err)
+	}
+	conn.Close()
+	if w.status != http.StatusSwitchingProtocols {
+		t.Errorf("Recorded status %d of the hijacked connection, want 101", w.status)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/jabong/florest-core/src/common/ratelimiter"
 	"github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/binder"
 	"github.com/jabong/florest-core/src/core/common/utils/healthcheck"
 	"github.com/jabong/florest-core/src/core/common/versionmanager"
 )
@@ -18,3 +19,10 @@ type APIInterface interface {
 
 	Init()
 }
+
+// RequestBinder can be implemented by an api to get the request bound to the declared types and validated
+// before its orchestrator runs. The bound values are set in the IO data as constants.BoundBody,
+// constants.BoundQuery, constants.BoundHeader and constants.BoundPath
+type RequestBinder interface {
+	GetRequestTypes() binder.Types
+}
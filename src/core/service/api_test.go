@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/ratelimiter"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/healthcheck"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+	"github.com/jabong/florest-core/src/core/floresttest"
+	"github.com/jabong/florest-core/src/core/service"
+)
+
+// executeFunc is the business logic of a test api
+type executeFunc func(data workflow.WorkFlowData) (workflow.WorkFlowData, error)
+
+type testNode struct {
+	id      string
+	execute executeFunc
+}
+
+func (n testNode) Name() string {
+	return "Test Node"
+}
+
+func (n *testNode) SetID(id string) {
+	n.id = id
+}
+
+func (n testNode) GetID() (id string, err error) {
+	return n.id, nil
+}
+
+func (n testNode) Execute(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+	return n.execute(data)
+}
+
+// testAPI is an api of a single node running the business logic
+type testAPI struct {
+	version versionmanager.Version
+	execute executeFunc
+}
+
+func (a testAPI) GetVersion() versionmanager.Version {
+	v := a.version
+	if v.Version == "" {
+		v.Version = "V1"
+	}
+	if v.BucketID == "" {
+		v.BucketID = constants.OrchestratorBucketDefaultValue
+	}
+	return v
+}
+
+func (a testAPI) GetOrchestrator() workflow.Orchestrator {
+	o := new(workflow.Orchestrator)
+	w := new(workflow.WorkFlowDefinition)
+	w.Create()
+	n := &testNode{execute: a.execute}
+	n.SetID("1")
+	w.AddExecutionNode(n)
+	w.SetStartNode(n)
+	o.Create(w)
+	return *o
+}
+
+func (a testAPI) GetHealthCheck() healthcheck.HCInterface {
+	return nil
+}
+
+func (a testAPI) GetRateLimiter() ratelimiter.RateLimiter {
+	return nil
+}
+
+func (a testAPI) Init() {
+}
+
+// result returns the business logic setting the result
+func result(v interface{}) executeFunc {
+	return func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+		data.IOData.Set(constants.Result, v)
+		return data, nil
+	}
+}
+
+// appError returns the business logic failing with the error
+func appError(code constants.APPErrorCode) executeFunc {
+	return func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+		data.IOData.Set(constants.APPError, &constants.AppError{Code: code, Message: "Failed"})
+		return data, nil
+	}
+}
+
+// metaData returns the response meta data of the request
+func metaData(data workflow.WorkFlowData) *utilhttp.ResponseMetaData {
+	v, _ := data.IOData.Get(constants.ResponseMetaData)
+	md, _ := v.(*utilhttp.ResponseMetaData)
+	return md
+}
+
+// newApp returns an app named florest serving the apis
+func newApp(t *testing.T, conf config.AppConfig, apis ...service.APIInterface) *floresttest.App {
+	if conf.AppName == "" {
+		conf.AppName = "florest"
+	}
+	return floresttest.New(t, floresttest.Config{AppConfig: conf, APIs: apis})
+}
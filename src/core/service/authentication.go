@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/auth"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// authenticate authenticates the caller of the apis having authenticators, setting it in the IO data and
+// its user and client app in the request context
+func authenticate(data workflow.WorkFlowData, req *utilhttp.Request,
+	apiVersion *versionmanager.Version) *constants.AppError {
+	if req == nil || apiVersion == nil || len(apiVersion.Authenticators) == 0 {
+		return nil
+	}
+	rc, _ := data.ExecContext.Get(constants.RequestContext)
+	p, appError := auth.Authenticate(req, apiVersion.Authenticators)
+	if appError != nil {
+		logger.Info(fmt.Sprintf("Request to %s not authenticated. %s", apiVersion.Resource,
+			appError.DeveloperMessage), rc)
+		return appError
+	}
+	if err := auth.SetPrincipal(data, p); err != nil {
+		logger.Error(fmt.Sprintf("Failed to set the caller of the request. Err : %s", err), rc)
+	}
+	return nil
+}
+
+// getPrincipalKey returns the client app and the user of the authenticated caller, empty if the caller is
+// not authenticated
+func getPrincipalKey(data workflow.WorkFlowData) string {
+	p := auth.GetPrincipal(data)
+	if p == nil {
+		return ""
+	}
+	return p.ClientAppID + ":" + p.UserID
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/auth"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// InitAuditLog validates the audit log config and sets the logger of the authorization decisions
+func InitAuditLog() {
+	key := config.GlobalAppConfig.AuditLog.LoggerKey
+	if key != "" {
+		if _, err := logger.GetLoggerHandle(key); err != nil {
+			logger.Error(fmt.Sprintf("Invalid audit log logger key. Err : %s", err))
+			panic(fmt.Sprintf("Invalid audit log logger key. Err : %s", err))
+		}
+	}
+	auth.SetAuditLogger(key)
+}
+
+// authorize authorizes the caller of the apis having an authorization rule, the decision being audit logged
+// with the resource, version, action and bucket of the api
+func authorize(data workflow.WorkFlowData, apiVersion *versionmanager.Version, orchBucket string) *constants.AppError {
+	if apiVersion == nil || apiVersion.Authorization == nil {
+		return nil
+	}
+	target := strings.Join([]string{apiVersion.Resource, apiVersion.Version, apiVersion.Action, orchBucket}, "/")
+	return auth.Authorize(data, apiVersion.Authorization, target)
+}
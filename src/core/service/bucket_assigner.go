@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	"github.com/jabong/florest-core/src/common/utils/misc"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+)
+
+// Sticky keys of an experiment. The request id is generated per request unless the caller sends it,
+// StickyKeyRequestID then assigning the buckets at random per request rather than per caller
+const (
+	StickyKeyUserID    = "UserID"
+	StickyKeySessionID = "SessionID"
+	StickyKeyRequestID = "RequestID"
+)
+
+// experiment is an experiment config validated for the bucket assignment
+type experiment struct {
+	resource    string
+	bucketKey   string
+	buckets     []config.BucketWeight
+	totalWeight int
+	stickyKey   string
+}
+
+// BucketAssigner assigns buckets to the requests which do not carry the bucket for the
+// requested resource in the bucket header, as per the configured experiments
+type BucketAssigner struct {
+	id          string
+	experiments map[string]experiment
+}
+
+func (n BucketAssigner) Name() string {
+	return "Bucket Assigner"
+}
+
+func (n *BucketAssigner) SetID(id string) {
+	n.id = id
+}
+
+func (n BucketAssigner) GetID() (id string, err error) {
+	return n.id, nil
+}
+
+// SetExperiments validates and sets the experiments used for the bucket assignment. The bucket key of an
+// experiment is registered with RegisterResourceBucketMapping for its resource if none is registered
+func (n *BucketAssigner) SetExperiments(experiments []config.ExperimentConfig) error {
+	n.experiments = make(map[string]experiment, len(experiments))
+	for _, conf := range experiments {
+		e, err := newExperiment(conf)
+		if err != nil {
+			return err
+		}
+		if _, found := n.experiments[e.resource]; found {
+			return fmt.Errorf("More than one experiment configured for resource %s", e.resource)
+		}
+		n.experiments[e.resource] = e
+	}
+	for _, e := range n.experiments {
+		if _, found := resourceBucketMapping[e.resource]; !found {
+			RegisterResourceBucketMapping(e.resource, e.bucketKey)
+		}
+	}
+	return nil
+}
+
+func (n BucketAssigner) Execute(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+	rcData, _ := data.ExecContext.Get(constants.RequestContext)
+	logger.Info(fmt.Sprintln("entered ", n.Name()), rcData)
+
+	rc, _ := rcData.(utilhttp.RequestContext)
+	n.assignBucket(data, rc)
+
+	logger.Info(fmt.Sprintln("exiting ", n.Name()), rc)
+	return data, nil
+}
+
+// assignBucket assigns the bucket of the experiment on the requested resource unless the request carries it
+func (n BucketAssigner) assignBucket(data workflow.WorkFlowData, rc utilhttp.RequestContext) {
+	resourceData, _ := data.IOData.Get(constants.Resource)
+	resource, _ := resourceData.(string)
+	e, found := n.experiments[resource]
+	if !found {
+		return
+	}
+
+	bucketsMap, _ := data.ExecContext.GetBuckets()
+	if _, present := bucketsMap[e.bucketKey]; present {
+		return
+	}
+
+	value := e.assign(rc)
+	if value == "" {
+		return
+	}
+
+	if bucketsMap == nil {
+		bucketsMap = make(map[string]string)
+	}
+	bucketsMap[e.bucketKey] = value
+	data.ExecContext.SetBuckets(bucketsMap)
+	data.IOData.Set(constants.AssignedBuckets, map[string]string{e.bucketKey: value})
+	logger.Info(fmt.Sprintf("Assigned bucket %s%s%s for resource %s", e.bucketKey,
+		constants.KeyValueSeperator, value, resource), rc)
+}
+
+// newExperiment validates the experiment config. The bucket key defaults to the one registered for the
+// resource, which the key specified should match
+func newExperiment(conf config.ExperimentConfig) (experiment, error) {
+	e := experiment{
+		resource:  strings.ToUpper(conf.Resource),
+		bucketKey: conf.BucketKey,
+		buckets:   conf.Buckets,
+		stickyKey: conf.StickyKey,
+	}
+	if e.resource == "" {
+		return e, fmt.Errorf("Resource not specified for experiment %+v", conf)
+	}
+	if e.bucketKey == "" {
+		e.bucketKey = resourceBucketMapping[e.resource]
+	}
+	if e.bucketKey == "" {
+		return e, fmt.Errorf("Bucket key neither specified nor registered for experiment on resource %s", e.resource)
+	}
+	if registered, found := resourceBucketMapping[e.resource]; found && registered != e.bucketKey {
+		return e, fmt.Errorf("Bucket key %s of experiment on resource %s does not match the registered key %s",
+			e.bucketKey, e.resource, registered)
+	}
+	switch e.stickyKey {
+	case StickyKeyUserID, StickyKeySessionID, StickyKeyRequestID:
+	default:
+		return e, fmt.Errorf("Invalid sticky key %s for experiment on resource %s", e.stickyKey, e.resource)
+	}
+	for _, b := range e.buckets {
+		if b.Value == "" || b.Weight < 0 {
+			return e, fmt.Errorf("Invalid bucket %+v for experiment on resource %s", b, e.resource)
+		}
+		e.totalWeight += b.Weight
+	}
+	if e.totalWeight <= 0 {
+		return e, fmt.Errorf("Traffic weights not specified for experiment on resource %s", e.resource)
+	}
+	return e, nil
+}
+
+// assign returns the bucket value for the request. The sticky key is consistently hashed so that
+// the same caller always gets the same bucket as long as the experiment config does not change
+func (e experiment) assign(rc utilhttp.RequestContext) string {
+	var sticky string
+	switch e.stickyKey {
+	case StickyKeyUserID:
+		sticky = rc.UserID
+	case StickyKeySessionID:
+		sticky = rc.SessionID
+	case StickyKeyRequestID:
+		sticky = rc.RequestID
+	}
+	if sticky == "" {
+		return ""
+	}
+
+	slot := int(misc.GetHash(e.resource+constants.KeyValueSeperator+sticky, e.totalWeight))
+	for _, b := range e.buckets {
+		if slot < b.Weight {
+			return b.Value
+		}
+		slot -= b.Weight
+	}
+	return ""
+}
+
+// getBucketTags returns the monitor tags for the buckets assigned to the request by the configured experiments.
+// The buckets carried in the bucket header are not tagged, the clients being free to send any of them
+func getBucketTags(data workflow.WorkFlowData) []string {
+	assigned, _ := data.IOData.Get(constants.AssignedBuckets)
+	bucketsMap, _ := assigned.(map[string]string)
+	tags := make([]string, 0, len(bucketsMap))
+	for k, v := range bucketsMap {
+		tags = append(tags, fmt.Sprintf("bucket_%s:%s", k, v))
+	}
+	sort.Strings(tags)
+	return tags
+}
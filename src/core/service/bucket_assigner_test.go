@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+)
+
+func newTestExperiment(t *testing.T, buckets ...config.BucketWeight) experiment {
+	e, err := newExperiment(config.ExperimentConfig{Resource: "hello", BucketKey: "hello", Buckets: buckets,
+		StickyKey: StickyKeyUserID})
+	if err != nil {
+		t.Fatalf("Failed to create the experiment. Err : %s", err)
+	}
+	return e
+}
+
+func TestNewExperiment(t *testing.T) {
+	defer Reset()
+	RegisterResourceBucketMapping("ORDERS", "orders")
+	buckets := []config.BucketWeight{{Value: "1", Weight: 50}, {Value: "2", Weight: 50}}
+
+	e, err := newExperiment(config.ExperimentConfig{Resource: "orders", Buckets: buckets, StickyKey: StickyKeySessionID})
+	if err != nil {
+		t.Fatalf("Failed to create the experiment. Err : %s", err)
+	}
+	if e.resource != "ORDERS" || e.bucketKey != "orders" || e.totalWeight != 100 {
+		t.Errorf("Unexpected experiment %+v", e)
+	}
+
+	invalid := map[string]config.ExperimentConfig{
+		"no resource":       {BucketKey: "b", Buckets: buckets, StickyKey: StickyKeyUserID},
+		"no bucket key":     {Resource: "hello", Buckets: buckets, StickyKey: StickyKeyUserID},
+		"other bucket key":  {Resource: "orders", BucketKey: "b", Buckets: buckets, StickyKey: StickyKeyUserID},
+		"invalid sticky":    {Resource: "hello", BucketKey: "b", Buckets: buckets, StickyKey: "Cookie"},
+		"no sticky":         {Resource: "hello", BucketKey: "b", Buckets: buckets},
+		"no buckets":        {Resource: "hello", BucketKey: "b", StickyKey: StickyKeyUserID},
+		"empty value":       {Resource: "hello", BucketKey: "b", Buckets: []config.BucketWeight{{Weight: 1}}, StickyKey: StickyKeyUserID},
+		"negative weight":   {Resource: "hello", BucketKey: "b", Buckets: []config.BucketWeight{{Value: "1", Weight: -1}, {Value: "2", Weight: 2}}, StickyKey: StickyKeyUserID},
+		"zero total weight": {Resource: "hello", BucketKey: "b", Buckets: []config.BucketWeight{{Value: "1"}}, StickyKey: StickyKeyUserID},
+	}
+	for name, conf := range invalid {
+		if _, err := newExperiment(conf); err == nil {
+			t.Errorf("Experiment with %s created", name)
+		}
+	}
+	if _, found := resourceBucketMapping["HELLO"]; found {
+		t.Error("Bucket key registered by the validation of an experiment")
+	}
+}
+
+func TestSetExperiments(t *testing.T) {
+	defer Reset()
+	buckets := []config.BucketWeight{{Value: "1", Weight: 1}}
+	n := new(BucketAssigner)
+	err := n.SetExperiments([]config.ExperimentConfig{
+		{Resource: "hello", BucketKey: "greeting", Buckets: buckets, StickyKey: StickyKeyUserID},
+		{Resource: "orders", BucketKey: "orders", StickyKey: StickyKeyUserID},
+	})
+	if err == nil {
+		t.Fatal("Invalid experiments set")
+	}
+	if len(resourceBucketMapping) != 0 {
+		t.Errorf("Bucket keys of invalid experiments registered %v", resourceBucketMapping)
+	}
+
+	err = n.SetExperiments([]config.ExperimentConfig{
+		{Resource: "hello", BucketKey: "greeting", Buckets: buckets, StickyKey: StickyKeyUserID},
+		{Resource: "HELLO", BucketKey: "greeting", Buckets: buckets, StickyKey: StickyKeyUserID},
+	})
+	if err == nil {
+		t.Error("Two experiments on a resource set")
+	}
+
+	err = n.SetExperiments([]config.ExperimentConfig{
+		{Resource: "hello", BucketKey: "greeting", Buckets: buckets, StickyKey: StickyKeyUserID},
+	})
+	if err != nil || resourceBucketMapping["HELLO"] != "greeting" {
+		t.Errorf("Bucket key of the experiment not registered %v. Err : %v", resourceBucketMapping, err)
+	}
+}
+
+func TestExperimentAssign(t *testing.T) {
+	e := newTestExperiment(t, config.BucketWeight{Value: "1", Weight: 20}, config.BucketWeight{Value: "2", Weight: 0},
+		config.BucketWeight{Value: "3", Weight: 80})
+	counts := make(map[string]int)
+	const users = 10000
+	for i := 0; i < users; i++ {
+		rc := utilhttp.RequestContext{UserID: fmt.Sprintf("user-%d", i)}
+		value := e.assign(rc)
+		counts[value]++
+		rc.RequestID = "other"
+		if again := e.assign(rc); again != value {
+			t.Fatalf("User %s assigned %s then %s", rc.UserID, value, again)
+		}
+	}
+	if counts["2"] != 0 || counts[""] != 0 {
+		t.Errorf("Unexpected assignments %v", counts)
+	}
+	if share := float64(counts["1"]) / users; share < 0.17 || share > 0.23 {
+		t.Errorf("Expected about 20%% of the users in bucket 1, got %v", counts)
+	}
+
+	if value := e.assign(utilhttp.RequestContext{SessionID: "s1", RequestID: "r1"}); value != "" {
+		t.Errorf("Request without the sticky key assigned %s", value)
+	}
+}
+
+func newBucketAssignerData(resource string, rc utilhttp.RequestContext, buckets map[string]string) workflow.WorkFlowData {
+	data := workflow.WorkFlowData{}
+	data.Create(new(workflow.WorkFlowIOInMemoryImpl), new(workflow.WorkFlowECInMemoryImpl))
+	data.IOData.Set(constants.Resource, resource)
+	data.ExecContext.Set(constants.RequestContext, rc)
+	if buckets != nil {
+		data.ExecContext.SetBuckets(buckets)
+	}
+	return data
+}
+
+func TestBucketAssignerExecute(t *testing.T) {
+	defer Reset()
+	n := new(BucketAssigner)
+	err := n.SetExperiments([]config.ExperimentConfig{{Resource: "hello", BucketKey: "greeting",
+		Buckets: []config.BucketWeight{{Value: "2", Weight: 1}}, StickyKey: StickyKeyUserID}})
+	if err != nil {
+		t.Fatalf("Failed to set the experiments. Err : %s", err)
+	}
+
+	data, _ := n.Execute(newBucketAssignerData("HELLO", utilhttp.RequestContext{UserID: "u1"},
+		map[string]string{"other": "1"}))
+	buckets, _ := data.ExecContext.GetBuckets()
+	assigned, _ := data.IOData.Get(constants.AssignedBuckets)
+	if buckets["greeting"] != "2" || buckets["other"] != "1" || fmt.Sprint(assigned) != "map[greeting:2]" {
+		t.Errorf("Bucket not assigned, buckets %v assigned %v", buckets, assigned)
+	}
+	// only the assigned buckets are tagged
+	if tags := getBucketTags(data); fmt.Sprint(tags) != "[bucket_greeting:2]" {
+		t.Errorf("Unexpected bucket tags %v", tags)
+	}
+
+	unassigned := map[string]workflow.WorkFlowData{
+		"carried":       newBucketAssignerData("HELLO", utilhttp.RequestContext{UserID: "u1"}, map[string]string{"greeting": "1"}),
+		"no experiment": newBucketAssignerData("ORDERS", utilhttp.RequestContext{UserID: "u1"}, nil),
+		"no sticky key": newBucketAssignerData("HELLO", utilhttp.RequestContext{RequestID: "r1"}, nil),
+	}
+	for name, d := range unassigned {
+		data, _ := n.Execute(d)
+		buckets, _ := data.ExecContext.GetBuckets()
+		assigned, _ := data.IOData.Get(constants.AssignedBuckets)
+		if assigned != nil || (buckets["greeting"] != "" && buckets["greeting"] != "1") {
+			t.Errorf("Bucket assigned with %s, buckets %v assigned %v", name, buckets, assigned)
+		}
+		if tags := getBucketTags(data); len(tags) != 0 {
+			t.Errorf("Buckets tagged with %s, %v", name, tags)
+		}
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"github.com/jabong/florest-core/src/common/logger"
 	"github.com/jabong/florest-core/src/common/monitor"
 	"github.com/jabong/florest-core/src/common/profiler"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
 	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
 	"github.com/jabong/florest-core/src/core/common/utils/misc"
 	"github.com/jabong/florest-core/src/core/common/utils/orchestratorhelper"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
 )
 
 type BusinessLogicExecutor struct {
@@ -36,13 +38,38 @@ func (n BusinessLogicExecutor) Execute(data workflow.WorkFlowData) (workflow.Wor
 	logger.Info(fmt.Sprintf("Resource: %s, Version: %s, Action: %s, BucketId: %s, PathParams: %s", resource,
 		version, action, orchBucket, pathParams), rc)
 
-	orchestrator, ratelimiter, parameters, oerr := orchestratorhelper.GetOrchestrator(resource, version,
+	orchestrator, ratelimiter, parameters, servedVersion, oerr := orchestratorhelper.ResolveOrchestrator(resource, version,
 		action, orchBucket, pathParams)
 	if oerr != nil {
 		data.IOData.Set(constants.APPError, oerr)
 		return data, nil
 	}
 
+	if m, _ := data.IOData.Get(constants.ResponseMetaData); m != nil {
+		if md, ok := m.(*utilhttp.ResponseMetaData); ok && md != nil {
+			md.ServedVersion = servedVersion
+		}
+	}
+	if servedVersion != version {
+		logger.Info(fmt.Sprintf("Version %s of %s is served by version %s", version, resource, servedVersion), rc)
+	}
+
+	apiVersion, _ := versionmanager.GetVersion(resource, servedVersion, action, orchBucket, pathParams)
+	if apiVersion != nil {
+		data.IOData.Set(constants.APIVersion, apiVersion)
+	}
+
+	if appError := negotiateMediaType(data, resource, apiVersion); appError != nil {
+		data.IOData.Set(constants.APPError, appError)
+		return data, nil
+	}
+
+	if appError := checkDeprecation(data, resource, version, servedVersion, action, orchBucket,
+		pathParams); appError != nil {
+		data.IOData.Set(constants.APPError, appError)
+		return data, nil
+	}
+
 	if ratelimiter != nil {
 		if rl := *ratelimiter; rl != nil {
 			exceeded, res, err := rl.RateLimit("")
@@ -73,12 +100,75 @@ func (n BusinessLogicExecutor) Execute(data workflow.WorkFlowData) (workflow.Wor
 		logger.Error("Error in getting request from Workflow IO Data")
 	}
 
+	if appError := authenticate(data, req, apiVersion); appError != nil {
+		data.IOData.Set(constants.APPError, appError)
+		return data, nil
+	}
+
+	if appError := authorize(data, apiVersion, orchBucket); appError != nil {
+		data.IOData.Set(constants.APPError, appError)
+		return data, nil
+	}
+
+	if appError := parseUpload(data, req, apiVersion); appError != nil {
+		data.IOData.Set(constants.APPError, appError)
+		return data, nil
+	}
+
+	if appErrors := bindRequest(data, req, apiVersion); appErrors != nil {
+		data.IOData.Set(constants.APPError, appErrors)
+		return data, nil
+	}
+
+	replayed, appError := checkIdempotency(data, req, apiVersion, pathParams)
+	if appError != nil {
+		data.IOData.Set(constants.APPError, appError)
+		return data, nil
+	}
+
+	// the retry of a request served gets its response replayed, its If-Match being stale once the request
+	// changed the ETag
+	if !replayed {
+		if appError := checkIfMatch(data, req, apiVersion, resource, servedVersion, orchBucket,
+			pathParams); appError != nil {
+			data.IOData.Set(constants.APPError, appError)
+			return data, nil
+		}
+	}
+
+	if appError := openStream(data, apiVersion); appError != nil {
+		data.IOData.Set(constants.APPError, appError)
+		return data, nil
+	}
+
 	dderr := monitor.GetInstance().Count(
-		fmt.Sprintf("%v_%v_%v_%v_%vrequest_count", action, version, resource, orchBucket, getCustomMetricPrefix(data)), 1, nil, 1)
+		fmt.Sprintf("%v_%v_%v_%v_%vrequest_count", action, version, resource, orchBucket, getCustomMetricPrefix(data)), 1,
+		getBucketTags(data), 1)
 	if dderr != nil {
 		logger.Error(fmt.Sprintln("Monitoring Error ", dderr.Error()), rc)
 	}
 
+	if isWebSocket(apiVersion) {
+		if appError := openWebSocket(data, req, orchestrator, apiVersion, resource, version, action,
+			orchBucket); appError != nil {
+			data.IOData.Set(constants.APPError, appError)
+		}
+		logger.Info(fmt.Sprintln("exiting ", n.Name()), rc)
+		return data, nil
+	}
+
+	if replayed {
+		logger.Info(fmt.Sprintln("exiting ", n.Name()), rc)
+		return data, nil
+	}
+
+	if lookupResponseCache(data, req, apiVersion, orchBucket, pathParams) {
+		logger.Info(fmt.Sprintln("exiting ", n.Name()), rc)
+		return data, nil
+	}
+
+	shadow := shadowTrafficInstance.prepare(data, resource, version, action, orchBucket, pathParams)
+
 	prof := profiler.NewProfiler()
 	nameOforchestratorExecuted := fmt.Sprintf("%v_%v_%v_%v_execution", action, version,
 		resource, orchBucket)
@@ -97,6 +187,7 @@ func (n BusinessLogicExecutor) Execute(data workflow.WorkFlowData) (workflow.Wor
 	}
 
 	data.IOData.Set(constants.ResponseData, res)
+	shadowTrafficInstance.mirror(shadow, res, err)
 
 	if err != nil {
 		data.IOData.Set(constants.APPError, err)
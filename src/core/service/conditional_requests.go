@@ -0,0 +1,185 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+	"github.com/jabong/florest-core/src/core/common/utils/orchestratorhelper"
+	"github.com/jabong/florest-core/src/core/common/utils/responseheaders"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// setValidatorHeaders sets the ETag and the Last-Modified headers of a successful response
+func setValidatorHeaders(data workflow.WorkFlowData, apiResponse *utilhttp.APIResponse, resData interface{}) {
+	req, err := misc.GetRequestFromIO(data)
+	if err != nil {
+		return
+	}
+	md := getResponseMetaData(data)
+	etag := getETag(data, req.HTTPVerb, md, resData)
+	if apiResponse.Headers == nil {
+		apiResponse.Headers = make(map[string]string)
+	}
+	if etag != "" {
+		apiResponse.Headers[utilhttp.ETagHeader] = etag
+	}
+	if md != nil && !md.LastModified.IsZero() {
+		apiResponse.Headers[utilhttp.LastModifiedHeader] = md.LastModified.UTC().Format(http.TimeFormat)
+	}
+}
+
+// answerNotModified answers the conditional GET requests whose ETag or modification time match the
+// validator headers of the successful response with 304 Not Modified
+func answerNotModified(data workflow.WorkFlowData, apiResponse *utilhttp.APIResponse) {
+	req, err := misc.GetRequestFromIO(data)
+	if err != nil || req.HTTPVerb != utilhttp.GET {
+		return
+	}
+	var notModified bool
+	if req.Headers.IfNoneMatch != "" {
+		notModified = utilhttp.MatchETag(req.Headers.IfNoneMatch, apiResponse.Headers[utilhttp.ETagHeader], true)
+	} else if lastModified, err := http.ParseTime(apiResponse.Headers[utilhttp.LastModifiedHeader]); err == nil {
+		notModified = utilhttp.NotModifiedSince(req.Headers.IfModifiedSince, lastModified)
+	}
+	if notModified {
+		apiResponse.HTTPStatus = constants.HTTPStatusNotModified
+		apiResponse.Body = nil
+		delete(apiResponse.Headers, "Content-Type")
+	}
+}
+
+// getETag returns the ETag of the response - the version of the resource set by the nodes in the response
+// meta data, else for the GET requests of the apis with ETag enabled a hash of the response data
+func getETag(data workflow.WorkFlowData, method utilhttp.Method, md *utilhttp.ResponseMetaData,
+	resData interface{}) string {
+	if md != nil && md.ETag != "" {
+		return utilhttp.QuoteETag(md.ETag)
+	}
+	if method != utilhttp.GET || !responseheaders.GetResponseHeaderFields(data).ETag {
+		return ""
+	}
+	b, err := json.Marshal(resData)
+	if err != nil {
+		return ""
+	}
+	return utilhttp.NewETag(b)
+}
+
+func getResponseMetaData(data workflow.WorkFlowData) *utilhttp.ResponseMetaData {
+	m, _ := data.IOData.Get(constants.ResponseMetaData)
+	md, _ := m.(*utilhttp.ResponseMetaData)
+	return md
+}
+
+// checkIfMatch evaluates the If-Match of the PUT, PATCH and DELETE requests against the current ETag of the
+// resource, given by the ETagLookup of the api, else got by running the GET api of the same resource, served
+// version, bucket and path as the caller of the request. The precondition is not evaluated if there is no
+// ETagLookup and no GET api registered for the path, or if no ETag is given
+func checkIfMatch(data workflow.WorkFlowData, req *utilhttp.Request, apiVersion *versionmanager.Version,
+	resource string, servedVersion string, orchBucket string, pathParams string) *constants.AppError {
+	if req == nil || req.Headers.IfMatch == "" ||
+		(req.HTTPVerb != utilhttp.PUT && req.HTTPVerb != utilhttp.PATCH && req.HTTPVerb != utilhttp.DELETE) {
+		return nil
+	}
+	rc, _ := data.ExecContext.Get(constants.RequestContext)
+	var etag string
+	if apiVersion != nil && apiVersion.ETagLookup != nil {
+		current, exists, err := apiVersion.ETagLookup(req)
+		if err != nil {
+			return &constants.AppError{Code: constants.ResourceErrorCode, Message: "Precondition can not be evaluated",
+				DeveloperMessage: err.Error()}
+		}
+		if !exists {
+			return &constants.AppError{Code: constants.PreconditionFailedErrorCode, Message: "Precondition failed",
+				DeveloperMessage: "Resource does not exist"}
+		}
+		if current != "" {
+			etag = utilhttp.QuoteETag(current)
+		}
+	} else {
+		var appError *constants.AppError
+		if etag, appError = getCurrentETag(data, req, resource, servedVersion, orchBucket, pathParams); appError != nil {
+			return appError
+		}
+	}
+	if etag == "" {
+		logger.Info(fmt.Sprintf("If-Match of %s not evaluated, no ETag given", resource), rc)
+		return nil
+	}
+	if !utilhttp.MatchETag(req.Headers.IfMatch, etag, false) {
+		return &constants.AppError{Code: constants.PreconditionFailedErrorCode, Message: "Precondition failed",
+			DeveloperMessage: fmt.Sprintf("If-Match does not match the current ETag %s", etag)}
+	}
+	return nil
+}
+
+// getCurrentETag runs the GET api of the resource for its ETag. The GET api authenticates and authorizes the
+// caller and binds its own values from the request, the values bound for the request and the state of its
+// response being cleared from the copy of the IO data it runs on. The ETag is empty if there is no GET api
+func getCurrentETag(data workflow.WorkFlowData, req *utilhttp.Request, resource string, servedVersion string,
+	orchBucket string, pathParams string) (string, *constants.AppError) {
+	rc, _ := data.ExecContext.Get(constants.RequestContext)
+	ec, ok := data.ExecContext.(*workflow.WorkFlowECInMemoryImpl)
+	if !ok {
+		return "", nil
+	}
+	orchestrator, _, parameters, getVersion, err := orchestratorhelper.ResolveOrchestrator(resource, servedVersion,
+		string(utilhttp.GET), orchBucket, pathParams)
+	if err != nil {
+		logger.Info(fmt.Sprintf("If-Match of %s not evaluated, GET api not found", resource), rc)
+		return "", nil
+	}
+
+	getReq := *req
+	getReq.HTTPVerb = utilhttp.GET
+	getReq.PathParameters = parameters
+	if req.OriginalRequest != nil {
+		httpReq := *req.OriginalRequest
+		httpReq.Method = string(utilhttp.GET)
+		httpReq.Body = http.NoBody
+		httpReq.ContentLength = 0
+		getReq.OriginalRequest = &httpReq
+	}
+	getIO := data.IOData.Clone()
+	clearRequestState(getIO)
+	getIO.Set(constants.Request, &getReq)
+	getIO.Set(constants.ResponseMetaData, utilhttp.NewResponseMetaData())
+	getAPIVersion, _ := versionmanager.GetVersion(resource, getVersion, string(utilhttp.GET), orchBucket, pathParams)
+	getIO.Set(constants.APIVersion, getAPIVersion)
+	getData := workflow.WorkFlowData{}
+	getData.Create(getIO, ec.Clone())
+
+	if appError := authenticate(getData, &getReq, getAPIVersion); appError != nil {
+		return "", appError
+	}
+	if appError := authorize(getData, getAPIVersion, orchBucket); appError != nil {
+		return "", appError
+	}
+	if appErrors := bindRequest(getData, &getReq, getAPIVersion); appErrors != nil {
+		return "", &constants.AppError{Code: constants.ResourceErrorCode, Message: "Precondition can not be evaluated",
+			DeveloperMessage: appErrors.Error()}
+	}
+
+	res, err := orchestratorhelper.ExecuteOrchestrator(&getData, orchestrator)
+	var getStatus interface{} = err
+	if err == nil {
+		// the nodes may as well report the errors in the io data
+		getStatus, _ = getData.IOData.Get(constants.APPError)
+	}
+	if getErrors := getAppErrors(getStatus); len(getErrors.Errors) > 0 {
+		status := constants.GetAppHTTPError(*getErrors)
+		if status.HTTPStatusCode == constants.HTTPStatusNotFound {
+			return "", &constants.AppError{Code: constants.PreconditionFailedErrorCode, Message: "Precondition failed",
+				DeveloperMessage: "Resource does not exist"}
+		}
+		return "", &constants.AppError{Code: constants.ResourceErrorCode, Message: "Precondition can not be evaluated",
+			DeveloperMessage: fmt.Sprintf("%v", getErrors.Errors)}
+	}
+	return getETag(getData, utilhttp.GET, getResponseMetaData(getData), res), nil
+}
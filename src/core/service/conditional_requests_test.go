@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/auth"
+	"github.com/jabong/florest-core/src/core/common/utils/binder"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+	"github.com/jabong/florest-core/src/core/floresttest"
+)
+
+// lastModified is the time the test resources were last modified
+var lastModified = time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// boundAPI is a test api binding the request to the types
+type boundAPI struct {
+	testAPI
+	types binder.Types
+}
+
+func (a boundAPI) GetRequestTypes() binder.Types {
+	return a.types
+}
+
+// itemBody is the body the PUT api of the ITEM resource binds
+type itemBody struct {
+	Name string `json:"name"`
+}
+
+// newConditionalApp returns an app serving the GET and PUT apis of the ITEM resource at revision 1, and the
+// GET api of the GONE resource which is not found
+func newConditionalApp(t *testing.T) *floresttest.App {
+	get := testAPI{
+		version: versionmanager.Version{Resource: "ITEM", Action: "GET"},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			md := metaData(data)
+			md.ETag = "1"
+			md.LastModified = lastModified
+			return result("item")(data)
+		},
+	}
+	put := testAPI{version: versionmanager.Version{Resource: "ITEM", Action: "PUT"}, execute: result("updated")}
+	gone := testAPI{version: versionmanager.Version{Resource: "GONE", Action: "GET"},
+		execute: appError(constants.InvalidRequestURI)}
+	putGone := testAPI{version: versionmanager.Version{Resource: "GONE", Action: "PUT"}, execute: result("updated")}
+	return newApp(t, config.AppConfig{}, get, put, gone, putGone)
+}
+
+func TestNotModified(t *testing.T) {
+	app := newConditionalApp(t)
+
+	app.GET("/florest/v1/item/").Do().AssertStatus(http.StatusOK).AssertHeader("ETag", `"1"`).
+		AssertHeader("Last-Modified", lastModified.Format(http.TimeFormat))
+	res := app.GET("/florest/v1/item/").Header("If-None-Match", `"0", W/"1"`).Do().
+		AssertStatus(http.StatusNotModified)
+	if len(res.Body()) != 0 {
+		t.Errorf("Body sent with 304, %s", res.Body())
+	}
+	app.GET("/florest/v1/item/").Header("If-None-Match", `"0"`).Do().AssertStatus(http.StatusOK)
+	// If-None-Match takes precedence over If-Modified-Since
+	app.GET("/florest/v1/item/").Header("If-None-Match", `"0"`).
+		Header("If-Modified-Since", lastModified.Format(http.TimeFormat)).Do().AssertStatus(http.StatusOK)
+
+	app.GET("/florest/v1/item/").Header("If-Modified-Since", lastModified.Format(http.TimeFormat)).Do().
+		AssertStatus(http.StatusNotModified)
+	app.GET("/florest/v1/item/").Header("If-Modified-Since", lastModified.Add(-time.Second).Format(http.TimeFormat)).
+		Do().AssertStatus(http.StatusOK)
+}
+
+func TestIfMatch(t *testing.T) {
+	app := newConditionalApp(t)
+
+	app.PUT("/florest/v1/item/").Header("If-Match", `"1"`).Do().AssertStatus(http.StatusOK).AssertData("", "updated")
+	app.PUT("/florest/v1/item/").Header("If-Match", `"0", "1"`).Do().AssertStatus(http.StatusOK)
+	app.PUT("/florest/v1/item/").Header("If-Match", `*`).Do().AssertStatus(http.StatusOK)
+	app.PUT("/florest/v1/item/").Do().AssertStatus(http.StatusOK)
+	app.PUT("/florest/v1/item/").Header("If-Match", `"0"`).Do().
+		AssertStatus(http.StatusPreconditionFailed).AssertError(constants.PreconditionFailedErrorCode)
+	// the strong comparison fails for the weak tags
+	app.PUT("/florest/v1/item/").Header("If-Match", `W/"1"`).Do().AssertStatus(http.StatusPreconditionFailed)
+
+	// a resource which does not exist matches no tag
+	app.PUT("/florest/v1/gone/").Header("If-Match", `*`).Do().
+		AssertStatus(http.StatusPreconditionFailed).AssertError(constants.PreconditionFailedErrorCode)
+	app.PUT("/florest/v1/gone/").Do().AssertStatus(http.StatusOK)
+}
+
+func TestIfMatchLookup(t *testing.T) {
+	var getCalls int
+	var etag string
+	var exists bool
+	var lookupErr error
+	get := testAPI{
+		version: versionmanager.Version{Resource: "ITEM", Action: "GET"},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			getCalls++
+			metaData(data).ETag = "0"
+			return result("item")(data)
+		},
+	}
+	put := testAPI{
+		version: versionmanager.Version{Resource: "ITEM", Action: "PUT",
+			ETagLookup: func(req *utilhttp.Request) (string, bool, error) {
+				return etag, exists, lookupErr
+			}},
+		execute: result("updated"),
+	}
+	app := newApp(t, config.AppConfig{}, get, put)
+
+	etag, exists = "1", true
+	app.PUT("/florest/v1/item/").Header("If-Match", `"1"`).Do().AssertStatus(http.StatusOK).AssertData("", "updated")
+	app.PUT("/florest/v1/item/").Header("If-Match", `"0"`).Do().
+		AssertStatus(http.StatusPreconditionFailed).AssertError(constants.PreconditionFailedErrorCode)
+	etag = `W/"1"`
+	app.PUT("/florest/v1/item/").Header("If-Match", `W/"1"`).Do().AssertStatus(http.StatusPreconditionFailed)
+	// no precondition is evaluated without an ETag
+	etag = ""
+	app.PUT("/florest/v1/item/").Header("If-Match", `"0"`).Do().AssertStatus(http.StatusOK)
+	exists = false
+	app.PUT("/florest/v1/item/").Header("If-Match", `*`).Do().
+		AssertStatus(http.StatusPreconditionFailed).AssertError(constants.PreconditionFailedErrorCode)
+	lookupErr = errors.New("store down")
+	app.PUT("/florest/v1/item/").Header("If-Match", `*`).Do().AssertError(constants.ResourceErrorCode)
+	if getCalls != 0 {
+		t.Errorf("GET api run %d times with an ETag lookup", getCalls)
+	}
+}
+
+func TestIfMatchOfAuthenticatedGET(t *testing.T) {
+	store := auth.NewMemoryKeyStore([]auth.Key{{ID: "a", UserID: "u1"}})
+	get := testAPI{
+		version: versionmanager.Version{Resource: "ITEM", Action: "GET",
+			Authenticators: []auth.Authenticator{auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{}, store)}},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			metaData(data).ETag = auth.GetPrincipal(data).UserID
+			return result("item")(data)
+		},
+	}
+	put := testAPI{version: versionmanager.Version{Resource: "ITEM", Action: "PUT"}, execute: result("updated")}
+	app := newApp(t, config.AppConfig{}, get, put)
+
+	// the GET api authenticates the caller of the PUT request before giving its ETag
+	app.PUT("/florest/v1/item/").Header("If-Match", `"u1"`).Do().AssertError(constants.UnauthorizedErrorCode)
+	app.PUT("/florest/v1/item/").Header("If-Match", `"u1"`).Header("X-Api-Key", "a").Do().
+		AssertStatus(http.StatusOK).AssertData("", "updated")
+}
+
+func TestIfMatchBoundValues(t *testing.T) {
+	get := testAPI{
+		version: versionmanager.Version{Resource: "ITEM", Action: "GET"},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			if body, _ := data.IOData.Get(constants.BoundBody); body != nil {
+				t.Errorf("GET api got the body bound for the PUT request, %v", body)
+			}
+			metaData(data).ETag = "1"
+			return result("item")(data)
+		},
+	}
+	put := boundAPI{
+		testAPI: testAPI{
+			version: versionmanager.Version{Resource: "ITEM", Action: "PUT"},
+			execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+				body, _ := data.IOData.Get(constants.BoundBody)
+				return result(body.(*itemBody).Name)(data)
+			},
+		},
+		types: binder.Types{Body: itemBody{}},
+	}
+	app := newApp(t, config.AppConfig{}, get, put)
+
+	app.PUT("/florest/v1/item/").Header("If-Match", `"1"`).JSON(itemBody{Name: "new"}).Do().
+		AssertStatus(http.StatusOK).AssertData("", "new")
+}
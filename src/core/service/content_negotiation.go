@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/encoder"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// RegisterEncoder registers the encoder for a response media type, replacing the default encoder
+// of the media type if any
+func RegisterEncoder(mediaType string, e encoder.Encoder) {
+	encoder.Register(mediaType, e)
+}
+
+// negotiateMediaType selects the response media type from the Accept header among the media types
+// allowed by the api and sets it in the IO data. Returns an error if none of them is acceptable.
+// Streaming apis respond in the media type of the stream, errors before the stream starts in the default one
+func negotiateMediaType(data workflow.WorkFlowData, resource string, apiVersion *versionmanager.Version) *constants.AppError {
+	if isStreamed(apiVersion) {
+		data.IOData.Set(constants.ResponseMediaType, encoder.DefaultMediaType)
+		return nil
+	}
+	var allowed []string
+	if apiVersion != nil {
+		allowed = apiVersion.MediaTypes
+	}
+	var accept string
+	if req, err := misc.GetRequestFromIO(data); err == nil {
+		accept = req.Headers.Accept
+	}
+
+	mediaType, ok := encoder.Negotiate(accept, allowed)
+	if !ok {
+		// the error itself is sent in the default media type
+		data.IOData.Set(constants.ResponseMediaType, encoder.DefaultMediaType)
+		rc, _ := data.ExecContext.Get(constants.RequestContext)
+		logger.Warning(fmt.Sprintf("None of the media types %s is acceptable for %s", accept, resource), rc)
+		return &constants.AppError{
+			Code:             constants.NotAcceptableErrorCode,
+			Message:          "Response can not be sent in any of the acceptable media types",
+			DeveloperMessage: fmt.Sprintf("Accept : %s, Available : %s", accept,
+				strings.Join(encoder.Available(allowed), constants.FieldSeperator)),
+		}
+	}
+	data.IOData.Set(constants.ResponseMediaType, mediaType)
+	return nil
+}
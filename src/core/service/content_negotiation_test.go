@@ -0,0 +1,36 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/encoder"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+func TestNotAcceptable(t *testing.T) {
+	negotiate := func(apiVersion *versionmanager.Version) *constants.AppError {
+		data := workflow.WorkFlowData{}
+		data.Create(new(workflow.WorkFlowIOInMemoryImpl), new(workflow.WorkFlowECInMemoryImpl))
+		data.IOData.Set(constants.Request, &utilhttp.Request{Headers: utilhttp.RequestHeader{Accept: "image/png"}})
+		return negotiateMediaType(data, "ITEM", apiVersion)
+	}
+
+	// all the registered media types are available to the apis not restricting them
+	appError := negotiate(&versionmanager.Version{})
+	if appError == nil || appError.Code != constants.NotAcceptableErrorCode {
+		t.Fatalf("Unacceptable media type got %v", appError)
+	}
+	if !strings.HasSuffix(appError.DeveloperMessage, "Available : "+strings.Join(encoder.Available(nil), ",")) ||
+		!strings.Contains(appError.DeveloperMessage, encoder.DefaultMediaType) {
+		t.Errorf("Unexpected developer message %s", appError.DeveloperMessage)
+	}
+
+	appError = negotiate(&versionmanager.Version{MediaTypes: []string{encoder.MediaTypeXML}})
+	if appError == nil || !strings.HasSuffix(appError.DeveloperMessage, "Available : "+encoder.MediaTypeXML) {
+		t.Errorf("Unacceptable media type of an api restricting them got %v", appError)
+	}
+}
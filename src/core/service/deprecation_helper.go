@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	"github.com/jabong/florest-core/src/common/monitor"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// checkDeprecation sets the deprecation details of the served version in the IO data for the response
+// headers, counts the hit and returns an error if the served version is past its sunset and has to be rejected.
+// The error names the version requested, the served version being the one it fell back to if they differ
+func checkDeprecation(data workflow.WorkFlowData, resource string, requestedVersion string, version string,
+	action string, orchBucket string, pathParams string) *constants.AppError {
+	deprecation, err := versionmanager.GetDeprecation(resource, version, action, orchBucket, pathParams)
+	if err != nil || deprecation == nil {
+		return nil
+	}
+	data.IOData.Set(constants.Deprecation, deprecation)
+
+	rcData, _ := data.ExecContext.Get(constants.RequestContext)
+	rc, _ := rcData.(utilhttp.RequestContext)
+	logger.Warning(fmt.Sprintf("Deprecated api %s_%s_%s_%s called by client app %s", action, version,
+		resource, orchBucket, rc.ClientAppID), rc)
+
+	tags := []string{fmt.Sprintf("client_app_id:%s", rc.ClientAppID)}
+	dderr := monitor.GetInstance().Count(
+		fmt.Sprintf("%v_%v_%v_%v_deprecated_count", action, version, resource, orchBucket), 1, tags, 1)
+	if dderr != nil {
+		logger.Error(fmt.Sprintln("Monitoring Error ", dderr.Error()), rc)
+	}
+
+	if deprecation.RejectAfterSunset && deprecation.IsSunset(time.Now()) {
+		developerMessage := fmt.Sprintf("Sunset on %s, use %s", deprecation.Sunset.Format(time.RFC3339),
+			deprecation.Link)
+		if requestedVersion != version {
+			developerMessage = fmt.Sprintf("Served by version %s, sunset on %s, use %s", version,
+				deprecation.Sunset.Format(time.RFC3339), deprecation.Link)
+		}
+		return &constants.AppError{
+			Code:             constants.APISunsetErrorCode,
+			Message:          fmt.Sprintf("Version %s of %s is no longer available", requestedVersion, resource),
+			DeveloperMessage: developerMessage,
+		}
+	}
+	return nil
+}
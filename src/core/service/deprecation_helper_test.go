@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// newDeprecationData returns the data of a request of the client app
+func newDeprecationData(clientAppID string) workflow.WorkFlowData {
+	data := workflow.WorkFlowData{}
+	data.Create(new(workflow.WorkFlowIOInMemoryImpl), new(workflow.WorkFlowECInMemoryImpl))
+	data.ExecContext.Set(constants.RequestContext, utilhttp.RequestContext{ClientAppID: clientAppID})
+	return data
+}
+
+func TestCheckDeprecation(t *testing.T) {
+	defer Reset()
+	metrics := listenMetrics(t)
+	now := time.Now()
+	deprecations := map[string]*versionmanager.Deprecation{
+		"V1": {Date: now.Add(-48 * time.Hour), Sunset: now.Add(-time.Hour), Link: "/v3/item", RejectAfterSunset: true},
+		"V2": {Date: now.Add(-48 * time.Hour), Sunset: now.Add(time.Hour), Link: "/v3/item", RejectAfterSunset: true},
+		"V3": nil,
+		"V4": {Sunset: now.Add(-time.Hour), Link: "/v3/item"},
+	}
+	vmap := versionmanager.VersionMap{}
+	for version, deprecation := range deprecations {
+		param := versionmanager.NewParam()
+		param.Update("", newResultOrchestrator(version), nil)
+		if deprecation != nil {
+			param.SetDeprecation("", deprecation)
+		}
+		vmap[versionmanager.BasicVersion{Resource: "ITEM", Version: version, Action: "GET",
+			BucketID: constants.OrchestratorBucketDefaultValue}] = param
+	}
+	versionmanager.Initialize(vmap)
+
+	checkServed := func(version string, servedVersion string) (workflow.WorkFlowData, *constants.AppError) {
+		data := newDeprecationData("app1")
+		return data, checkDeprecation(data, "ITEM", version, servedVersion, "GET",
+			constants.OrchestratorBucketDefaultValue, "")
+	}
+	check := func(version string) (workflow.WorkFlowData, *constants.AppError) {
+		return checkServed(version, version)
+	}
+
+	// past the sunset the calls are rejected with 410
+	data, appErr := check("V1")
+	if appErr == nil || appErr.Code != constants.APISunsetErrorCode {
+		t.Fatalf("Call after the sunset got %v", appErr)
+	}
+	status := constants.GetAppHTTPError(constants.AppErrors{Errors: []constants.AppError{*appErr}})
+	if status.HTTPStatusCode != 410 {
+		t.Errorf("Call after the sunset answered with %d, want 410", status.HTTPStatusCode)
+	}
+	if d, _ := data.IOData.Get(constants.Deprecation); d != deprecations["V1"] {
+		t.Errorf("Deprecation of the response %v", d)
+	}
+	if appErr.Message != "Version V1 of ITEM is no longer available" {
+		t.Errorf("Unexpected message of the call after the sunset, %s", appErr.Message)
+	}
+	// the error of a call falling back to a version past its sunset names the version requested
+	_, appErr = checkServed("V5", "V1")
+	if appErr == nil || appErr.Message != "Version V5 of ITEM is no longer available" ||
+		!strings.HasPrefix(appErr.DeveloperMessage, "Served by version V1") {
+		t.Errorf("Call falling back after the sunset got %v", appErr)
+	}
+
+	data, appErr = check("V2")
+	if d, _ := data.IOData.Get(constants.Deprecation); appErr != nil || d != deprecations["V2"] {
+		t.Errorf("Call before the sunset got %v, deprecation %v", appErr, d)
+	}
+	data, appErr = check("V3")
+	if d, _ := data.IOData.Get(constants.Deprecation); appErr != nil || d != nil {
+		t.Errorf("Call of a version not deprecated got %v, deprecation %v", appErr, d)
+	}
+	// the calls are still served past the sunset unless rejected
+	if _, appErr = check("V4"); appErr != nil {
+		t.Errorf("Call after the sunset not rejected got %v", appErr)
+	}
+	if _, appErr = check("V9"); appErr != nil {
+		t.Errorf("Call of an unknown version got %v", appErr)
+	}
+
+	counts := make(map[string]int)
+	for _, name := range metrics() {
+		counts[name]++
+	}
+	metric := "GET_%s_ITEM_" + constants.OrchestratorBucketDefaultValue + "_deprecated_count"
+	want := map[string]int{
+		fmt.Sprintf(metric, "V1"): 2,
+		fmt.Sprintf(metric, "V2"): 1,
+		fmt.Sprintf(metric, "V4"): 1,
+	}
+	if len(counts) != len(want) {
+		t.Errorf("Counted %v, want %v", counts, want)
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("Counted %s %d times, want %d", name, counts[name], n)
+		}
+	}
+}
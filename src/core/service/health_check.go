@@ -0,0 +1,84 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/logger"
+	"github.com/jabong/florest-core/src/components/cache"
+	"github.com/jabong/florest-core/src/components/mongodb"
+	"github.com/jabong/florest-core/src/components/sqldb"
+	"github.com/jabong/florest-core/src/core/common/utils/healthcheck"
+)
+
+// Defaults of the liveness and readiness checks
+const (
+	defaultLivePath      = "/live"
+	defaultReadyPath     = "/ready"
+	defaultDiskMinFreeMB = 100
+)
+
+// initProbes sets the options of the liveness and readiness checks and adds the readiness checks of the
+// registered sql dbs, mongodbs and caches and of the disks of the log paths
+func initProbes() {
+	conf := config.GlobalAppConfig.HealthCheck
+	healthcheck.SetDefaultOptions(healthcheck.CheckOptions{
+		Timeout:  time.Duration(conf.TimeoutInMs) * time.Millisecond,
+		CacheTTL: time.Duration(conf.CacheTTLInMs) * time.Millisecond,
+	})
+	if conf.DisableBuiltInChecks {
+		return
+	}
+	for _, key := range sqldb.Keys() {
+		healthcheck.AddReadinessCheck(healthcheck.NewSQLDBChecker(key), healthcheck.CheckOptions{})
+	}
+	for _, key := range mongodb.Keys() {
+		healthcheck.AddReadinessCheck(healthcheck.NewMongoDBChecker(key), healthcheck.CheckOptions{})
+	}
+	for _, key := range cache.Keys() {
+		healthcheck.AddReadinessCheck(healthcheck.NewCacheChecker(key), healthcheck.CheckOptions{})
+	}
+	minFreeMB := conf.DiskMinFreeMB
+	if minFreeMB <= 0 {
+		minFreeMB = defaultDiskMinFreeMB
+	}
+	for _, path := range logger.GetLogPaths() {
+		healthcheck.AddReadinessCheck(healthcheck.NewDiskChecker(path, uint64(minFreeMB)<<20),
+			healthcheck.CheckOptions{})
+	}
+}
+
+// handleProbes serves the liveness and readiness checks on their paths of the mux
+func handleProbes(mux *http.ServeMux) {
+	conf := config.GlobalAppConfig.HealthCheck
+	livePath, readyPath := conf.LivePath, conf.ReadyPath
+	if livePath == "" {
+		livePath = defaultLivePath
+	}
+	if readyPath == "" {
+		readyPath = defaultReadyPath
+	}
+	mux.HandleFunc(livePath, makeProbeHandler(healthcheck.Live))
+	mux.HandleFunc(readyPath, makeProbeHandler(healthcheck.Ready))
+}
+
+// makeProbeHandler writes the report of the checks with 200 if up and 503 otherwise
+func makeProbeHandler(run func() healthcheck.Report) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := run()
+		status := http.StatusOK
+		if report.Status != healthcheck.StatusUp {
+			status = http.StatusServiceUnavailable
+			logger.Warning(fmt.Sprintf("%s is %s. Checks : %+v", r.URL.Path, report.Status, report.Checks))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		if r.Method != http.MethodHead {
+			json.NewEncoder(w).Encode(report)
+		}
+	}
+}
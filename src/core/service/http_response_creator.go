@@ -1,18 +1,21 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
 
-	"encoding/json"
+	"github.com/jabong/florest-core/src/common/config"
 	"github.com/jabong/florest-core/src/common/constants"
 	"github.com/jabong/florest-core/src/common/logger"
 	"github.com/jabong/florest-core/src/common/monitor"
 	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
 	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/encoder"
 )
 
 type HTTPResponseCreator struct {
-	id string
+	id            string
+	errorResponse config.ErrorResponseConfig
 }
 
 func (n HTTPResponseCreator) Name() string {
@@ -27,6 +30,15 @@ func (n HTTPResponseCreator) GetID() (id string, err error) {
 	return n.id, nil
 }
 
+// SetErrorResponse sets the app level config of the error responses
+func (n *HTTPResponseCreator) SetErrorResponse(conf config.ErrorResponseConfig) error {
+	if err := validateErrorFormat(conf.Format); err != nil {
+		return err
+	}
+	n.errorResponse = conf
+	return nil
+}
+
 func (n *HTTPResponseCreator) Execute(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
 
 	rc, _ := data.ExecContext.Get(constants.RequestContext)
@@ -35,22 +47,7 @@ func (n *HTTPResponseCreator) Execute(data workflow.WorkFlowData) (workflow.Work
 	resStatus, _ := data.IOData.Get(constants.APPError)
 	resData, _ := data.IOData.Get(constants.ResponseData)
 
-	appError := new(constants.AppErrors)
-
-	if resStatus != nil {
-		if v, ok := resStatus.(*constants.AppError); ok {
-			if v != nil { //if v is of type *AppError and is not nil
-				appError.Errors = []constants.AppError{*v}
-			}
-		} else if v, ok := resStatus.(*constants.AppErrors); ok {
-			if v != nil { //v is of type *AppErrors and is not nil
-				appError = v
-			}
-		} else {
-			appError.Errors = []constants.AppError{constants.AppError{Code: constants.InvalidErrorCode,
-				Message: "Invalid App error"}}
-		}
-	}
+	appError := getAppErrors(resStatus)
 	status := constants.GetAppHTTPError(*appError)
 	debugData, _ := data.ExecContext.GetDebugMsg()
 
@@ -59,11 +56,18 @@ func (n *HTTPResponseCreator) Execute(data workflow.WorkFlowData) (workflow.Work
 	serviceStatusKey := fmt.Sprintf("%v_%v_%v_%v_%v_%vHttp_%v", action,
 		version, resource, orchBucket, pathParams, getCustomMetricPrefix(data), status.HTTPStatusCode)
 
-	if status.HTTPStatusCode != constants.HTTPStatusSuccessCode {
-		logger.Error(fmt.Sprintf("%s_%v Application Errors : %v", resource, status.HTTPStatusCode, appError), rc)
+	tags := getBucketTags(data)
+	if !status.Success {
+		category := constants.GetErrorCategory(status.HTTPStatusCode)
+		tags = append(tags, fmt.Sprintf("error_category:%s", category))
+		if category == constants.ClientErrorCategory {
+			logger.Warning(fmt.Sprintf("%s_%v Application Errors : %v", resource, status.HTTPStatusCode, appError), rc)
+		} else {
+			logger.Error(fmt.Sprintf("%s_%v Application Errors : %v", resource, status.HTTPStatusCode, appError), rc)
+		}
 	}
 
-	dderr := monitor.GetInstance().Count(serviceStatusKey, 1, nil, 1)
+	dderr := monitor.GetInstance().Count(serviceStatusKey, 1, tags, 1)
 	if dderr != nil {
 		logger.Error(fmt.Sprintln("Monitoring Error ", dderr.Error()), rc)
 	}
@@ -79,17 +83,93 @@ func (n *HTTPResponseCreator) Execute(data workflow.WorkFlowData) (workflow.Work
 	md, _ := m.(*utilhttp.ResponseMetaData)
 	appResponse := utilhttp.Response{Status: *status, Data: resData, DebugData: appDebugData, MetaData: md}
 	data.IOData.Set(constants.Response, appResponse)
-	jsonBody, err := json.Marshal(appResponse)
-	if err != nil {
-		return data, err
+	if stream := getStartedStream(data); stream != nil {
+		// the status and the headers have been sent, the errors can only be appended to the stream
+		if !status.Success {
+			if err := stream.WriteError(*status); err != nil {
+				logger.Warning(fmt.Sprintf("Failed to stream the errors of %s. Err : %s", resource, err), rc)
+			}
+		}
+		logger.Info(fmt.Sprintln("exiting ", n.Name()), rc)
+		return data, nil
 	}
 	r, _ := data.IOData.Get(constants.APIResponse)
 	apiResponse, _ := r.(utilhttp.APIResponse)
+	if record := getReplayedResponse(data); record != nil && status.Success {
+		setReplayedResponse(&apiResponse, record)
+		data.IOData.Set(constants.APIResponse, apiResponse)
+		logger.Info(fmt.Sprintln("exiting ", n.Name()), rc)
+		return data, nil
+	}
+	if cached := getCachedResponse(data); cached != nil && status.Success {
+		setCachedResponse(&apiResponse, cached)
+		answerNotModified(data, &apiResponse)
+		data.IOData.Set(constants.APIResponse, apiResponse)
+		logger.Info(fmt.Sprintln("exiting ", n.Name()), rc)
+		return data, nil
+	}
+	var body []byte
+	var err error
+	if !status.Success && len(status.Errors) > 0 &&
+		getErrorFormat(data, n.errorResponse.Format) == ErrorFormatProblem {
+		body, err = json.Marshal(newProblem(data, status, n.errorResponse))
+		if apiResponse.Headers == nil {
+			apiResponse.Headers = make(map[string]string)
+		}
+		apiResponse.Headers["Content-Type"] = ProblemMediaType
+	} else {
+		body, err = encodeResponse(data, appResponse)
+	}
+	if err != nil {
+		return data, err
+	}
 	apiResponse.HTTPStatus = appResponse.Status.HTTPStatusCode
-	apiResponse.Body = jsonBody
+	apiResponse.Body = body
+	if status.Success {
+		setValidatorHeaders(data, &apiResponse, resData)
+		cacheResponse(data, apiResponse)
+		answerNotModified(data, &apiResponse)
+	}
+	storeIdempotentResponse(data, apiResponse)
 	data.IOData.Set(constants.APIResponse, apiResponse)
 
 	logger.Info(fmt.Sprintln("exiting ", n.Name()), rc)
 
 	return data, nil
 }
+
+// getAppErrors returns the app errors set as the outcome of a request
+func getAppErrors(resStatus interface{}) *constants.AppErrors {
+	appError := new(constants.AppErrors)
+
+	if resStatus != nil {
+		if v, ok := resStatus.(*constants.AppError); ok {
+			if v != nil { //if v is of type *AppError and is not nil
+				appError.Errors = []constants.AppError{*v}
+			}
+		} else if v, ok := resStatus.(*constants.AppErrors); ok {
+			if v != nil { //v is of type *AppErrors and is not nil
+				appError = v
+			}
+		} else {
+			appError.Errors = []constants.AppError{constants.AppError{Code: constants.InvalidErrorCode,
+				Message: "Invalid App error"}}
+		}
+	}
+	return appError
+}
+
+// encodeResponse encodes the response in the negotiated media type, json if none is negotiated
+func encodeResponse(data workflow.WorkFlowData, appResponse utilhttp.Response) ([]byte, error) {
+	mediaType := encoder.DefaultMediaType
+	if m, _ := data.IOData.Get(constants.ResponseMediaType); m != nil {
+		if v, ok := m.(string); ok && v != "" {
+			mediaType = v
+		}
+	}
+	e, found := encoder.Get(mediaType)
+	if !found {
+		return nil, fmt.Errorf("Encoder not registered for media type %s", mediaType)
+	}
+	return e.Encode(appResponse)
+}
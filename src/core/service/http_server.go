@@ -0,0 +1,220 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/logger"
+)
+
+// unixSocketPrefix marks the addresses which are unix domain sockets
+const unixSocketPrefix = "unix:"
+
+// tlsVersions are the TLS versions by the names used in the config
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// clientAuthTypes are the client certificate policies by the names used in the config
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"None":             tls.NoClientCert,
+	"Request":          tls.RequestClientCert,
+	"Require":          tls.RequireAnyClientCert,
+	"VerifyIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerify": tls.RequireAndVerifyClientCert,
+}
+
+// tlsLoader loads the TLS config of the web server from the config files, keeping the last loaded one
+// which is served to the clients
+type tlsLoader struct {
+	conf    config.TLSConfig
+	http2   bool
+	current atomic.Value
+}
+
+// newHTTPServer returns the web server serving the handler as per the server config
+func newHTTPServer(handler http.Handler, conf config.ServerConfig) *http.Server {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadTimeout:       time.Duration(conf.ReadTimeoutInMs) * time.Millisecond,
+		ReadHeaderTimeout: time.Duration(conf.ReadHeaderTimeoutInMs) * time.Millisecond,
+		WriteTimeout:      time.Duration(conf.WriteTimeoutInMs) * time.Millisecond,
+		IdleTimeout:       time.Duration(conf.IdleTimeoutInMs) * time.Millisecond,
+		MaxHeaderBytes:    conf.MaxHeaderBytes,
+	}
+	if !conf.HTTP2 {
+		// a non nil map keeps net/http from setting up HTTP/2
+		srv.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+	return srv
+}
+
+// getServerAddresses returns the addresses the web server listens on - the server port followed by the
+// configured addresses
+func getServerAddresses() []string {
+	var addresses []string
+	if config.GlobalAppConfig.ServerPort != "" || len(config.GlobalAppConfig.Server.Addresses) == 0 {
+		addresses = append(addresses, ":"+config.GlobalAppConfig.ServerPort)
+	}
+	return append(addresses, config.GlobalAppConfig.Server.Addresses...)
+}
+
+// serveHTTP serves the handler on all the addresses of the web server, reloading the TLS config on SIGHUP.
+// Returns once any of the listeners fails
+func serveHTTP(handler http.Handler) error {
+	conf := config.GlobalAppConfig.Server
+	srv := newHTTPServer(handler, conf)
+	var loader *tlsLoader
+	if conf.TLS != nil {
+		loader = &tlsLoader{conf: *conf.TLS, http2: conf.HTTP2}
+		if err := loader.load(); err != nil {
+			return err
+		}
+		go loader.reloadOnSignal()
+	}
+
+	addresses := getServerAddresses()
+	listeners := make([]net.Listener, 0, len(addresses))
+	for _, address := range addresses {
+		l, err := listen(address)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return err
+		}
+		if loader != nil && !strings.HasPrefix(address, unixSocketPrefix) {
+			l = tls.NewListener(l, loader.tlsConfig())
+		}
+		listeners = append(listeners, l)
+	}
+
+	errs := make(chan error, len(listeners))
+	for i, l := range listeners {
+		logger.Info(fmt.Sprintf("Web server listening on %s", addresses[i]))
+		go func(l net.Listener) {
+			errs <- srv.Serve(l)
+		}(l)
+	}
+	err := <-errs
+	srv.Close()
+	return err
+}
+
+// listen listens on a tcp address or a unix domain socket, removing the socket file left by a previous run
+func listen(address string) (net.Listener, error) {
+	if !strings.HasPrefix(address, unixSocketPrefix) {
+		return net.Listen("tcp", address)
+	}
+	path := strings.TrimPrefix(address, unixSocketPrefix)
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// tlsConfig returns the TLS config of the listeners, handing over the last loaded config to each client
+func (t *tlsLoader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return t.current.Load().(*tls.Config), nil
+		},
+	}
+}
+
+// load loads the certificate, the key and the client CAs from their files
+func (t *tlsLoader) load() error {
+	cert, err := tls.LoadX509KeyPair(t.conf.CertFile, t.conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("Failed to load certificate. Err : %s", err)
+	}
+	tlsConf := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if t.http2 {
+		tlsConf.NextProtos = []string{"h2", "http/1.1"}
+	} else {
+		tlsConf.NextProtos = []string{"http/1.1"}
+	}
+	if t.conf.MinVersion != "" {
+		v, ok := tlsVersions[t.conf.MinVersion]
+		if !ok {
+			return fmt.Errorf("Invalid TLS version %s", t.conf.MinVersion)
+		}
+		tlsConf.MinVersion = v
+	}
+	if len(t.conf.CipherSuites) > 0 {
+		if tlsConf.CipherSuites, err = getCipherSuites(t.conf.CipherSuites); err != nil {
+			return err
+		}
+	}
+	if t.conf.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(t.conf.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("Failed to load client CAs. Err : %s", err)
+		}
+		tlsConf.ClientCAs = x509.NewCertPool()
+		if !tlsConf.ClientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("No client CA found in %s", t.conf.ClientCAFile)
+		}
+		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if t.conf.ClientAuth != "" {
+		clientAuth, ok := clientAuthTypes[t.conf.ClientAuth]
+		if !ok {
+			return fmt.Errorf("Invalid client auth %s", t.conf.ClientAuth)
+		}
+		if clientAuth >= tls.VerifyClientCertIfGiven && tlsConf.ClientCAs == nil {
+			return errors.New("Client CAs are required to verify the client certificates")
+		}
+		tlsConf.ClientAuth = clientAuth
+	}
+	t.current.Store(tlsConf)
+	return nil
+}
+
+// reloadOnSignal reloads the TLS config on SIGHUP. The clients keep getting the previous config if the
+// reload fails
+func (t *tlsLoader) reloadOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		if err := t.load(); err != nil {
+			logger.Error(fmt.Sprintf("Failed to reload TLS config. Err : %s", err))
+			continue
+		}
+		logger.Info("TLS config reloaded")
+	}
+}
+
+// getCipherSuites returns the ids of the cipher suites by their names
+func getCipherSuites(names []string) ([]uint16, error) {
+	suites := make(map[string]uint16)
+	for _, s := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites[s.Name] = s.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("Invalid cipher suite %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+)
+
+// writeTestCertificate writes a self signed certificate and its key to the directory, returning their files
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate the key. Err : %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "florest"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create the certificate. Err : %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode the key. Err : %s", err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func newTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "florest")
+	if err != nil {
+		t.Fatalf("Failed to create a temporary directory. Err : %s", err)
+	}
+	return dir
+}
+
+func TestGetCipherSuites(t *testing.T) {
+	ids, err := getCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_RSA_WITH_RC4_128_SHA"})
+	expected := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_RC4_128_SHA}
+	if err != nil || !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v. Err : %v", expected, ids, err)
+	}
+	if _, err := getCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_UNKNOWN"}); err == nil {
+		t.Error("Unknown cipher suite accepted")
+	}
+}
+
+func TestTLSLoaderLoad(t *testing.T) {
+	dir := newTempDir(t)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	loader := &tlsLoader{conf: config.TLSConfig{CertFile: certFile, KeyFile: keyFile}, http2: true}
+	if err := loader.load(); err != nil {
+		t.Fatalf("Failed to load the TLS config. Err : %s", err)
+	}
+	conf := loader.current.Load().(*tls.Config)
+	if conf.MinVersion != tls.VersionTLS12 || conf.ClientAuth != tls.NoClientCert ||
+		!reflect.DeepEqual(conf.NextProtos, []string{"h2", "http/1.1"}) {
+		t.Errorf("Unexpected default TLS config %+v", conf)
+	}
+	served, err := loader.tlsConfig().GetConfigForClient(nil)
+	if err != nil || served != conf {
+		t.Errorf("Clients not served the loaded config. Err : %v", err)
+	}
+
+	// the client CAs default to verifying the client certificates
+	loader.conf.ClientCAFile = certFile
+	loader.conf.MinVersion = "1.3"
+	if err := loader.load(); err != nil {
+		t.Fatalf("Failed to load the TLS config. Err : %s", err)
+	}
+	conf = loader.current.Load().(*tls.Config)
+	if conf.ClientAuth != tls.RequireAndVerifyClientCert || conf.ClientCAs == nil ||
+		conf.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Unexpected TLS config with client CAs %+v", conf)
+	}
+	loader.conf.ClientAuth = "Request"
+	if err := loader.load(); err != nil || loader.current.Load().(*tls.Config).ClientAuth != tls.RequestClientCert {
+		t.Errorf("Client auth not overridden. Err : %v", err)
+	}
+
+	invalid := map[string]config.TLSConfig{
+		"bad min version":      {CertFile: certFile, KeyFile: keyFile, MinVersion: "1.4"},
+		"bad client auth":      {CertFile: certFile, KeyFile: keyFile, ClientAuth: "Always"},
+		"verify without CAs":   {CertFile: certFile, KeyFile: keyFile, ClientAuth: "VerifyIfGiven"},
+		"require without CAs":  {CertFile: certFile, KeyFile: keyFile, ClientAuth: "RequireAndVerify"},
+		"bad cipher suite":     {CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_UNKNOWN"}},
+		"missing certificate":  {CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile},
+		"missing client CAs":   {CertFile: certFile, KeyFile: keyFile, ClientCAFile: filepath.Join(dir, "missing.pem")},
+		"client CAs not a PEM": {CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile},
+	}
+	for name, conf := range invalid {
+		before := loader.current.Load()
+		l := &tlsLoader{conf: conf}
+		l.current.Store(before)
+		if err := l.load(); err == nil {
+			t.Errorf("TLS config with %s loaded", name)
+		}
+		if l.current.Load() != before {
+			t.Errorf("TLS config with %s replaced the loaded config", name)
+		}
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	dir := newTempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.sock")
+
+	l, err := listen(unixSocketPrefix + path)
+	if err != nil {
+		t.Fatalf("Failed to listen on the socket. Err : %s", err)
+	}
+	if l.Addr().Network() != "unix" {
+		t.Errorf("Expected a unix listener, got %s", l.Addr().Network())
+	}
+	// a socket file left by a previous run is removed
+	if f, ok := l.(*net.UnixListener); ok {
+		f.SetUnlinkOnClose(false)
+	}
+	l.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Socket file not left. Err : %s", err)
+	}
+	l, err = listen(unixSocketPrefix + path)
+	if err != nil {
+		t.Fatalf("Failed to listen on the socket left by a previous run. Err : %s", err)
+	}
+	l.Close()
+
+	// a file which is not a socket is not removed
+	file := filepath.Join(dir, "app.txt")
+	ioutil.WriteFile(file, nil, 0600)
+	if _, err := listen(unixSocketPrefix + file); err == nil {
+		t.Error("Listened on a regular file")
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("Regular file removed. Err : %s", err)
+	}
+}
+
+func TestGetServerAddresses(t *testing.T) {
+	defer Reset()
+	cases := []struct {
+		port      string
+		addresses []string
+		expected  []string
+	}{
+		{"8080", nil, []string{":8080"}},
+		{"", nil, []string{":"}},
+		{"8080", []string{"127.0.0.1:8443", "unix:/tmp/app.sock"}, []string{":8080", "127.0.0.1:8443", "unix:/tmp/app.sock"}},
+		{"", []string{"127.0.0.1:8443"}, []string{"127.0.0.1:8443"}},
+	}
+	for _, c := range cases {
+		config.GlobalAppConfig = &config.AppConfig{ServerPort: c.port, Server: config.ServerConfig{Addresses: c.addresses}}
+		if got := getServerAddresses(); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected %v for port %s and addresses %v, got %v", c.expected, c.port, c.addresses, got)
+		}
+	}
+}
+
+func TestNewHTTPServer(t *testing.T) {
+	srv := newHTTPServer(nil, config.ServerConfig{ReadTimeoutInMs: 1000, IdleTimeoutInMs: 2000, MaxHeaderBytes: 1024})
+	if srv.ReadTimeout != time.Second || srv.IdleTimeout != 2*time.Second || srv.MaxHeaderBytes != 1024 ||
+		srv.TLSNextProto == nil {
+		t.Errorf("Unexpected server %+v", srv)
+	}
+	if srv := newHTTPServer(nil, config.ServerConfig{HTTP2: true}); srv.TLSNextProto != nil {
+		t.Error("HTTP/2 disabled")
+	}
+}
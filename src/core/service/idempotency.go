@@ -0,0 +1,290 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	"github.com/jabong/florest-core/src/components/cache"
+	"github.com/jabong/florest-core/src/components/sqldb"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/idempotency"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// Defaults of the idempotency
+const (
+	defaultIdempotencyTTL         = 24 * time.Hour
+	defaultIdempotencySQLTable    = "idempotency_keys"
+	defaultIdempotencyMaxBodySize = 32 << 20
+)
+
+// maxIdempotencyKeyLength is the maximum length of an Idempotency-Key
+const maxIdempotencyKeyLength = 255
+
+// idempotencyKeyPrefix namespaces the keys of the responses stored in a cache of the cache component
+const idempotencyKeyPrefix = ":idempotency:"
+
+// idempotentReplayedHeader marks the responses replayed to the retries of a request
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyStore stores the responses of the requests made with an idempotency key
+var idempotencyStore idempotency.Store
+
+// idempotentRequest is a request made with an idempotency key whose response is stored once served
+type idempotentRequest struct {
+	key      string
+	bodyHash string
+	ttl      time.Duration
+	done     bool
+}
+
+// InitIdempotency creates the store of the responses of the idempotent apis as per the idempotency config
+func InitIdempotency() {
+	conf := config.GlobalAppConfig.Idempotency
+	switch {
+	case conf.CacheKey != "":
+		c, err := cache.Get(conf.CacheKey)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Invalid idempotency cache key %s. Err : %s", conf.CacheKey, err))
+			panic(fmt.Sprintf("Invalid idempotency cache key %s. Err : %s", conf.CacheKey, err))
+		}
+		idempotencyStore = idempotency.NewCacheStore(c, config.GlobalAppConfig.AppName+idempotencyKeyPrefix)
+	case conf.SQLKey != "":
+		db, err := sqldb.Get(conf.SQLKey)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Invalid idempotency sql key %s. Err : %s", conf.SQLKey, err))
+			panic(fmt.Sprintf("Invalid idempotency sql key %s. Err : %s", conf.SQLKey, err))
+		}
+		table := conf.SQLTable
+		if table == "" {
+			table = defaultIdempotencySQLTable
+		}
+		idempotencyStore = idempotency.NewSQLStore(db, table)
+	default:
+		idempotencyStore = idempotency.NewMemoryStore()
+	}
+}
+
+// checkIdempotency begins the POST and PATCH requests of the idempotent apis made with an Idempotency-Key.
+// The retries of a request served get its response replayed, returning true. The retries of a request in
+// flight are rejected with 409 and the requests reusing a key with a different body or upload with 422
+func checkIdempotency(data workflow.WorkFlowData, req *utilhttp.Request, apiVersion *versionmanager.Version,
+	pathParams string) (bool, *constants.AppError) {
+	if idempotencyStore == nil || req == nil || apiVersion == nil || apiVersion.Idempotency == nil ||
+		(req.HTTPVerb != utilhttp.POST && req.HTTPVerb != utilhttp.PATCH) {
+		return false, nil
+	}
+	key := req.Headers.IdempotencyKey
+	if key == "" {
+		if apiVersion.Idempotency.Required {
+			return false, &constants.AppError{Code: constants.ParamsInSufficientErrorCode,
+				Message: fmt.Sprintf("%s header is required", utilhttp.IdempotencyKeyHeader)}
+		}
+		return false, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return false, &constants.AppError{Code: constants.ParamsInValidErrorCode,
+			Message: fmt.Sprintf("%s header is longer than %d characters", utilhttp.IdempotencyKeyHeader,
+				maxIdempotencyKeyLength)}
+	}
+
+	bodyHash, appError := hashRequestBody(data, req)
+	if appError != nil {
+		return false, appError
+	}
+	ttl := apiVersion.Idempotency.TTL
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	rc, _ := data.ExecContext.Get(constants.RequestContext)
+	storeKey := getIdempotencyKey(data, req, apiVersion, pathParams)
+	record, err := idempotencyStore.Begin(storeKey, bodyHash, ttl)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to begin idempotent request %s. Err : %s", storeKey, err), rc)
+		return false, &constants.AppError{Code: constants.ResourceErrorCode,
+			Message: "Idempotency of the request can not be ensured", DeveloperMessage: err.Error()}
+	}
+	switch {
+	case record == nil:
+		data.IOData.Set(constants.IdempotentRequest, &idempotentRequest{key: storeKey, bodyHash: bodyHash, ttl: ttl})
+		return false, nil
+	case record.BodyHash != bodyHash:
+		return false, &constants.AppError{Code: constants.IdempotencyKeyReusedErrorCode,
+			Message: fmt.Sprintf("%s is already used with a different request body", utilhttp.IdempotencyKeyHeader)}
+	case !record.Completed:
+		return false, &constants.AppError{Code: constants.ConflictErrorCode,
+			Message: fmt.Sprintf("A request with the %s is in progress", utilhttp.IdempotencyKeyHeader)}
+	}
+	logger.Info(fmt.Sprintf("Response of %s replayed", storeKey), rc)
+	data.IOData.Set(constants.ReplayedResponse, record)
+	return true, nil
+}
+
+// getIdempotencyKey returns the key of the request - the resource, version, action, path params and caller
+// followed by the Idempotency-Key. The caller is the client app and the user authenticated, else the user id
+// of the request context
+func getIdempotencyKey(data workflow.WorkFlowData, req *utilhttp.Request, apiVersion *versionmanager.Version,
+	pathParams string) string {
+	caller := getPrincipalKey(data)
+	if caller == "" {
+		v, _ := data.ExecContext.Get(constants.RequestContext)
+		if rc, ok := v.(utilhttp.RequestContext); ok {
+			caller = rc.UserID
+		}
+	}
+	return strings.Join([]string{apiVersion.Resource, apiVersion.Version, apiVersion.Action, pathParams,
+		caller, req.Headers.IdempotencyKey}, "/")
+}
+
+// hashRequestBody returns the hash of the request body, of the parsed parts for the uploads. The bodies larger
+// than the MaxBodySize of the idempotency config are rejected with 413
+func hashRequestBody(data workflow.WorkFlowData, req *utilhttp.Request) (string, *constants.AppError) {
+	if f, _ := data.IOData.Get(constants.UploadedFiles); f != nil {
+		v, _ := data.IOData.Get(constants.UploadedValues)
+		files, _ := f.(map[string][]*utilhttp.UploadedFile)
+		values, _ := v.(map[string][]string)
+		hash, err := hashUpload(files, values)
+		if err != nil {
+			return "", &constants.AppError{Code: constants.ResourceErrorCode, Message: "Failed to read the upload",
+				DeveloperMessage: err.Error()}
+		}
+		return hash, nil
+	}
+	if req.OriginalRequest == nil || req.OriginalRequest.Body == nil {
+		return idempotency.HashBody(nil), nil
+	}
+	limit := config.GlobalAppConfig.Idempotency.MaxBodySize
+	if limit <= 0 {
+		limit = defaultIdempotencyMaxBodySize
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.OriginalRequest.Body, limit+1))
+	req.OriginalRequest.Body.Close()
+	// the request gets back the body read so far even on an error
+	req.OriginalRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", &constants.AppError{Code: constants.IncorrectDataErrorCode, Message: "Invalid request body",
+			DeveloperMessage: err.Error()}
+	}
+	if int64(len(body)) > limit {
+		return "", &constants.AppError{Code: constants.PayloadTooLargeErrorCode,
+			Message: fmt.Sprintf("Request body is larger than %d bytes", limit)}
+	}
+	return idempotency.HashBody(body), nil
+}
+
+// hashUpload returns the hash of the values and the files of an upload - the field, name, content type, size
+// and content hash of each file
+func hashUpload(files map[string][]*utilhttp.UploadedFile, values map[string][]string) (string, error) {
+	var parts bytes.Buffer
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range values[name] {
+			fmt.Fprintf(&parts, "value %q %q\n", name, value)
+		}
+	}
+	fields := make([]string, 0, len(files))
+	for field := range files {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		for _, file := range files[field] {
+			content, err := file.Open()
+			if err != nil {
+				return "", err
+			}
+			h := sha256.New()
+			_, err = io.Copy(h, content)
+			content.Close()
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&parts, "file %q %q %q %d %x\n", field, file.FileName, file.ContentType, file.Size, h.Sum(nil))
+		}
+	}
+	return idempotency.HashBody(parts.Bytes()), nil
+}
+
+// getReplayedResponse returns the response replayed to the retry of a request, nil if not a retry
+func getReplayedResponse(data workflow.WorkFlowData) *idempotency.Record {
+	v, _ := data.IOData.Get(constants.ReplayedResponse)
+	record, _ := v.(*idempotency.Record)
+	return record
+}
+
+// setReplayedResponse sets the status, the body and the headers of the replayed response in the response,
+// along with the Idempotent-Replayed header
+func setReplayedResponse(apiResponse *utilhttp.APIResponse, record *idempotency.Record) {
+	if apiResponse.Headers == nil {
+		apiResponse.Headers = make(map[string]string)
+	}
+	for k, v := range record.Headers {
+		apiResponse.Headers[k] = v
+	}
+	apiResponse.Headers[idempotentReplayedHeader] = "true"
+	apiResponse.HTTPStatus = constants.HTTPCode(record.Status)
+	apiResponse.Body = record.Body
+}
+
+// storeIdempotentResponse stores the response of a request made with an idempotency key. The server errors
+// are not stored, the request being released to be retried
+func storeIdempotentResponse(data workflow.WorkFlowData, apiResponse utilhttp.APIResponse) {
+	v, _ := data.IOData.Get(constants.IdempotentRequest)
+	ir, ok := v.(*idempotentRequest)
+	if !ok || ir.done || apiResponse.HTTPStatus >= constants.HTTPStatusInternalServerErrorCode {
+		return
+	}
+	ir.done = true
+	record := &idempotency.Record{BodyHash: ir.bodyHash, Status: int(apiResponse.HTTPStatus),
+		Body: apiResponse.Body, Headers: make(map[string]string)}
+	for k, val := range apiResponse.Headers {
+		if !isRequestIDHeader(k) {
+			record.Headers[k] = val
+		}
+	}
+	if err := idempotencyStore.Complete(ir.key, record, ir.ttl); err != nil {
+		rc, _ := data.ExecContext.Get(constants.RequestContext)
+		logger.Error(fmt.Sprintf("Failed to store the response of idempotent request %s. Err : %s", ir.key, err),
+			rc)
+	}
+}
+
+// releaseIdempotentRequest releases the request made with an idempotency key whose response is not stored,
+// so that it can be retried
+func releaseIdempotentRequest(data *workflow.WorkFlowData) {
+	v, _ := data.IOData.Get(constants.IdempotentRequest)
+	ir, ok := v.(*idempotentRequest)
+	if !ok || ir.done {
+		return
+	}
+	ir.done = true
+	if err := idempotencyStore.Release(ir.key); err != nil {
+		rc, _ := data.ExecContext.Get(constants.RequestContext)
+		logger.Error(fmt.Sprintf("Failed to release idempotent request %s. Err : %s", ir.key, err), rc)
+	}
+}
+
+// isRequestIDHeader checks if the header carries the ids of the request, which are not replayed
+func isRequestIDHeader(name string) bool {
+	for _, h := range []string{utilhttp.CustomHeaderMap[utilhttp.RequestID],
+		utilhttp.CustomHeaderMap[utilhttp.TransactionID], utilhttp.TraceParentHeader, utilhttp.TraceStateHeader} {
+		if h != "" && strings.EqualFold(name, h) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/auth"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+func TestIdempotencyConditionalRetry(t *testing.T) {
+	revision := 1
+	get := testAPI{
+		version: versionmanager.Version{Resource: "ITEM", Action: "GET"},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			metaData(data).ETag = strconv.Itoa(revision)
+			return result(revision)(data)
+		},
+	}
+	patch := testAPI{
+		version: versionmanager.Version{Resource: "ITEM", Action: "PATCH",
+			Idempotency: new(versionmanager.Idempotency)},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			revision++
+			return result(revision)(data)
+		},
+	}
+	app := newApp(t, config.AppConfig{}, get, patch)
+
+	app.PATCH("/florest/v1/item/").Header("If-Match", `"1"`).Header("Idempotency-Key", "k1").Do().
+		AssertStatus(http.StatusOK).AssertData("", 2)
+	app.PATCH("/florest/v1/item/").Header("If-Match", `"1"`).Header("Idempotency-Key", "k1").Do().
+		AssertStatus(http.StatusOK).AssertHeader("Idempotent-Replayed", "true").AssertData("", 2)
+	app.PATCH("/florest/v1/item/").Header("If-Match", `"1"`).Header("Idempotency-Key", "k2").Do().
+		AssertStatus(http.StatusPreconditionFailed)
+	if revision != 2 {
+		t.Errorf("Expected the item to be patched once, revision %d", revision)
+	}
+}
+
+func TestIdempotencyKeyOfCaller(t *testing.T) {
+	var calls int
+	post := testAPI{
+		version: versionmanager.Version{Resource: "ORDER", Action: "POST",
+			Idempotency: new(versionmanager.Idempotency)},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			calls++
+			return result(calls)(data)
+		},
+	}
+	app := newApp(t, config.AppConfig{}, post)
+
+	app.POST("/florest/v1/order/").Header("USER_ID", "u1").Header("Idempotency-Key", "k").Do().
+		AssertData("", 1)
+	app.POST("/florest/v1/order/").Header("USER_ID", "u2").Header("Idempotency-Key", "k").Do().
+		AssertData("", 2)
+	app.POST("/florest/v1/order/").Header("USER_ID", "u1").Header("Idempotency-Key", "k").Do().
+		AssertHeader("Idempotent-Replayed", "true").AssertData("", 1)
+}
+
+func TestIdempotencyKeyOfPrincipal(t *testing.T) {
+	var calls int
+	store := auth.NewMemoryKeyStore([]auth.Key{{ID: "a", UserID: "u1"}, {ID: "b", UserID: "u2"}})
+	post := testAPI{
+		version: versionmanager.Version{Resource: "ORDER", Action: "POST",
+			Idempotency:    new(versionmanager.Idempotency),
+			Authenticators: []auth.Authenticator{auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{}, store)}},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			calls++
+			return result(calls)(data)
+		},
+	}
+	app := newApp(t, config.AppConfig{}, post)
+
+	// the user id header does not let a caller reach the responses of another
+	app.POST("/florest/v1/order/").Header("X-Api-Key", "a").Header("USER_ID", "u1").
+		Header("Idempotency-Key", "k").Do().AssertData("", 1)
+	app.POST("/florest/v1/order/").Header("X-Api-Key", "b").Header("USER_ID", "u1").
+		Header("Idempotency-Key", "k").Do().AssertData("", 2)
+	app.POST("/florest/v1/order/").Header("X-Api-Key", "a").Header("Idempotency-Key", "k").Do().
+		AssertHeader("Idempotent-Replayed", "true").AssertData("", 1)
+}
+
+func TestIdempotencyOfUpload(t *testing.T) {
+	var calls int
+	upload := testAPI{
+		version: versionmanager.Version{Resource: "DOC", Action: "POST", Upload: &utilhttp.UploadConfig{},
+			Idempotency: new(versionmanager.Idempotency)},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			calls++
+			return result(calls)(data)
+		},
+	}
+	app := newApp(t, config.AppConfig{}, upload)
+
+	app.POST("/florest/v1/doc/").Header("Idempotency-Key", "k").Body(uploadBody("hello")).Do().AssertData("", 1)
+	// the parts are hashed rather than the body, whose boundary changes with every upload
+	app.POST("/florest/v1/doc/").Header("Idempotency-Key", "k").Body(uploadBody("hello")).Do().
+		AssertHeader("Idempotent-Replayed", "true").AssertData("", 1)
+	app.POST("/florest/v1/doc/").Header("Idempotency-Key", "k").Body(uploadBody("world")).Do().
+		AssertStatus(http.StatusUnprocessableEntity).AssertError(constants.IdempotencyKeyReusedErrorCode)
+	if calls != 1 {
+		t.Errorf("Expected the upload to be served once, served %d times", calls)
+	}
+}
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	post := testAPI{
+		version: versionmanager.Version{Resource: "ORDER", Action: "POST",
+			Idempotency: new(versionmanager.Idempotency)},
+		execute: result("created"),
+	}
+	app := newApp(t, config.AppConfig{Idempotency: config.IdempotencyConfig{MaxBodySize: 8}}, post)
+
+	app.POST("/florest/v1/order/").Header("Idempotency-Key", "k1").Body([]byte("12345678"), "text/plain").Do().
+		AssertSuccess().AssertData("", "created")
+	app.POST("/florest/v1/order/").Header("Idempotency-Key", "k2").Body([]byte("123456789"), "text/plain").Do().
+		AssertStatus(http.StatusRequestEntityTooLarge).AssertError(constants.PayloadTooLargeErrorCode)
+	// the bodies of the requests without an Idempotency-Key are not limited
+	app.POST("/florest/v1/order/").Body([]byte("123456789"), "text/plain").Do().AssertSuccess()
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/jabong/florest-core/src/common/utils/http"
 	"github.com/jabong/florest-core/src/core/common/env"
 	"github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/binder"
 	"github.com/jabong/florest-core/src/core/common/utils/healthcheck"
 	"github.com/jabong/florest-core/src/core/common/utils/responseheaders"
 	"github.com/jabong/florest-core/src/core/common/versionmanager"
@@ -45,9 +46,39 @@ func (im InitManager) Execute() {
 	//Initialises Logger
 	initLogger()
 
+	im.initComponents()
+}
+
+// ExecuteWithConfig initialises the app like Execute, from the config instead of the config file. The
+// logger is initialised only if the LogConfFile of the config is set and the performance params are left
+// as is, e.g. for the tests
+func (im InitManager) ExecuteWithConfig(conf *config.AppConfig) {
+	env.GetOsEnviron()
+	config.GlobalAppConfig = conf
+	if conf.LogConfFile != "" {
+		initLogger()
+	}
+	im.initComponents()
+}
+
+// initComponents initialises the monitor, the tracer, the api pipelines and the other components of the
+// app once the config and the logger are initialised
+func (im InitManager) initComponents() {
 	// Initilalize Monitor
 	InitMonitor()
 
+	// Initialise the http status resolution of the errors
+	InitHTTPErrors()
+
+	// Initialise the tracer
+	InitTracer()
+
+	// Initialise the access log
+	InitAccessLog()
+
+	// Initialise the audit log of the authorization decisions
+	InitAuditLog()
+
 	// initialize profiler
 	initProfiler()
 
@@ -57,9 +88,18 @@ func (im InitManager) Execute() {
 	// Initialise http pooling
 	InitHTTPPool()
 
+	// Initialise the mirroring of requests to candidate buckets
+	InitShadowTraffic()
+
 	//Initializes custom api init functionality
 	InitCustomAPIInit()
 
+	// Initialise the response cache, its cache being registered by the custom api init
+	InitResponseCache()
+
+	// Initialise the store of the responses of the idempotent apis
+	InitIdempotency()
+
 	//Initialize Apis
 	InitApis()
 
@@ -104,6 +144,7 @@ func InitVersionManager() {
 	}
 
 	addAPIVersions(vmap)
+	versionmanager.SetFallbackChain(config.GlobalAppConfig.VersionFallbackChain)
 	versionmanager.Initialize(vmap)
 }
 
@@ -116,17 +157,43 @@ func InitCustomAPIInit() {
 }
 
 func addAPIVersions(vmap versionmanager.VersionMap) {
+	requestBinders = make(map[bindingKey]*binder.Binder)
 	for _, apiInstance := range apiList {
 		version := apiInstance.GetVersion()
+		b, err := getRequestBinder(apiInstance)
+		if err == nil {
+			err = validateErrorFormat(version.ErrorFormat)
+		}
+		if err == nil {
+			err = validateStreamFormat(version.Stream)
+		}
+		if err == nil && version.Authorization != nil {
+			err = version.Authorization.Compile()
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("Rejected API registration Resource: %s, Version: %s, Action: %s, BucketId: %s, Path: %s. Err : %s",
+				version.Resource, version.Version, version.Action, version.BucketID, version.Path, err.Error()))
+			continue
+		}
 		param := vmap[version.GetBasicVersion()]
 		if param == nil {
 			param = versionmanager.NewParam()
 			vmap[version.GetBasicVersion()] = param
 		}
 		rl := apiInstance.GetRateLimiter()
-		err := param.Update(version.Path, apiInstance.GetOrchestrator(), &rl)
+		err = param.Update(version.Path, apiInstance.GetOrchestrator(), &rl)
+		if err == nil {
+			err = param.SetVersion(version.Path, &version)
+		}
+		if err == nil && version.Deprecation != nil {
+			err = param.SetDeprecation(version.Path, version.Deprecation)
+		}
+		if err == nil && b != nil {
+			requestBinders[bindingKey{version.GetBasicVersion(), version.Path}] = b
+		}
 		if err != nil {
-			logger.Error("Path - " + version.Path + " is not valid. Err : " + err.Error())
+			logger.Error(fmt.Sprintf("Rejected API registration Resource: %s, Version: %s, Action: %s, BucketId: %s, Path: %s. Err : %s",
+				version.Resource, version.Version, version.Action, version.BucketID, version.Path, err.Error()))
 		}
 	}
 }
@@ -141,11 +208,29 @@ func createServiceOrchestrator() orchestrator.Orchestrator {
 	//Create and add execution node UriInterpreter
 	uriInterpreter := new(URIInterpreter)
 	uriInterpreter.SetID("1")
+	uriScheme, serr := getURIScheme(config.GlobalAppConfig.URIScheme)
+	if serr != nil {
+		logger.Error(fmt.Sprintln(serr))
+		panic(serr)
+	}
+	uriInterpreter.SetURIScheme(uriScheme)
 	uerr := serviceWorkflow.AddExecutionNode(uriInterpreter)
 	if uerr != nil {
 		logger.Error(fmt.Sprintln(uerr))
 	}
 
+	//Create and add execution node BucketAssigner
+	bucketAssigner := new(BucketAssigner)
+	bucketAssigner.SetID("2")
+	if eerr := bucketAssigner.SetExperiments(config.GlobalAppConfig.Experiments); eerr != nil {
+		logger.Error(fmt.Sprintln(eerr))
+		panic(eerr)
+	}
+	aerr := serviceWorkflow.AddExecutionNode(bucketAssigner)
+	if aerr != nil {
+		logger.Error(fmt.Sprintln(aerr))
+	}
+
 	//Create and add execution node BusinessLogicExecutor
 	businessLogicExecutor := new(BusinessLogicExecutor)
 	businessLogicExecutor.SetID("3")
@@ -163,13 +248,21 @@ func createServiceOrchestrator() orchestrator.Orchestrator {
 	//Create and add execution node HTTPResponseCreator
 	httpResponseCreator := new(HTTPResponseCreator)
 	httpResponseCreator.SetID("5")
+	if eerr := httpResponseCreator.SetErrorResponse(config.GlobalAppConfig.ErrorResponse); eerr != nil {
+		logger.Error(fmt.Sprintln(eerr))
+		panic(eerr)
+	}
 	herr := serviceWorkflow.AddExecutionNode(httpResponseCreator)
 	if herr != nil {
 		logger.Error(fmt.Sprintln(herr))
 	}
 
 	//Add the connections between the nodes
-	c1err := serviceWorkflow.AddConnection(uriInterpreter, businessLogicExecutor)
+	c0err := serviceWorkflow.AddConnection(uriInterpreter, bucketAssigner)
+	if c0err != nil {
+		logger.Error(fmt.Sprintln(c0err))
+	}
+	c1err := serviceWorkflow.AddConnection(bucketAssigner, businessLogicExecutor)
 	if c1err != nil {
 		logger.Error(fmt.Sprintln(c1err))
 	}
@@ -265,6 +358,7 @@ func InitHealthCheck() {
 		healthCheckArray[i] = apiInstance.GetHealthCheck()
 	}
 	healthcheck.Initialise(healthCheckArray)
+	initProbes()
 }
 
 // InitMonitor initializes the monitor
@@ -274,6 +368,25 @@ func InitMonitor() {
 	}
 }
 
+// InitHTTPErrors sets the http status policy and the default http status of the errors, the ones not
+// configured being reset to their defaults
+func InitHTTPErrors() {
+	conf := config.GlobalAppConfig.ErrorResponse
+	constants.ResetHTTPErrors()
+	if conf.StatusPolicy != "" {
+		if err := constants.SetHTTPStatusPolicy(constants.HTTPStatusPolicy(conf.StatusPolicy)); err != nil {
+			logger.Error(fmt.Sprintln(err))
+			panic(err)
+		}
+	}
+	if conf.DefaultHTTPStatus != 0 {
+		if err := constants.SetDefaultHTTPCode(constants.HTTPCode(conf.DefaultHTTPStatus)); err != nil {
+			logger.Error(fmt.Sprintln(err))
+			panic(err)
+		}
+	}
+}
+
 // InitHTTPPool: initialize http pool
 func InitHTTPPool() {
 	http.InitConnPool(&config.GlobalAppConfig.HTTPConfig)
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+)
+
+func TestInitHTTPErrors(t *testing.T) {
+	defer Reset()
+	errs := constants.AppErrors{Errors: []constants.AppError{{Code: constants.ParamsInValidErrorCode},
+		{Code: constants.APPErrorCode(9999)}}}
+
+	config.GlobalAppConfig.ErrorResponse = config.ErrorResponseConfig{StatusPolicy: "FirstWins", DefaultHTTPStatus: 503}
+	InitHTTPErrors()
+	if status := constants.GetAppHTTPError(errs); status.HTTPStatusCode != constants.HTTPStatusBadRequestCode {
+		t.Errorf("Expected the status of the first error, got %d", status.HTTPStatusCode)
+	}
+	if code := constants.ResolveHTTPCode(9999); code != 503 {
+		t.Errorf("Expected the configured default http code, got %d", code)
+	}
+
+	// the settings of an earlier init do not leak into an init without the config
+	config.GlobalAppConfig.ErrorResponse = config.ErrorResponseConfig{}
+	InitHTTPErrors()
+	if status := constants.GetAppHTTPError(errs); status.HTTPStatusCode != constants.HTTPStatusInternalServerErrorCode {
+		t.Errorf("Expected the default status of the last error, got %d", status.HTTPStatusCode)
+	}
+}
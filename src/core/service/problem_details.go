@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// Formats of the error responses
+const (
+	// ErrorFormatEnvelope renders the errors in the status of the response envelope
+	ErrorFormatEnvelope = "Envelope"
+	// ErrorFormatProblem renders the errors as problem documents (RFC 7807)
+	ErrorFormatProblem = "Problem"
+)
+
+// ProblemMediaType is the content type of the problem documents
+const ProblemMediaType = "application/problem+json"
+
+const problemTypeDefault = "about:blank"
+
+// validateErrorFormat checks if the error format is known, empty is valid and means the default
+func validateErrorFormat(format string) error {
+	switch format {
+	case "", ErrorFormatEnvelope, ErrorFormatProblem:
+		return nil
+	}
+	return fmt.Errorf("Unknown error format %s", format)
+}
+
+// getErrorFormat returns the error format of the api serving the request, the app level format if
+// the api does not override it or the request did not reach an api
+func getErrorFormat(data workflow.WorkFlowData, appFormat string) string {
+	if v, _ := data.IOData.Get(constants.APIVersion); v != nil {
+		if apiVersion, ok := v.(*versionmanager.Version); ok && apiVersion != nil && apiVersion.ErrorFormat != "" {
+			return apiVersion.ErrorFormat
+		}
+	}
+	return appFormat
+}
+
+// newProblem creates the problem document for the errors. The error deciding the http status as per the
+// http status policy is the primary error of the document, all the errors are listed as an extension
+// member if there are more
+func newProblem(data workflow.WorkFlowData, status *constants.APPHttpStatus,
+	conf config.ErrorResponseConfig) utilhttp.Problem {
+	appErrors := status.Errors
+	primary, _ := constants.GetPrimaryAppError(constants.AppErrors{Errors: appErrors})
+
+	problem := utilhttp.Problem{
+		Type:             problemTypeDefault,
+		Title:            http.StatusText(int(status.HTTPStatusCode)),
+		Status:           status.HTTPStatusCode,
+		Detail:           primary.Message,
+		Code:             primary.Code,
+		DeveloperMessage: primary.DeveloperMessage,
+	}
+	if conf.ProblemTypeBaseURI != "" {
+		problem.Type = fmt.Sprintf("%s%d", conf.ProblemTypeBaseURI, primary.Code)
+	}
+	if problem.Title == "" {
+		problem.Title = "Error"
+	}
+	if req, err := misc.GetRequestFromIO(data); err == nil && req.OriginalRequest != nil {
+		problem.Instance = req.OriginalRequest.URL.Path
+	}
+	if len(appErrors) > 1 {
+		problem.Errors = appErrors
+	}
+	return problem
+}
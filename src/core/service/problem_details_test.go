@@ -0,0 +1,92 @@
+package service
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// newProblemData returns the data of a request to the uri served by the api version if any
+func newProblemData(uri string, apiVersion *versionmanager.Version) workflow.WorkFlowData {
+	data := workflow.WorkFlowData{}
+	data.Create(new(workflow.WorkFlowIOInMemoryImpl), new(workflow.WorkFlowECInMemoryImpl))
+	if uri != "" {
+		data.IOData.Set(constants.Request, &utilhttp.Request{OriginalRequest: httptest.NewRequest("GET", uri, nil)})
+	}
+	if apiVersion != nil {
+		data.IOData.Set(constants.APIVersion, apiVersion)
+	}
+	return data
+}
+
+func TestValidateErrorFormat(t *testing.T) {
+	for _, format := range []string{"", ErrorFormatEnvelope, ErrorFormatProblem} {
+		if err := validateErrorFormat(format); err != nil {
+			t.Errorf("Error format %q rejected. Err : %s", format, err)
+		}
+	}
+	for _, format := range []string{"problem", "JSON", "Problem "} {
+		if err := validateErrorFormat(format); err == nil {
+			t.Errorf("Unknown error format %q accepted", format)
+		}
+	}
+}
+
+func TestGetErrorFormat(t *testing.T) {
+	tests := []struct {
+		apiVersion *versionmanager.Version
+		appFormat  string
+		want       string
+	}{
+		{nil, "", ""},
+		{nil, ErrorFormatProblem, ErrorFormatProblem},
+		{&versionmanager.Version{}, ErrorFormatProblem, ErrorFormatProblem},
+		{&versionmanager.Version{ErrorFormat: ErrorFormatEnvelope}, ErrorFormatProblem, ErrorFormatEnvelope},
+		{&versionmanager.Version{ErrorFormat: ErrorFormatProblem}, "", ErrorFormatProblem},
+	}
+	for _, test := range tests {
+		if got := getErrorFormat(newProblemData("", test.apiVersion), test.appFormat); got != test.want {
+			t.Errorf("Error format of %+v with the app format %q is %q, want %q", test.apiVersion, test.appFormat,
+				got, test.want)
+		}
+	}
+}
+
+func TestNewProblem(t *testing.T) {
+	defer Reset()
+	constants.SetHTTPStatusPolicy(constants.HighestSeverityWins)
+
+	notFound := constants.AppError{Code: constants.InvalidRequestURI, Message: "Not found"}
+	failed := constants.AppError{Code: constants.ResourceErrorCode, Message: "Failed", DeveloperMessage: "Timeout"}
+	invalid := constants.AppError{Code: constants.ParamsInValidErrorCode, Message: "Invalid"}
+
+	status := constants.GetAppHTTPError(constants.AppErrors{Errors: []constants.AppError{notFound}})
+	problem := newProblem(newProblemData("/florest/v1/item/12?q=1", nil), status, config.ErrorResponseConfig{})
+	want := utilhttp.Problem{Type: "about:blank", Title: "Not Found", Status: 404, Detail: "Not found",
+		Instance: "/florest/v1/item/12", Code: constants.InvalidRequestURI}
+	if !reflect.DeepEqual(problem, want) {
+		t.Errorf("Problem %+v, want %+v", problem, want)
+	}
+
+	// the server error decides the status and is the primary error of the document
+	errors := []constants.AppError{invalid, failed, notFound}
+	status = constants.GetAppHTTPError(constants.AppErrors{Errors: errors})
+	problem = newProblem(newProblemData("", nil), status,
+		config.ErrorResponseConfig{ProblemTypeBaseURI: "https://errors.example.com/"})
+	want = utilhttp.Problem{Type: "https://errors.example.com/1501", Title: "Internal Server Error", Status: 500,
+		Detail: "Failed", Code: constants.ResourceErrorCode, DeveloperMessage: "Timeout", Errors: errors}
+	if !reflect.DeepEqual(problem, want) {
+		t.Errorf("Problem %+v, want %+v", problem, want)
+	}
+
+	status = &constants.APPHttpStatus{HTTPStatusCode: 599, Errors: []constants.AppError{failed}}
+	if problem = newProblem(newProblemData("", nil), status, config.ErrorResponseConfig{}); problem.Title != "Error" {
+		t.Errorf("Title %q of an unknown status, want Error", problem.Title)
+	}
+}
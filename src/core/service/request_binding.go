@@ -0,0 +1,57 @@
+package service
+
+import (
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/binder"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// bindingKey identifies a registered api
+type bindingKey struct {
+	version versionmanager.BasicVersion
+	path    string
+}
+
+// requestBinders are the binders of the registered apis declaring the request types
+var requestBinders map[bindingKey]*binder.Binder
+
+// getRequestBinder returns the binder of the api, nil if the api does not declare the request types
+func getRequestBinder(apiInstance APIInterface) (*binder.Binder, error) {
+	rb, ok := apiInstance.(RequestBinder)
+	if !ok {
+		return nil, nil
+	}
+	return binder.New(rb.GetRequestTypes())
+}
+
+// bindRequest binds the request to the types declared by the api and sets the bound values in the IO data.
+// Returns the decoding and validation errors if any
+func bindRequest(data workflow.WorkFlowData, req *utilhttp.Request, apiVersion *versionmanager.Version) *constants.AppErrors {
+	if req == nil || apiVersion == nil {
+		return nil
+	}
+	b, found := requestBinders[bindingKey{apiVersion.GetBasicVersion(), apiVersion.Path}]
+	if !found {
+		return nil
+	}
+
+	values, appErrors := b.Bind(req)
+	if appErrors != nil {
+		return appErrors
+	}
+	if values.Body != nil {
+		data.IOData.Set(constants.BoundBody, values.Body)
+	}
+	if values.Query != nil {
+		data.IOData.Set(constants.BoundQuery, values.Query)
+	}
+	if values.Header != nil {
+		data.IOData.Set(constants.BoundHeader, values.Header)
+	}
+	if values.Path != nil {
+		data.IOData.Set(constants.BoundPath, values.Path)
+	}
+	return nil
+}
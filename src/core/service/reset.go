@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/core/common/utils/auth"
+	"github.com/jabong/florest-core/src/core/common/utils/healthcheck"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// Reset clears the registered apis, the registrations and the state initialised by the InitManager so that
+// the app can be initialised again, e.g. between tests. The workers of the shadow traffic are stopped once
+// the queued mirrored requests are served. The registered encoders and uri schemes, the dynamic config and
+// the errors registered with RegisterHTTPErrors are kept
+func Reset() {
+	apiList = nil
+	resourceBucketMapping = nil
+	apiCustomInitFunc = nil
+	configEnvUpdateMap = nil
+	globalEnvUpdateMap = nil
+	traceExporter = nil
+
+	requestBinders = nil
+	accessLogInstance = nil
+	responseCacheInstance = nil
+	idempotencyStore = nil
+	if shadowTrafficInstance != nil {
+		shadowTrafficInstance.executor.Stop()
+		shadowTrafficInstance = nil
+	}
+	appRateLimiter = nil
+	auth.SetAuditLogger("")
+	constants.ResetHTTPErrors()
+
+	versionmanager.Reset()
+	healthcheck.Reset()
+	config.GlobalAppConfig = new(config.AppConfig)
+}
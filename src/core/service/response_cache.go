@@ -0,0 +1,215 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	"github.com/jabong/florest-core/src/components/cache"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/responsecache"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// Defaults of the response cache
+const (
+	defaultResponseCacheEntries         = 10000
+	defaultResponseCacheCoalesceTimeout = 5 * time.Second
+)
+
+// responseCacheKeyPrefix namespaces the keys of the responses stored in a cache of the cache component
+const responseCacheKeyPrefix = ":responsecache:"
+
+// responseCache serves the GET requests of the cached apis from the store
+type responseCache struct {
+	store           responsecache.Store
+	group           *responsecache.Group
+	coalesceTimeout time.Duration
+}
+
+// pendingResponse is the response of a request which missed the cache, cached once served. call is
+// set if the request leads the concurrent requests for the key
+type pendingResponse struct {
+	key  string
+	ttl  time.Duration
+	call *responsecache.Call
+}
+
+// responseCacheInstance is the response cache of the app
+var responseCacheInstance *responseCache
+
+// InitResponseCache creates the store of the cached responses as per the response cache config
+func InitResponseCache() {
+	conf := config.GlobalAppConfig.ResponseCache
+	var store responsecache.Store
+	if conf.CacheKey != "" {
+		c, err := cache.Get(conf.CacheKey)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Invalid response cache key %s. Err : %s", conf.CacheKey, err))
+			panic(fmt.Sprintf("Invalid response cache key %s. Err : %s", conf.CacheKey, err))
+		}
+		store = responsecache.NewCacheStore(c, config.GlobalAppConfig.AppName+responseCacheKeyPrefix)
+	} else {
+		size := conf.MaxEntries
+		if size == 0 {
+			size = defaultResponseCacheEntries
+		}
+		s, err := responsecache.NewLRUStore(size)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Invalid response cache size %d. Err : %s", size, err))
+			panic(fmt.Sprintf("Invalid response cache size %d. Err : %s", size, err))
+		}
+		store = s
+	}
+	timeout := defaultResponseCacheCoalesceTimeout
+	if conf.CoalesceTimeoutInMs > 0 {
+		timeout = time.Duration(conf.CoalesceTimeoutInMs) * time.Millisecond
+	}
+	responseCacheInstance = &responseCache{store: store, group: responsecache.NewGroup(), coalesceTimeout: timeout}
+}
+
+// lookupResponseCache looks up the response of the GET requests of the cached apis. On a miss the request
+// is served by the orchestrator and its response cached, the concurrent requests for the same key waiting
+// for it. Returns true if the request is served from the cache
+func lookupResponseCache(data workflow.WorkFlowData, req *utilhttp.Request, apiVersion *versionmanager.Version,
+	orchBucket string, pathParams string) bool {
+	rc := responseCacheInstance
+	if rc == nil || req == nil || req.HTTPVerb != utilhttp.GET || req.Headers.Debug || apiVersion == nil ||
+		apiVersion.ResponseCache == nil || apiVersion.ResponseCache.TTL <= 0 {
+		return false
+	}
+	reqContext, _ := data.ExecContext.Get(constants.RequestContext)
+	m, _ := data.IOData.Get(constants.ResponseMediaType)
+	mediaType, _ := m.(string)
+	key := getResponseCacheKey(data, req, apiVersion, orchBucket, pathParams, mediaType)
+	pending := &pendingResponse{key: key, ttl: apiVersion.ResponseCache.TTL}
+
+	// no-cache requests are served by the orchestrator, refreshing the cached response
+	if !isNoCache(req.Headers) {
+		if entry := rc.store.Get(key); entry != nil {
+			logger.Info(fmt.Sprintf("Response of %s served from the cache", key), reqContext)
+			data.IOData.Set(constants.CachedResponse, entry)
+			return true
+		}
+		call, leader := rc.group.Join(key)
+		if leader {
+			pending.call = call
+		} else if entry, ok := call.Wait(rc.coalesceTimeout); ok {
+			logger.Info(fmt.Sprintf("Response of %s served from a concurrent request", key), reqContext)
+			data.IOData.Set(constants.CachedResponse, entry)
+			return true
+		}
+	}
+	data.IOData.Set(constants.ResponseCacheCall, pending)
+	return false
+}
+
+// getResponseCacheKey returns the key of the response - the resource, version, action, bucket and path
+// params followed by the query params and the headers the response varies with, the media type and, for
+// the apis having authenticators, the authenticated caller so that a caller never gets the response of another
+func getResponseCacheKey(data workflow.WorkFlowData, req *utilhttp.Request, apiVersion *versionmanager.Version,
+	orchBucket string, pathParams string, mediaType string) string {
+	conf := apiVersion.ResponseCache
+	key := strings.Join([]string{apiVersion.Resource, apiVersion.Version, apiVersion.Action, orchBucket,
+		pathParams}, "/")
+	var caller string
+	if len(apiVersion.Authenticators) > 0 {
+		caller = "|caller=" + getPrincipalKey(data)
+	}
+	if req.OriginalRequest == nil {
+		return key + "|" + mediaType + caller
+	}
+	if len(conf.QueryParams) > 0 {
+		query := req.OriginalRequest.URL.Query()
+		vary := url.Values{}
+		for _, p := range conf.QueryParams {
+			if v, ok := query[p]; ok {
+				vary[p] = v
+			}
+		}
+		key += "?" + vary.Encode()
+	}
+	for _, h := range conf.Headers {
+		key += "|" + http.CanonicalHeaderKey(h) + "=" + req.OriginalRequest.Header.Get(h)
+	}
+	return key + "|" + mediaType + caller
+}
+
+// isNoCache checks if the client asks for a response not served from a cache
+func isNoCache(headers utilhttp.RequestHeader) bool {
+	for _, directive := range strings.Split(headers.CacheControl, ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+	return headers.CacheControl == "" && strings.EqualFold(strings.TrimSpace(headers.Pragma), "no-cache")
+}
+
+// getCachedResponse returns the response served from the cache, nil if the request missed the cache
+func getCachedResponse(data workflow.WorkFlowData) *responsecache.Entry {
+	v, _ := data.IOData.Get(constants.CachedResponse)
+	entry, _ := v.(*responsecache.Entry)
+	return entry
+}
+
+// setCachedResponse sets the status, the body and the validator headers of the cached response in the
+// response, the other headers being the ones of the request served
+func setCachedResponse(apiResponse *utilhttp.APIResponse, entry *responsecache.Entry) {
+	if apiResponse.Headers == nil {
+		apiResponse.Headers = make(map[string]string)
+	}
+	for k, v := range entry.Headers {
+		apiResponse.Headers[k] = v
+	}
+	apiResponse.HTTPStatus = constants.HTTPCode(entry.Status)
+	apiResponse.Body = entry.Body
+}
+
+// cacheResponse caches the successful response of a request which missed the cache and hands it over to
+// the concurrent requests waiting for it
+func cacheResponse(data workflow.WorkFlowData, apiResponse utilhttp.APIResponse) {
+	v, _ := data.IOData.Get(constants.ResponseCacheCall)
+	pending, ok := v.(*pendingResponse)
+	if !ok || responseCacheInstance == nil {
+		return
+	}
+	entry := &responsecache.Entry{Status: int(apiResponse.HTTPStatus), Body: apiResponse.Body}
+	for _, h := range []string{utilhttp.ETagHeader, utilhttp.LastModifiedHeader} {
+		if val, ok := apiResponse.Headers[h]; ok {
+			if entry.Headers == nil {
+				entry.Headers = make(map[string]string)
+			}
+			entry.Headers[h] = val
+		}
+	}
+	if err := responseCacheInstance.store.Set(pending.key, entry, pending.ttl); err != nil {
+		rc, _ := data.ExecContext.Get(constants.RequestContext)
+		logger.Warning(fmt.Sprintf("Failed to cache the response of %s. Err : %s", pending.key, err), rc)
+	}
+	if pending.call != nil {
+		responseCacheInstance.group.Done(pending.key, pending.call, entry)
+	}
+}
+
+// releaseResponseCache wakes up the requests waiting for a request which failed, to be served by the
+// orchestrator. It has no effect if the response has been cached
+func releaseResponseCache(data *workflow.WorkFlowData) {
+	v, _ := data.IOData.Get(constants.ResponseCacheCall)
+	if pending, ok := v.(*pendingResponse); ok && pending.call != nil && responseCacheInstance != nil {
+		responseCacheInstance.group.Done(pending.key, pending.call, nil)
+	}
+}
+
+// purgeResponseCache removes the cached responses whose keys start with the prefix
+func purgeResponseCache(prefix string) (int, error) {
+	if responseCacheInstance == nil {
+		return 0, nil
+	}
+	return responseCacheInstance.store.Purge(prefix)
+}
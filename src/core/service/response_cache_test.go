@@ -0,0 +1,38 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/auth"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+func TestResponseCacheOfPrincipal(t *testing.T) {
+	store := auth.NewMemoryKeyStore([]auth.Key{{ID: "a", UserID: "u1"}, {ID: "b", UserID: "u2"}})
+	var calls int
+	profile := testAPI{
+		version: versionmanager.Version{Resource: "PROFILE", Action: "GET",
+			ResponseCache:  &versionmanager.ResponseCache{TTL: time.Minute},
+			Authenticators: []auth.Authenticator{auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{}, store)}},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			calls++
+			p := auth.GetPrincipal(data)
+			return result(p.UserID)(data)
+		},
+	}
+	app := newApp(t, config.AppConfig{}, profile)
+
+	app.GET("/florest/v1/profile/").Header("X-Api-Key", "a").Do().AssertSuccess().AssertData("", "u1")
+	// the response cached for a caller is not served to another
+	app.GET("/florest/v1/profile/").Header("X-Api-Key", "b").Do().AssertSuccess().AssertData("", "u2")
+	app.GET("/florest/v1/profile/").Header("X-Api-Key", "a").Do().AssertSuccess().AssertData("", "u1")
+	app.GET("/florest/v1/profile/").Header("X-Api-Key", "b").Do().AssertSuccess().AssertData("", "u2")
+	if calls != 2 {
+		t.Errorf("Orchestrator called %d times, want once per caller", calls)
+	}
+	app.GET("/florest/v1/profile/").Do().AssertError(constants.UnauthorizedErrorCode)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/jabong/florest-core/src/common/config"
 	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/tracer"
 )
 
 var apiList []APIInterface
@@ -19,8 +20,15 @@ func RegisterConfig(applicationConfig interface{}) {
 	config.GlobalAppConfig.ApplicationConfig = applicationConfig
 }
 
-func RegisterHTTPErrors(appErrorCodeMap map[constants.APPErrorCode]constants.HTTPCode) {
-	constants.UpdateAppHTTPError(appErrorCodeMap)
+// RegisterHTTPErrors maps the app error codes to http codes. The map is rejected as a whole if an error
+// code is already mapped to a different http code
+func RegisterHTTPErrors(appErrorCodeMap map[constants.APPErrorCode]constants.HTTPCode) error {
+	return constants.UpdateAppHTTPError(appErrorCodeMap)
+}
+
+// RegisterTraceExporter registers the exporter of the tracer spans, used instead of the exporter of the config
+func RegisterTraceExporter(e tracer.Exporter) {
+	traceExporter = e
 }
 
 func RegisterResourceBucketMapping(resource, bucketID string) {
@@ -41,3 +49,8 @@ func RegisterConfigEnvUpdateMap(a map[string]string) {
 func RegisterGlobalEnvUpdateMap(a map[string]string) {
 	globalEnvUpdateMap = a
 }
+
+// RegisterURIScheme registers a custom uri scheme which can be selected by name in URIScheme.Type of the config
+func RegisterURIScheme(name string, creator URISchemeCreator) {
+	uriSchemes[name] = creator
+}
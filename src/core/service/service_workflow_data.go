@@ -8,6 +8,7 @@ import (
 	"github.com/jabong/florest-core/src/common/config"
 	"github.com/jabong/florest-core/src/common/constants"
 	"github.com/jabong/florest-core/src/common/logger"
+	"github.com/jabong/florest-core/src/common/tracer"
 	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
 	"github.com/jabong/florest-core/src/core/common/orchestrator"
 )
@@ -34,6 +35,20 @@ func getBucketsMap(bucketsList string) map[string]string {
 	return bucketMap
 }
 
+// requestStateKeys are the keys of the IO data holding the bound values and the state of the response of a
+// request, not shared with the orchestrators run on a copy of its IO data
+var requestStateKeys = []string{constants.BoundBody, constants.BoundQuery, constants.BoundHeader,
+	constants.BoundPath, constants.ResponseWriter, constants.ResponseStream, constants.CachedResponse,
+	constants.ResponseCacheCall, constants.IdempotentRequest, constants.ReplayedResponse,
+	constants.UploadedFiles, constants.UploadedValues, constants.WebSocketSession}
+
+// clearRequestState clears the bound values and the state of the response of the request copied in the IO data
+func clearRequestState(io orchestrator.WorkFlowIOInterface) {
+	for _, key := range requestStateKeys {
+		io.Set(key, nil)
+	}
+}
+
 //Get the Service WorkFlow Data
 func GetData(r *http.Request) (*orchestrator.WorkFlowData, error) {
 	serviceInputOutput := new(orchestrator.WorkFlowIOInMemoryImpl)
@@ -42,6 +57,8 @@ func GetData(r *http.Request) (*orchestrator.WorkFlowData, error) {
 		return nil, rerr
 	}
 
+	setRequestIDs(&appReq.Headers)
+	trace := getTraceParent(&appReq.Headers)
 	serviceInputOutput.Set(constants.URI, appReq.URI)
 	serviceInputOutput.Set(constants.HTTPVerb, appReq.HTTPVerb)
 	serviceInputOutput.Set(constants.Request, appReq)
@@ -67,6 +84,11 @@ func GetData(r *http.Request) (*orchestrator.WorkFlowData, error) {
 			TransactionID: appReq.Headers.TransactionID,
 			URI:           appReq.URI,
 			ClientAppID:   appReq.Headers.ClientAppID,
+			TraceID:       trace.TraceID,
+			SpanID:        utilhttp.NewSpanID(),
+			ParentSpanID:  trace.ParentID,
+			TraceFlags:    trace.Flags,
+			TraceState:    appReq.Headers.TraceState,
 		})
 
 	logger.Info(fmt.Sprintf("Service Execution Context %v", serviceInputOutput))
@@ -76,3 +98,23 @@ func GetData(r *http.Request) (*orchestrator.WorkFlowData, error) {
 
 	return serviceWorkFlowData, nil
 }
+
+//setRequestIDs generates the request and transaction ids if the client did not send them
+func setRequestIDs(headers *utilhttp.RequestHeader) {
+	if headers.RequestID == "" {
+		headers.RequestID = utilhttp.GetTransactionID()
+	}
+	if headers.TransactionID == "" {
+		headers.TransactionID = utilhttp.GetTransactionID()
+	}
+}
+
+//getTraceParent returns the trace context of the caller, a new trace if the caller did not send a valid one.
+//The trace state is dropped along with an invalid traceparent
+func getTraceParent(headers *utilhttp.RequestHeader) utilhttp.TraceParent {
+	if tp, ok := utilhttp.ParseTraceParent(headers.TraceParent); ok {
+		return tp
+	}
+	headers.TraceState = ""
+	return utilhttp.NewTraceParent(tracer.SpanContext{TraceID: utilhttp.NewTraceID(), Sampled: tracer.Sample()})
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+func TestSetRequestIDs(t *testing.T) {
+	headers := &utilhttp.RequestHeader{RequestID: "r1", TransactionID: "t1"}
+	setRequestIDs(headers)
+	if headers.RequestID != "r1" || headers.TransactionID != "t1" {
+		t.Errorf("Ids of the request changed %+v", headers)
+	}
+
+	headers = new(utilhttp.RequestHeader)
+	setRequestIDs(headers)
+	if headers.RequestID == "" || headers.TransactionID == "" || headers.RequestID == headers.TransactionID {
+		t.Errorf("Ids of the request not generated %+v", headers)
+	}
+}
+
+func TestGetTraceParent(t *testing.T) {
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	headers := &utilhttp.RequestHeader{TraceParent: traceParent, TraceState: "vendor=value"}
+	if tp := getTraceParent(headers); tp.String() != traceParent || headers.TraceState != "vendor=value" {
+		t.Errorf("Trace context of the caller not kept, got %s %+v", tp, headers)
+	}
+
+	for _, invalid := range []string{"", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "garbage"} {
+		headers := &utilhttp.RequestHeader{TraceParent: invalid, TraceState: "vendor=value"}
+		// a new trace has no parent span
+		tp := getTraceParent(headers)
+		if len(tp.TraceID) != 32 || tp.TraceID == "4bf92f3577b34da6a3ce929d0e0e4736" || tp.ParentID != "" {
+			t.Errorf("New trace not generated for %q, got %+v", invalid, tp)
+		}
+		if headers.TraceState != "" {
+			t.Errorf("Trace state kept with the invalid traceparent %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	"github.com/jabong/florest-core/src/common/monitor"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+	"github.com/jabong/florest-core/src/core/common/utils/orchestratorhelper"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+	"github.com/jabong/florest-core/src/core/common/workerpool"
+)
+
+// shadowTraffic mirrors the sampled requests of the configured resources to their candidate bucket
+type shadowTraffic struct {
+	executor  *workerpool.WPExecutor
+	resources map[string]config.ShadowResourceConfig
+}
+
+// shadowRequest is a mirrored request along with the primary outcome it is compared with
+type shadowRequest struct {
+	data            workflow.WorkFlowData
+	resource        string
+	version         string
+	action          string
+	primaryBucket   string
+	candidateBucket string
+	pathParams      string
+	primaryResult   interface{}
+	primaryErr      error
+}
+
+// shadowTrafficPoolName is the name the worker pool of the mirrored requests is registered with
+const shadowTrafficPoolName = "ShadowTraffic"
+
+// shadowTrafficInstance is nil when no resource is mirrored
+var shadowTrafficInstance *shadowTraffic
+
+// shadowReadActions are the actions mirrored for all the apis, the others only for the apis with ShadowWrites
+var shadowReadActions = map[string]bool{string(utilhttp.GET): true, "HEAD": true}
+
+// InitShadowTraffic creates the worker pool for the mirrored requests of the configured resources
+func InitShadowTraffic() {
+	conf := config.GlobalAppConfig.ShadowTraffic
+	shadowTrafficInstance = nil
+	if len(conf.Resources) == 0 {
+		return
+	}
+
+	resources := make(map[string]config.ShadowResourceConfig, len(conf.Resources))
+	for _, r := range conf.Resources {
+		r.Resource = strings.ToUpper(r.Resource)
+		if r.CandidateBucket == "" {
+			r.CandidateBucket = constants.OrchestratorBucketNewAlgo
+		}
+		if r.Resource == "" || r.SamplePercentage < 0 || r.SamplePercentage > 100 {
+			logger.Error(fmt.Sprintf("Invalid shadow traffic config %+v", r))
+			panic(fmt.Sprintf("Invalid shadow traffic config %+v", r))
+		}
+		if _, found := resources[r.Resource]; found {
+			logger.Error(fmt.Sprintf("More than one shadow traffic config for resource %s", r.Resource))
+			panic(fmt.Sprintf("More than one shadow traffic config for resource %s", r.Resource))
+		}
+		resources[r.Resource] = r
+	}
+
+	executor, err := workerpool.NewWPExecutor(workerpool.Config{NWorkers: conf.NWorkers,
+		TaskQueueSize: conf.TaskQueueSize, Name: shadowTrafficPoolName})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create shadow traffic worker pool. Err : %s", err))
+		panic(fmt.Sprintf("Failed to create shadow traffic worker pool. Err : %s", err))
+	}
+	shadowTrafficInstance = &shadowTraffic{executor: executor, resources: resources}
+	logger.Info("Initialized shadow traffic")
+}
+
+// prepare returns the mirrored request if the request is sampled, nil otherwise. It must be called
+// before the primary orchestrator executes so that the candidate gets the request as received
+func (s *shadowTraffic) prepare(data workflow.WorkFlowData, resource string, version string, action string,
+	orchBucket string, pathParams string) *shadowRequest {
+	if s == nil {
+		return nil
+	}
+	conf, found := s.resources[resource]
+	// streamed responses are written as they are produced and can not be compared, uploads are read
+	// only once and their files removed with the request
+	if stream, _ := data.IOData.Get(constants.ResponseStream); stream != nil {
+		return nil
+	}
+	if files, _ := data.IOData.Get(constants.UploadedFiles); files != nil {
+		return nil
+	}
+	if !found || conf.CandidateBucket == orchBucket || !isShadowed(data, action) ||
+		rand.Float64()*100 >= conf.SamplePercentage {
+		return nil
+	}
+	ec, ok := data.ExecContext.(*workflow.WorkFlowECInMemoryImpl)
+	if !ok {
+		return nil
+	}
+	req, err := misc.GetRequestFromIO(data)
+	if err != nil {
+		return nil
+	}
+	shadowReq, err := cloneRequest(req)
+	if err != nil {
+		rc, _ := data.ExecContext.Get(constants.RequestContext)
+		logger.Error(fmt.Sprintf("Failed to mirror request for resource %s. Err : %s", resource, err), rc)
+		return nil
+	}
+
+	shadowEC := ec.Clone()
+	shadowEC.SetDebugFlag(false)
+	buckets := make(map[string]string)
+	if bucketsMap, _ := data.ExecContext.GetBuckets(); bucketsMap != nil {
+		for k, v := range bucketsMap {
+			buckets[k] = v
+		}
+	}
+	if key, present := resourceBucketMapping[resource]; present {
+		buckets[key] = conf.CandidateBucket
+	}
+	shadowEC.SetBuckets(buckets)
+	shadowEC.Set(constants.ShadowRequest, true)
+
+	shadowIO := data.IOData.Clone()
+	clearRequestState(shadowIO)
+	shadowIO.Set(constants.Request, shadowReq)
+	shadowIO.Set(constants.ResponseMetaData, utilhttp.NewResponseMetaData())
+
+	shadowData := workflow.WorkFlowData{}
+	shadowData.Create(shadowIO, shadowEC)
+	return &shadowRequest{
+		data:            shadowData,
+		resource:        resource,
+		version:         version,
+		action:          action,
+		primaryBucket:   orchBucket,
+		candidateBucket: conf.CandidateBucket,
+		pathParams:      pathParams,
+	}
+}
+
+// isShadowed checks if the requests of the action are mirrored, the writes being mirrored only for the
+// apis opting in with ShadowWrites
+func isShadowed(data workflow.WorkFlowData, action string) bool {
+	if shadowReadActions[strings.ToUpper(action)] {
+		return true
+	}
+	v, _ := data.IOData.Get(constants.APIVersion)
+	apiVersion, _ := v.(*versionmanager.Version)
+	return apiVersion != nil && apiVersion.ShadowWrites
+}
+
+// mirror submits the mirrored request along with the primary outcome. The request is dropped
+// if the worker pool is busy so that the primary request is never delayed
+func (s *shadowTraffic) mirror(sr *shadowRequest, primaryResult interface{}, primaryErr error) {
+	if s == nil || sr == nil {
+		return
+	}
+	sr.primaryResult = primaryResult
+	sr.primaryErr = primaryErr
+	if !s.executor.TryExecuteTask(workerpool.Task{Instance: sr, MethodName: "Run"}) {
+		sr.count("shadow_dropped_count")
+	}
+}
+
+// Run executes the candidate orchestrator and compares its outcome with the primary outcome
+func (sr *shadowRequest) Run() {
+	rc, _ := sr.data.ExecContext.Get(constants.RequestContext)
+	orchestrator, _, parameters, servedVersion, err := orchestratorhelper.ResolveOrchestrator(sr.resource,
+		sr.version, sr.action, sr.candidateBucket, sr.pathParams)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Shadow orchestrator not found for Resource: %s, Version: %s, Action: %s, "+
+			"BucketId: %s. Err : %s", sr.resource, sr.version, sr.action, sr.candidateBucket, err), rc)
+		sr.count("shadow_error_count")
+		return
+	}
+	// the candidate gets its own bound values, bound from the mirrored request as per its api
+	apiVersion, _ := versionmanager.GetVersion(sr.resource, servedVersion, sr.action, sr.candidateBucket,
+		sr.pathParams)
+	sr.data.IOData.Set(constants.APIVersion, apiVersion)
+	if req, rerr := misc.GetRequestFromIO(sr.data); rerr == nil {
+		req.PathParameters = parameters
+		if appErrors := bindRequest(sr.data, req, apiVersion); appErrors != nil {
+			logger.Warning(fmt.Sprintf("Shadow request not bound for Resource: %s, Version: %s, Action: %s, "+
+				"BucketId: %s. Err : %s", sr.resource, sr.version, sr.action, sr.candidateBucket, appErrors), rc)
+			sr.count("shadow_error_count")
+			return
+		}
+	}
+
+	res, err := orchestratorhelper.ExecuteOrchestrator(&sr.data, orchestrator)
+	if mismatch := compareShadowOutcome(sr.primaryResult, sr.primaryErr, res, err); mismatch != "" {
+		logger.Warning(fmt.Sprintf("Shadow mismatch for Resource: %s, Version: %s, Action: %s, BucketId: %s "+
+			"against BucketId: %s. %s", sr.resource, sr.version, sr.action, sr.candidateBucket,
+			sr.primaryBucket, mismatch), rc)
+		sr.count("shadow_mismatch_count")
+		return
+	}
+	sr.count("shadow_match_count")
+}
+
+func (sr *shadowRequest) count(metric string) {
+	dderr := monitor.GetInstance().Count(fmt.Sprintf("%v_%v_%v_%v_%v", sr.action, sr.version, sr.resource,
+		sr.candidateBucket, metric), 1, nil, 1)
+	if dderr != nil {
+		logger.Error(fmt.Sprintln("Monitoring Error ", dderr.Error()))
+	}
+}
+
+// compareShadowOutcome returns the description of the mismatch between the primary and the candidate
+// outcome, empty if they match. Results are compared by their json form as that is what the client gets
+func compareShadowOutcome(primaryResult interface{}, primaryErr error, candidateResult interface{},
+	candidateErr error) string {
+	if (primaryErr == nil) != (candidateErr == nil) {
+		return fmt.Sprintf("Primary error : %v, Candidate error : %v", primaryErr, candidateErr)
+	}
+	if primaryErr != nil {
+		if primaryErr.Error() != candidateErr.Error() {
+			return fmt.Sprintf("Primary error : %v, Candidate error : %v", primaryErr, candidateErr)
+		}
+		return ""
+	}
+
+	primaryJSON, perr := json.Marshal(primaryResult)
+	candidateJSON, cerr := json.Marshal(candidateResult)
+	if perr != nil || cerr != nil {
+		if !reflect.DeepEqual(primaryResult, candidateResult) {
+			return fmt.Sprintf("Primary result : %+v, Candidate result : %+v", primaryResult, candidateResult)
+		}
+		return ""
+	}
+	if !bytes.Equal(primaryJSON, candidateJSON) {
+		return fmt.Sprintf("Primary result : %s, Candidate result : %s", primaryJSON, candidateJSON)
+	}
+	return ""
+}
+
+// cloneRequest copies the request so that the candidate can read the body and set the path params
+// independently of the primary request
+func cloneRequest(req *utilhttp.Request) (*utilhttp.Request, error) {
+	shadowReq := *req
+	shadowReq.PathParameters = nil
+	if req.OriginalRequest == nil {
+		return &shadowReq, nil
+	}
+
+	httpReq := *req.OriginalRequest
+	if req.OriginalRequest.Body != nil {
+		body, err := ioutil.ReadAll(req.OriginalRequest.Body)
+		req.OriginalRequest.Body.Close()
+		// the primary request gets back the body read so far even on an error
+		req.OriginalRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return nil, errors.New("Failed to read request body. Err : " + err.Error())
+		}
+		httpReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	shadowReq.OriginalRequest = &httpReq
+	return &shadowReq, nil
+}
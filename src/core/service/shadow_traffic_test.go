@@ -0,0 +1,314 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/monitor"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/binder"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+	"github.com/jabong/florest-core/src/core/common/workerpool"
+)
+
+// resultNode is a node giving the result or failing with the error
+type resultNode struct {
+	id     string
+	result interface{}
+	err    error
+}
+
+func (n resultNode) Name() string {
+	return "Result Node"
+}
+
+func (n *resultNode) SetID(id string) {
+	n.id = id
+}
+
+func (n resultNode) GetID() (string, error) {
+	return n.id, nil
+}
+
+func (n resultNode) Execute(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+	data.IOData.Set(constants.Result, n.result)
+	return data, n.err
+}
+
+// shadowTestQuery is the query bound by the candidate
+type shadowTestQuery struct {
+	Limit int `query:"limit"`
+}
+
+// newResultOrchestrator returns the orchestrator of a single node giving the result
+func newResultOrchestrator(result interface{}) workflow.Orchestrator {
+	w := new(workflow.WorkFlowDefinition)
+	w.Create()
+	n := &resultNode{result: result}
+	n.SetID("1")
+	w.AddExecutionNode(n)
+	w.SetStartNode(n)
+	o := new(workflow.Orchestrator)
+	o.Create(w)
+	return *o
+}
+
+// newShadowData returns the data of a GET request with the buckets
+func newShadowData(buckets map[string]string) workflow.WorkFlowData {
+	data := workflow.WorkFlowData{}
+	data.Create(new(workflow.WorkFlowIOInMemoryImpl), new(workflow.WorkFlowECInMemoryImpl))
+	req, _ := utilhttp.GetRequest(httptest.NewRequest("GET", "/florest/v1/item/?limit=5", strings.NewReader("body")))
+	data.IOData.Set(constants.Request, &req)
+	data.ExecContext.Set(constants.RequestContext, utilhttp.RequestContext{RequestID: "r1"})
+	data.ExecContext.SetBuckets(buckets)
+	return data
+}
+
+// listenMetrics sends the metrics to a local listener and returns the function giving the names of the
+// counters received so far. The monitor is disabled once the test is done
+func listenMetrics(t *testing.T) func() []string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen for the metrics. Err : %s", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		monitor.Initialize(&monitor.MConf{Platform: monitor.DatadogAgent})
+	})
+	if err := monitor.Initialize(&monitor.MConf{Platform: monitor.DatadogAgent, Enabled: true,
+		AgentServer: conn.LocalAddr().String()}); err != nil {
+		t.Fatalf("Failed to initialise the monitor. Err : %s", err)
+	}
+	return func() []string {
+		var names []string
+		buf := make([]byte, 2048)
+		for {
+			conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return names
+			}
+			for _, metric := range strings.Split(string(buf[:n]), "\n") {
+				if index := strings.Index(metric, ":"); index != -1 && strings.Contains(metric, "|c") {
+					names = append(names, metric[:index])
+				}
+			}
+		}
+	}
+}
+
+func TestCompareShadowOutcome(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	ch := make(chan int)
+	tests := []struct {
+		name             string
+		primaryResult    interface{}
+		primaryErr       error
+		candidateResult  interface{}
+		candidateErr     error
+		mismatch         bool
+		mismatchContains string
+	}{
+		{name: "same result", primaryResult: item{1, "a"}, candidateResult: item{1, "a"}},
+		{name: "same json", primaryResult: item{1, "a"}, candidateResult: map[string]interface{}{"id": 1, "name": "a"}},
+		{name: "nil results", primaryResult: nil, candidateResult: nil},
+		{name: "different body", primaryResult: item{1, "a"}, candidateResult: item{1, "b"}, mismatch: true,
+			mismatchContains: `"name":"b"`},
+		{name: "candidate fails", primaryResult: item{1, "a"}, candidateErr: errors.New("timeout"), mismatch: true,
+			mismatchContains: "Candidate error : timeout"},
+		{name: "primary fails", primaryErr: errors.New("timeout"), candidateResult: item{1, "a"}, mismatch: true,
+			mismatchContains: "Primary error : timeout"},
+		{name: "same error", primaryErr: errors.New("timeout"), candidateErr: errors.New("timeout"),
+			candidateResult: item{1, "a"}},
+		{name: "different errors", primaryErr: errors.New("timeout"), candidateErr: errors.New("not found"),
+			mismatch: true, mismatchContains: "Candidate error : not found"},
+		{name: "same result not encodable", primaryResult: ch, candidateResult: ch},
+		{name: "different result not encodable", primaryResult: map[string]interface{}{"f": func() {}},
+			candidateResult: map[string]interface{}{"f": 1}, mismatch: true},
+	}
+	for _, test := range tests {
+		mismatch := compareShadowOutcome(test.primaryResult, test.primaryErr, test.candidateResult,
+			test.candidateErr)
+		if (mismatch != "") != test.mismatch || !strings.Contains(mismatch, test.mismatchContains) {
+			t.Errorf("%s: got the mismatch %q", test.name, mismatch)
+		}
+	}
+}
+
+func TestShadowTrafficPrepare(t *testing.T) {
+	defer Reset()
+	resourceBucketMapping = map[string]string{"ITEM": "itemBucket"}
+	s := &shadowTraffic{resources: map[string]config.ShadowResourceConfig{
+		"ITEM": {Resource: "ITEM", CandidateBucket: "New", SamplePercentage: 100},
+		"HALF": {Resource: "HALF", CandidateBucket: "New", SamplePercentage: 50},
+		"NONE": {Resource: "NONE", CandidateBucket: "New", SamplePercentage: 0},
+	}}
+
+	data := newShadowData(map[string]string{"itemBucket": "Default", "other": "1"})
+	sr := s.prepare(data, "ITEM", "V1", "GET", "Default", "12")
+	if sr == nil {
+		t.Fatal("Request not mirrored at 100%")
+	}
+	if sr.candidateBucket != "New" || sr.primaryBucket != "Default" || sr.pathParams != "12" {
+		t.Errorf("Mirrored request %+v", sr)
+	}
+	if shadow, _ := sr.data.ExecContext.Get(constants.ShadowRequest); shadow != true {
+		t.Error("Mirrored request not flagged")
+	}
+	if shadow, _ := data.ExecContext.Get(constants.ShadowRequest); shadow != nil {
+		t.Error("Primary request flagged")
+	}
+	if buckets, _ := sr.data.ExecContext.GetBuckets(); buckets["itemBucket"] != "New" || buckets["other"] != "1" {
+		t.Errorf("Buckets of the mirrored request %v", buckets)
+	}
+	if buckets, _ := data.ExecContext.GetBuckets(); buckets["itemBucket"] != "Default" {
+		t.Errorf("Buckets of the primary request changed %v", buckets)
+	}
+	primaryReq, _ := data.IOData.Get(constants.Request)
+	shadowReq, _ := sr.data.IOData.Get(constants.Request)
+	if primaryReq == shadowReq {
+		t.Error("Request not cloned")
+	}
+
+	// the candidate does not share the bound values and the state of the primary response
+	data = newShadowData(nil)
+	// the streamed requests and the uploads are not mirrored
+	for _, key := range requestStateKeys {
+		if key != constants.ResponseStream && key != constants.UploadedFiles {
+			data.IOData.Set(key, new(int))
+		}
+	}
+	if sr = s.prepare(data, "ITEM", "V1", "GET", "Default", ""); sr == nil {
+		t.Fatal("Request not mirrored at 100%")
+	}
+	for _, key := range requestStateKeys {
+		if v, _ := sr.data.IOData.Get(key); v != nil {
+			t.Errorf("%s shared with the candidate", key)
+		}
+		if v, _ := data.IOData.Get(key); v == nil && key != constants.ResponseStream && key != constants.UploadedFiles {
+			t.Errorf("%s removed from the primary request", key)
+		}
+	}
+
+	// the writes are mirrored only for the apis opting in
+	for _, action := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+		if s.prepare(newShadowData(nil), "ITEM", "V1", action, "Default", "") != nil {
+			t.Errorf("%s request mirrored", action)
+		}
+		data := newShadowData(nil)
+		data.IOData.Set(constants.APIVersion, &versionmanager.Version{ShadowWrites: true})
+		if s.prepare(data, "ITEM", "V1", action, "Default", "") == nil {
+			t.Errorf("%s request of an api with shadow writes not mirrored", action)
+		}
+	}
+	if s.prepare(newShadowData(nil), "ITEM", "V1", "HEAD", "Default", "") == nil {
+		t.Error("HEAD request not mirrored")
+	}
+
+	if s.prepare(newShadowData(nil), "ITEM", "V1", "GET", "New", "") != nil {
+		t.Error("Request to the candidate bucket mirrored")
+	}
+	if s.prepare(newShadowData(nil), "OTHER", "V1", "GET", "Default", "") != nil {
+		t.Error("Request of a resource not configured mirrored")
+	}
+	streamed := newShadowData(nil)
+	streamed.IOData.Set(constants.ResponseStream, new(utilhttp.Stream))
+	if s.prepare(streamed, "ITEM", "V1", "GET", "Default", "") != nil {
+		t.Error("Streamed request mirrored")
+	}
+	uploaded := newShadowData(nil)
+	uploaded.IOData.Set(constants.UploadedFiles, map[string][]*utilhttp.UploadedFile{})
+	if s.prepare(uploaded, "ITEM", "V1", "GET", "Default", "") != nil {
+		t.Error("Upload mirrored")
+	}
+	var disabled *shadowTraffic
+	if disabled.prepare(newShadowData(nil), "ITEM", "V1", "GET", "Default", "") != nil {
+		t.Error("Request mirrored without shadow traffic")
+	}
+
+	mirrored := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		for _, resource := range []string{"HALF", "NONE"} {
+			if s.prepare(newShadowData(nil), resource, "V1", "GET", "Default", "") != nil {
+				mirrored[resource]++
+			}
+		}
+	}
+	if mirrored["NONE"] != 0 || mirrored["HALF"] < 350 || mirrored["HALF"] > 650 {
+		t.Errorf("Mirrored %v of 1000 requests at 50%% and 0%%", mirrored)
+	}
+}
+
+func TestShadowTrafficMirror(t *testing.T) {
+	defer Reset()
+	metrics := listenMetrics(t)
+	candidateVersion := versionmanager.Version{Resource: "ITEM", Version: "V1", Action: "GET", BucketID: "New"}
+	candidate := versionmanager.NewParam()
+	candidate.Update("", newResultOrchestrator("b"), nil)
+	candidate.SetVersion("", &candidateVersion)
+	versionmanager.Initialize(versionmanager.VersionMap{candidateVersion.GetBasicVersion(): candidate})
+	b, err := binder.New(binder.Types{Query: shadowTestQuery{}})
+	if err != nil {
+		t.Fatalf("Failed to create the binder. Err : %s", err)
+	}
+	requestBinders = map[bindingKey]*binder.Binder{{candidateVersion.GetBasicVersion(), ""}: b}
+	executor, err := workerpool.NewWPExecutor(workerpool.Config{NWorkers: 1, TaskQueueSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create the worker pool. Err : %s", err)
+	}
+	s := &shadowTraffic{executor: executor, resources: map[string]config.ShadowResourceConfig{
+		"ITEM":  {Resource: "ITEM", CandidateBucket: "New", SamplePercentage: 100},
+		"OTHER": {Resource: "OTHER", CandidateBucket: "New", SamplePercentage: 100},
+	}}
+
+	mirror := func(resource string, primaryResult interface{}, primaryErr error) {
+		s.mirror(s.prepare(newShadowData(nil), resource, "V1", "GET", "Default", ""), primaryResult, primaryErr)
+	}
+	primary := newShadowData(nil)
+	primaryQuery := &shadowTestQuery{Limit: 1}
+	primary.IOData.Set(constants.BoundQuery, primaryQuery)
+	sr := s.prepare(primary, "ITEM", "V1", "GET", "Default", "")
+	s.mirror(sr, "b", nil)
+	mirror("ITEM", "a", nil)
+	mirror("ITEM", "b", errors.New("timeout"))
+	mirror("OTHER", "b", nil)
+	s.mirror(nil, "b", nil)
+	var disabled *shadowTraffic
+	disabled.mirror(&shadowRequest{}, "b", nil)
+	// the queued requests are served before the workers stop
+	executor.Stop()
+
+	// the candidate binds its own values from the mirrored request
+	if q, _ := sr.data.IOData.Get(constants.BoundQuery); q == primaryQuery || q == nil ||
+		q.(*shadowTestQuery).Limit != 5 {
+		t.Errorf("Candidate bound the query %+v", q)
+	}
+	if primaryQuery.Limit != 1 {
+		t.Errorf("Primary query changed %+v", primaryQuery)
+	}
+
+	counts := make(map[string]int)
+	for _, name := range metrics() {
+		counts[name]++
+	}
+	want := map[string]int{
+		"GET_V1_ITEM_New_shadow_match_count":    1,
+		"GET_V1_ITEM_New_shadow_mismatch_count": 2,
+		"GET_V1_OTHER_New_shadow_error_count":   1,
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("Counted %s %d times, want %d. Counts : %v", name, counts[name], n, counts)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+	"github.com/jabong/florest-core/src/core/common/utils/responseheaders"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// validateStreamFormat checks if the stream format is known, empty is valid and means not streamed
+func validateStreamFormat(format string) error {
+	if format != "" && !utilhttp.IsStreamFormat(format) {
+		return fmt.Errorf("Unknown stream format %s, expected %s or %s", format, utilhttp.StreamJSONLines,
+			utilhttp.StreamSSE)
+	}
+	return nil
+}
+
+// isStreamed checks if the api streams its response
+func isStreamed(apiVersion *versionmanager.Version) bool {
+	return apiVersion != nil && apiVersion.Stream != ""
+}
+
+// openStream sets the response stream in the IO data for the streaming apis. The headers are resolved
+// now as the stream may start before the response header writer is reached
+func openStream(data workflow.WorkFlowData, apiVersion *versionmanager.Version) *constants.AppError {
+	if !isStreamed(apiVersion) {
+		return nil
+	}
+	w, _ := data.IOData.Get(constants.ResponseWriter)
+	rw, ok := w.(http.ResponseWriter)
+	if !ok || rw == nil {
+		return &constants.AppError{Code: constants.InvalidErrorCode, Message: "Response can not be streamed",
+			DeveloperMessage: "Response writer not available"}
+	}
+	var done <-chan struct{}
+	if req, err := misc.GetRequestFromIO(data); err == nil && req.OriginalRequest != nil {
+		done = req.OriginalRequest.Context().Done()
+	}
+	stream, err := utilhttp.NewStream(rw, apiVersion.Stream, done, responseheaders.GetHeaders(data))
+	if err != nil {
+		return &constants.AppError{Code: constants.InvalidErrorCode, Message: "Response can not be streamed",
+			DeveloperMessage: err.Error()}
+	}
+	data.IOData.Set(constants.ResponseStream, stream)
+	return nil
+}
+
+// getStartedStream returns the response stream if the first byte of the response has been streamed
+func getStartedStream(data workflow.WorkFlowData) *utilhttp.Stream {
+	stream, err := misc.GetStreamFromIO(data)
+	if err != nil || !stream.Started() {
+		return nil
+	}
+	return stream
+}
@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// streamLines returns the business logic streaming the lines, failing with the error code if any
+func streamLines(code constants.APPErrorCode, lines ...interface{}) executeFunc {
+	return func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+		stream, err := misc.GetStreamFromIO(data)
+		if err != nil {
+			return data, err
+		}
+		for _, line := range lines {
+			stream.WriteJSON(line)
+		}
+		if code != 0 {
+			return appError(code)(data)
+		}
+		return data, nil
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	app := newApp(t, config.AppConfig{},
+		testAPI{version: versionmanager.Version{Resource: "LINES", Action: "GET", Stream: utilhttp.StreamJSONLines},
+			execute: streamLines(constants.ResourceErrorCode, 1, 2)},
+		testAPI{version: versionmanager.Version{Resource: "NONE", Action: "GET", Stream: utilhttp.StreamJSONLines},
+			execute: streamLines(constants.ParamsInValidErrorCode)})
+
+	// the status is sent with the first line, the error is the last line
+	res := app.GET("/florest/v1/lines/").Do().AssertStatus(http.StatusOK).
+		AssertHeader("Content-Type", "application/x-ndjson")
+	lines := strings.Split(strings.TrimSuffix(string(res.Body()), "\n"), "\n")
+	if len(lines) != 3 || lines[0] != "1" || lines[1] != "2" {
+		t.Fatalf("Streamed lines %q", lines)
+	}
+	var last utilhttp.Response
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil {
+		t.Fatalf("Failed to decode the error line %s. Err : %s", lines[2], err)
+	}
+	if last.Status.Success || last.Status.HTTPStatusCode != constants.HTTPStatusInternalServerErrorCode ||
+		len(last.Status.Errors) != 1 || last.Status.Errors[0].Code != constants.ResourceErrorCode {
+		t.Errorf("Error line %s", lines[2])
+	}
+
+	// nothing is streamed yet, the error is a regular response
+	app.GET("/florest/v1/none/").Do().AssertStatus(http.StatusBadRequest).
+		AssertError(constants.ParamsInValidErrorCode)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	"github.com/jabong/florest-core/src/common/tracer"
+	"github.com/jabong/florest-core/src/common/tracer/exporter"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+)
+
+// traceExporter is the exporter registered by the app, used instead of the exporter of the config
+var traceExporter tracer.Exporter
+
+// InitTracer starts the tracer as per the config with the registered or the configured exporter
+func InitTracer() {
+	conf := config.GlobalAppConfig.Tracing
+	if conf.ServiceName == "" {
+		conf.ServiceName = config.GlobalAppConfig.AppName
+	}
+	e := traceExporter
+	if e == nil && conf.Enabled {
+		var err error
+		if e, err = exporter.New(conf); err != nil {
+			logger.Error(fmt.Sprintln(err))
+			panic(err)
+		}
+	}
+	err := tracer.Init(conf, e, func(err error) {
+		logger.Warning(fmt.Sprintf("Failed to export spans. Err : %s", err))
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintln(err))
+		panic(err)
+	}
+}
+
+// startRequestSpan starts the server span of the request, with the ids given to it in the request context
+func startRequestSpan(data *workflow.WorkFlowData, r *http.Request) *tracer.Span {
+	rc, _ := data.ExecContext.Get(constants.RequestContext)
+	reqContext, ok := rc.(utilhttp.RequestContext)
+	if !ok {
+		return nil
+	}
+	span := tracer.StartWithContext(reqContext.GetSpanContext(), reqContext.ParentSpanID, "HTTP "+r.Method,
+		tracer.SpanKindServer)
+	span.SetAttribute(tracer.AttrHTTPMethod, r.Method)
+	span.SetAttribute(tracer.AttrHTTPTarget, r.URL.RequestURI())
+	return span
+}
+
+// finishRequestSpan names the span after the api served and ends it with the http status of the response
+func finishRequestSpan(span *tracer.Span, data *workflow.WorkFlowData, status constants.HTTPCode) {
+	if span == nil {
+		return
+	}
+	if resource, version, action, _, _ := getServiceVersion(*data); resource != "" {
+		span.Name = fmt.Sprintf("%s %s %s", action, version, resource)
+	}
+	span.SetAttribute(tracer.AttrHTTPStatusCode, int(status))
+	if status >= constants.HTTPStatusInternalServerErrorCode {
+		span.SetError(fmt.Errorf("Request failed with status %d", status))
+	}
+	span.Finish()
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"mime/multipart"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// parseUpload parses the multipart upload of the request for the apis accepting uploads and sets the files
+// and the values in the IO data. The values are also made available to the request binding
+func parseUpload(data workflow.WorkFlowData, req *utilhttp.Request, apiVersion *versionmanager.Version) *constants.AppError {
+	if apiVersion == nil || apiVersion.Upload == nil || req == nil || req.OriginalRequest == nil ||
+		!utilhttp.IsMultipart(req.OriginalRequest) {
+		return nil
+	}
+	conf := apiVersion.Upload.WithDefaults(config.GlobalAppConfig.Upload)
+	form, err := utilhttp.ParseMultipart(req.OriginalRequest, conf)
+	if err != nil {
+		if limitErr, ok := err.(*utilhttp.UploadLimitError); ok {
+			return &constants.AppError{Code: constants.PayloadTooLargeErrorCode, Message: limitErr.Error()}
+		}
+		return &constants.AppError{Code: constants.ParamsInValidErrorCode, Message: "Invalid multipart upload",
+			DeveloperMessage: err.Error()}
+	}
+	data.IOData.Set(constants.UploadedFiles, form.Files)
+	data.IOData.Set(constants.UploadedValues, form.Values)
+	req.OriginalRequest.MultipartForm = &multipart.Form{Value: form.Values}
+	return nil
+}
+
+// removeUploads removes the temp files of the upload of the request if any
+func removeUploads(data workflow.WorkFlowData) {
+	f, _ := data.IOData.Get(constants.UploadedFiles)
+	files, ok := f.(map[string][]*utilhttp.UploadedFile)
+	if !ok || len(files) == 0 {
+		return
+	}
+	form := utilhttp.MultipartForm{Files: files}
+	if err := form.RemoveAll(); err != nil {
+		rc, _ := data.ExecContext.Get(constants.RequestContext)
+		logger.Error(fmt.Sprintf("Failed to remove the uploaded files. Err : %s", err), rc)
+	}
+}
@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// uploadBody returns a multipart body uploading the file content
+func uploadBody(content string) ([]byte, string) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("title", "doc")
+	fw, _ := w.CreateFormFile("doc", "doc.txt")
+	fw.Write([]byte(content))
+	w.Close()
+	return body.Bytes(), w.FormDataContentType()
+}
+
+func TestUpload(t *testing.T) {
+	upload := testAPI{
+		version: versionmanager.Version{Resource: "DOC", Action: "POST", Upload: &utilhttp.UploadConfig{MaxPartSize: 8}},
+		execute: func(data workflow.WorkFlowData) (workflow.WorkFlowData, error) {
+			f, _ := data.IOData.Get(constants.UploadedFiles)
+			files, _ := f.(map[string][]*utilhttp.UploadedFile)
+			if len(files["doc"]) != 1 {
+				return appError(constants.ParamsInValidErrorCode)(data)
+			}
+			return result(files["doc"][0].Size)(data)
+		},
+	}
+	app := newApp(t, config.AppConfig{}, upload)
+
+	app.POST("/florest/v1/doc/").Body(uploadBody("hello")).Do().AssertSuccess().AssertData("", 5)
+	app.POST("/florest/v1/doc/").Body(uploadBody("hello world")).Do().
+		AssertStatus(http.StatusRequestEntityTooLarge).AssertError(constants.PayloadTooLargeErrorCode)
+	app.POST("/florest/v1/doc/").Body([]byte("--XX\r\nbroken"), "multipart/form-data; boundary=XX").Do().
+		AssertStatus(http.StatusBadRequest).AssertError(constants.ParamsInValidErrorCode)
+	// the bodies which are not multipart are left to the business logic
+	app.POST("/florest/v1/doc/").Body([]byte(strings.Repeat("a", 16)), "text/plain").Do().
+		AssertStatus(http.StatusBadRequest)
+}
@@ -2,17 +2,23 @@ package service
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/jabong/florest-core/src/common/config"
 	"github.com/jabong/florest-core/src/common/constants"
 	"github.com/jabong/florest-core/src/common/logger"
 	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
 	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/misc"
 )
 
 type URIInterpreter struct {
-	id string
+	id     string
+	scheme URIScheme
+}
+
+// SetURIScheme sets the scheme used to interpret the uri, the path uri scheme is used if not set
+func (u *URIInterpreter) SetURIScheme(scheme URIScheme) {
+	u.scheme = scheme
 }
 
 func (u URIInterpreter) Name() string {
@@ -57,31 +63,12 @@ func (u URIInterpreter) getResource(data workflow.WorkFlowData) (resource string
 	}
 	logger.Info(fmt.Sprintln("uri is ", uri), rc)
 
-	// remove query parameter if any
-	if index := strings.Index(uri, "?"); index != -1 {
-		uri = uri[:index]
-	}
-	// split on basis of separator
-	uriArr := strings.Split(uri[1:], "/")
-
-	if len(uriArr) >= 2 &&
-		uriArr[0] == config.GlobalAppConfig.AppName &&
-		strings.ToUpper(uriArr[1]) == constants.HealthCheckAPI {
-		resource = constants.HealthCheckAPI
-		version = ""
-		pathParams = ""
-	} else if len(uriArr) >= 3 && uriArr[0] == config.GlobalAppConfig.AppName {
-		resource = strings.ToUpper(uriArr[2])
-		version = strings.ToUpper(uriArr[1])
-		if len(uriArr) > 3 {
-			pathParams = strings.Join(uriArr[3:], "/")
-		}
-	} else {
-		//Badly formed URI
-		resource = ""
-		version = ""
-		pathParams = ""
+	req, _ := misc.GetRequestFromIO(data)
+	scheme := u.scheme
+	if scheme == nil {
+		scheme = newPathURIScheme(config.GlobalAppConfig.URIScheme)
 	}
+	resource, version, pathParams = scheme.Interpret(uri, req)
 
 	if v, ok := actiondata.(utilhttp.Method); ok {
 		action = string(v)
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+// URIScheme interprets the resource, version and path params of a request. The returned resource
+// and version are looked up in the version manager as is
+type URIScheme interface {
+	Interpret(uri string, req *utilhttp.Request) (resource string, version string, pathParams string)
+}
+
+// URISchemeCreator creates an URIScheme from the config
+type URISchemeCreator func(conf config.URISchemeConfig) URIScheme
+
+const (
+	// PathURIScheme interprets /{Prefix}/{version}/{resource}/{pathParams}
+	PathURIScheme = "Path"
+	// HeaderURIScheme interprets /{Prefix}/{resource}/{pathParams} with the version in a header or query param
+	HeaderURIScheme = "Header"
+	// MediaTypeURIScheme interprets /{Prefix}/{resource}/{pathParams} with the version in the Accept header
+	MediaTypeURIScheme = "MediaType"
+)
+
+var uriSchemes = map[string]URISchemeCreator{
+	PathURIScheme:      newPathURIScheme,
+	HeaderURIScheme:    newHeaderURIScheme,
+	MediaTypeURIScheme: newMediaTypeURIScheme,
+}
+
+// getURIScheme returns the uri scheme for the config, defaults to the path uri scheme
+func getURIScheme(conf config.URISchemeConfig) (URIScheme, error) {
+	schemeType := conf.Type
+	if schemeType == "" {
+		schemeType = PathURIScheme
+	}
+	creator, found := uriSchemes[schemeType]
+	if !found {
+		return nil, fmt.Errorf("Unknown URI scheme %s", schemeType)
+	}
+	return creator(conf), nil
+}
+
+// uriPrefixedPath holds the path segments following the configured prefix
+type uriPrefixedPath struct {
+	prefix []string
+}
+
+func newURIPrefixedPath(conf config.URISchemeConfig) uriPrefixedPath {
+	prefix := config.GlobalAppConfig.AppName
+	if conf.Prefix != nil {
+		prefix = *conf.Prefix
+	}
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return uriPrefixedPath{}
+	}
+	return uriPrefixedPath{prefix: strings.Split(prefix, "/")}
+}
+
+// segments returns the path segments after the prefix, ok is false if the uri does not start with the prefix
+func (p uriPrefixedPath) segments(uri string) (segments []string, ok bool) {
+	// remove query parameter if any
+	if index := strings.Index(uri, "?"); index != -1 {
+		uri = uri[:index]
+	}
+	uriArr := strings.Split(strings.TrimPrefix(uri, "/"), "/")
+	if len(uriArr) < len(p.prefix) {
+		return nil, false
+	}
+	for i, v := range p.prefix {
+		if uriArr[i] != v {
+			return nil, false
+		}
+	}
+	return uriArr[len(p.prefix):], true
+}
+
+// isHealthCheck checks if the path segments point to the health check api
+func isHealthCheck(segments []string) bool {
+	return len(segments) >= 1 && strings.ToUpper(segments[0]) == constants.HealthCheckAPI
+}
+
+// normaliseVersion upper cases the version and prefixes V if it is only a number, e.g. 2 -> V2
+func normaliseVersion(version string) string {
+	version = strings.ToUpper(strings.TrimSpace(version))
+	if version != "" && version[0] >= '0' && version[0] <= '9' {
+		version = "V" + version
+	}
+	return version
+}
+
+// pathURIScheme reads the version and resource from the path
+type pathURIScheme struct {
+	path uriPrefixedPath
+}
+
+func newPathURIScheme(conf config.URISchemeConfig) URIScheme {
+	return pathURIScheme{path: newURIPrefixedPath(conf)}
+}
+
+func (s pathURIScheme) Interpret(uri string, req *utilhttp.Request) (resource string,
+	version string, pathParams string) {
+	segments, ok := s.path.segments(uri)
+	if !ok {
+		//Badly formed URI
+		return "", "", ""
+	}
+	if isHealthCheck(segments) {
+		return constants.HealthCheckAPI, "", ""
+	}
+	if len(segments) < 2 {
+		//Badly formed URI
+		return "", "", ""
+	}
+	return strings.ToUpper(segments[1]), strings.ToUpper(segments[0]), strings.Join(segments[2:], "/")
+}
+
+// headerURIScheme reads the resource from the path and the version from a header or query param
+type headerURIScheme struct {
+	path           uriPrefixedPath
+	header         string
+	queryParam     string
+	defaultVersion string
+}
+
+func newHeaderURIScheme(conf config.URISchemeConfig) URIScheme {
+	return headerURIScheme{
+		path:           newURIPrefixedPath(conf),
+		header:         conf.VersionHeader,
+		queryParam:     conf.VersionQueryParam,
+		defaultVersion: conf.DefaultVersion,
+	}
+}
+
+func (s headerURIScheme) Interpret(uri string, req *utilhttp.Request) (resource string,
+	version string, pathParams string) {
+	segments, ok := s.path.segments(uri)
+	if !ok || len(segments) < 1 || segments[0] == "" {
+		//Badly formed URI
+		return "", "", ""
+	}
+	if isHealthCheck(segments) {
+		return constants.HealthCheckAPI, "", ""
+	}
+
+	version = s.defaultVersion
+	if req != nil && req.OriginalRequest != nil {
+		if v := req.OriginalRequest.URL.Query().Get(s.queryParam); s.queryParam != "" && v != "" {
+			version = v
+		}
+		if v := req.OriginalRequest.Header.Get(s.header); s.header != "" && v != "" {
+			version = v
+		}
+	}
+	return strings.ToUpper(segments[0]), normaliseVersion(version), strings.Join(segments[1:], "/")
+}
+
+// mediaTypeURIScheme reads the resource from the path and the version from the Accept header,
+// either as application/vnd.{vendor}.{version}+json or as application/json; version={version}
+type mediaTypeURIScheme struct {
+	path           uriPrefixedPath
+	vendor         string
+	defaultVersion string
+}
+
+func newMediaTypeURIScheme(conf config.URISchemeConfig) URIScheme {
+	return mediaTypeURIScheme{
+		path:           newURIPrefixedPath(conf),
+		vendor:         conf.MediaTypeVendor,
+		defaultVersion: conf.DefaultVersion,
+	}
+}
+
+func (s mediaTypeURIScheme) Interpret(uri string, req *utilhttp.Request) (resource string,
+	version string, pathParams string) {
+	segments, ok := s.path.segments(uri)
+	if !ok || len(segments) < 1 || segments[0] == "" {
+		//Badly formed URI
+		return "", "", ""
+	}
+	if isHealthCheck(segments) {
+		return constants.HealthCheckAPI, "", ""
+	}
+
+	version = s.defaultVersion
+	if req != nil {
+		if v := s.getVersion(req.Headers.Accept); v != "" {
+			version = v
+		}
+	}
+	return strings.ToUpper(segments[0]), normaliseVersion(version), strings.Join(segments[1:], "/")
+}
+
+// getVersion returns the version from the first media range of the Accept header carrying one
+func (s mediaTypeURIScheme) getVersion(accept string) string {
+	vendorPrefix := "application/vnd." + s.vendor + "."
+	for _, mediaRange := range strings.Split(accept, constants.FieldSeperator) {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if s.vendor != "" && strings.HasPrefix(mediaType, vendorPrefix) {
+			v := strings.TrimPrefix(mediaType, vendorPrefix)
+			if index := strings.Index(v, "+"); index != -1 {
+				v = v[:index]
+			}
+			if v != "" {
+				return v
+			}
+		}
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.ToLower(kv[0]) == "version" && kv[1] != "" {
+				return kv[1]
+			}
+		}
+	}
+	return ""
+}
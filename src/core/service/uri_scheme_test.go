@@ -0,0 +1,147 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jabong/florest-core/src/common/config"
+	"github.com/jabong/florest-core/src/common/constants"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+)
+
+// uriSchemeTest is a request interpreted by an uri scheme
+type uriSchemeTest struct {
+	uri        string
+	header     map[string]string
+	resource   string
+	version    string
+	pathParams string
+}
+
+// newURIRequest returns the request of the uri with the headers
+func newURIRequest(uri string, header map[string]string) *utilhttp.Request {
+	r := httptest.NewRequest("GET", uri, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	return &utilhttp.Request{OriginalRequest: r, Headers: utilhttp.RequestHeader{Accept: r.Header.Get("Accept")}}
+}
+
+func testURIScheme(t *testing.T, conf config.URISchemeConfig, tests []uriSchemeTest) {
+	scheme, err := getURIScheme(conf)
+	if err != nil {
+		t.Fatalf("Failed to get the uri scheme %s. Err : %s", conf.Type, err)
+	}
+	for _, test := range tests {
+		resource, version, pathParams := scheme.Interpret(test.uri, newURIRequest(test.uri, test.header))
+		if resource != test.resource || version != test.version || pathParams != test.pathParams {
+			t.Errorf("%s scheme interprets %s %v as %q %q %q, want %q %q %q", conf.Type, test.uri, test.header,
+				resource, version, pathParams, test.resource, test.version, test.pathParams)
+		}
+	}
+}
+
+func TestPathURIScheme(t *testing.T) {
+	appName := config.GlobalAppConfig.AppName
+	defer func() { config.GlobalAppConfig.AppName = appName }()
+	config.GlobalAppConfig.AppName = "florest"
+
+	testURIScheme(t, config.URISchemeConfig{}, []uriSchemeTest{
+		{uri: "/florest/v1/item/", resource: "ITEM", version: "V1"},
+		{uri: "/florest/v2/item/12/size?q=1", resource: "ITEM", version: "V2", pathParams: "12/size"},
+		{uri: "/florest/healthcheck/", resource: constants.HealthCheckAPI},
+		{uri: "/florest/v1", resource: "", version: ""},
+		{uri: "/other/v1/item/", resource: "", version: ""},
+		{uri: "/", resource: "", version: ""},
+	})
+
+	prefix := "/api/florest/"
+	testURIScheme(t, config.URISchemeConfig{Type: PathURIScheme, Prefix: &prefix}, []uriSchemeTest{
+		{uri: "/api/florest/v1/item/12", resource: "ITEM", version: "V1", pathParams: "12"},
+		{uri: "/florest/v1/item/", resource: "", version: ""},
+	})
+
+	prefix = ""
+	testURIScheme(t, config.URISchemeConfig{Prefix: &prefix}, []uriSchemeTest{
+		{uri: "/v1/item/", resource: "ITEM", version: "V1"},
+		{uri: "/healthcheck", resource: constants.HealthCheckAPI},
+	})
+}
+
+func TestHeaderURIScheme(t *testing.T) {
+	prefix := "florest"
+	conf := config.URISchemeConfig{Type: HeaderURIScheme, Prefix: &prefix, VersionHeader: "X-Api-Version",
+		VersionQueryParam: "v", DefaultVersion: "v1"}
+	testURIScheme(t, conf, []uriSchemeTest{
+		{uri: "/florest/item/", resource: "ITEM", version: "V1"},
+		{uri: "/florest/item/12/size", resource: "ITEM", version: "V1", pathParams: "12/size"},
+		{uri: "/florest/item/?v=2", resource: "ITEM", version: "V2"},
+		{uri: "/florest/item/", header: map[string]string{"X-Api-Version": "v3"}, resource: "ITEM", version: "V3"},
+		// the header takes precedence over the query param
+		{uri: "/florest/item/?v=2", header: map[string]string{"X-Api-Version": "3"}, resource: "ITEM",
+			version: "V3"},
+		{uri: "/florest/healthcheck", header: map[string]string{"X-Api-Version": "3"},
+			resource: constants.HealthCheckAPI},
+		{uri: "/florest/", resource: "", version: ""},
+		{uri: "/other/item/", resource: "", version: ""},
+	})
+
+	conf = config.URISchemeConfig{Type: HeaderURIScheme, Prefix: &prefix, VersionHeader: "X-Api-Version"}
+	testURIScheme(t, conf, []uriSchemeTest{
+		{uri: "/florest/item/?v=2", resource: "ITEM", version: ""},
+		{uri: "/florest/item/", header: map[string]string{"X-Api-Version": "v2"}, resource: "ITEM", version: "V2"},
+	})
+}
+
+func TestMediaTypeURIScheme(t *testing.T) {
+	prefix := "florest"
+	conf := config.URISchemeConfig{Type: MediaTypeURIScheme, Prefix: &prefix, MediaTypeVendor: "florest",
+		DefaultVersion: "1"}
+	testURIScheme(t, conf, []uriSchemeTest{
+		{uri: "/florest/item/12", resource: "ITEM", version: "V1", pathParams: "12"},
+		{uri: "/florest/item/", header: map[string]string{"Accept": "application/json"}, resource: "ITEM",
+			version: "V1"},
+		{uri: "/florest/item/", header: map[string]string{"Accept": "application/vnd.florest.v2+json"},
+			resource: "ITEM", version: "V2"},
+		{uri: "/florest/item/", header: map[string]string{"Accept": "application/vnd.florest.v3"},
+			resource: "ITEM", version: "V3"},
+		{uri: "/florest/item/", header: map[string]string{"Accept": "application/json; charset=utf-8; version=4"},
+			resource: "ITEM", version: "V4"},
+		// the first media range carrying a version is used
+		{uri: "/florest/item/", header: map[string]string{
+			"Accept": "text/html, application/vnd.other.v9+json, application/vnd.florest.v2+json;q=0.9, " +
+				"application/json;version=3"}, resource: "ITEM", version: "V2"},
+		{uri: "/florest/healthcheck", resource: constants.HealthCheckAPI},
+		{uri: "/florest/", resource: "", version: ""},
+	})
+
+	conf = config.URISchemeConfig{Type: MediaTypeURIScheme, Prefix: &prefix}
+	testURIScheme(t, conf, []uriSchemeTest{
+		{uri: "/florest/item/", header: map[string]string{"Accept": "application/vnd.florest.v2+json"},
+			resource: "ITEM", version: ""},
+		{uri: "/florest/item/", header: map[string]string{"Accept": "application/json;version=v2"},
+			resource: "ITEM", version: "V2"},
+	})
+}
+
+func TestGetURIScheme(t *testing.T) {
+	if _, err := getURIScheme(config.URISchemeConfig{Type: "Unknown"}); err == nil {
+		t.Error("Unknown uri scheme returned")
+	}
+}
+
+func TestNormaliseVersion(t *testing.T) {
+	tests := map[string]string{
+		"":     "",
+		"1":    "V1",
+		" 2 ":  "V2",
+		"v3":   "V3",
+		"V4":   "V4",
+		"beta": "BETA",
+	}
+	for version, want := range tests {
+		if got := normaliseVersion(version); got != want {
+			t.Errorf("Normalised version of %q is %q, want %q", version, got, want)
+		}
+	}
+}
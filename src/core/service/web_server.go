@@ -24,6 +24,9 @@ func (ws Webserver) ServiceHandler(w http.ResponseWriter, req *http.Request) {
 		fmt.Fprintf(w, "Error %v", derr)
 		return
 	}
+	defer setAccessEntry(req, io)
+	defer releaseResponseCache(io)
+	defer releaseIdempotentRequest(io)
 
 	serviceVersion, _, _, gerr := versionmanager.Get("SERVICE", "V1", "GET", constants.OrchestratorBucketDefaultValue, "")
 
@@ -33,13 +36,30 @@ func (ws Webserver) ServiceHandler(w http.ResponseWriter, req *http.Request) {
 	}
 
 	if serviceOrchestrator, ok := serviceVersion.(workflow.Orchestrator); ok {
+		span := startRequestSpan(io, req)
+		status := constants.HTTPStatusInternalServerErrorCode
+		defer func() {
+			finishRequestSpan(span, io, status)
+		}()
+		io.IOData.Set(constants.ResponseWriter, w)
 		output := serviceOrchestrator.Start(io)
+		defer removeUploads(*output)
+		if getStartedStream(*output) != nil {
+			status = constants.HTTPStatusSuccessCode
+			return
+		}
+		if session := getWebSocketSession(*output); session != nil {
+			status = constants.HTTPCode(http.StatusSwitchingProtocols)
+			session.serve(w, req)
+			return
+		}
 		response, _ := output.IOData.Get(constants.APIResponse)
 		if v, ok := response.(utilhttp.APIResponse); ok {
 			//logger.Error(fmt.Sprintf("HEllo %+v", v.Headers))
 			for key, val := range v.Headers {
 				w.Header().Set(key, val)
 			}
+			status = v.HTTPStatus
 			w.WriteHeader(int(v.HTTPStatus))
 			w.Write(v.Body)
 			return
@@ -59,6 +79,24 @@ func (ws Webserver) Start() {
 
 	logger.Info(fmt.Sprintln("Web server Initialization done"))
 
+	handler := ws.Handler()
+
+	//Start the admin server if configured
+	startAdminServer()
+
+	//Start the web server on all its addresses
+	logger.Info(fmt.Sprintln("Web server Starting......"))
+
+	serr := serveHTTP(handler)
+	if serr != nil {
+		logger.Error(fmt.Sprintln("Could not start web server ", serr))
+	}
+
+}
+
+// Handler returns the handler of the web server once the app is initialised - the service rate limited
+// as per the config, gzipped and access logged, along with the liveness and readiness checks
+func (ws Webserver) Handler() http.Handler {
 	//All requests will be passed to the service handler
 	var httpHandlerFunc = utilhttp.MakeGzipHandler(ws.wrapperHandler)
 
@@ -69,26 +107,23 @@ func (ws Webserver) Start() {
 			logger.Error(fmt.Sprintln("Could not initialise rate limiter ", rerr.Error()))
 			panic(rerr)
 		}
+		appRateLimiter = rl
 
 		httpHandlerFunc = utilhttp.MakeGzipHandler(
 			ratelimiter.MakeRateLimitedHTTPHandler(ws.wrapperHandler, rl, "SERVICE"),
 		)
 	}
 
-	http.HandleFunc("/", httpHandlerFunc)
-
-	//Start the web server
-	url := ":" + config.GlobalAppConfig.ServerPort
-	logger.Info(fmt.Sprintln("Web server Starting......"))
+	//Write the access log records
+	httpHandlerFunc = makeAccessLogHandler(httpHandlerFunc)
 
-	serr := http.ListenAndServe(url, nil)
-	if serr != nil {
-		logger.Error(fmt.Sprintln("Could not start web server ", serr))
-	}
-	if serr == nil {
-		logger.Info(fmt.Sprintln("Web server Started on port : ", config.GlobalAppConfig.ServerPort))
-	}
+	//The service is served on its own mux, the default mux is left to the debug handlers
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", httpHandlerFunc)
 
+	//The liveness and readiness checks are served bypassing the rate limiter and the access log
+	handleProbes(mux)
+	return mux
 }
 
 // wrapper handler
@@ -96,7 +131,6 @@ func (ws Webserver) wrapperHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, PUT, PATCH, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", swaggerAllowedHeaders)
-	w.Header().Set("content-type", "application/json")
 	if strings.HasPrefix(r.URL.Path, "/swagger") {
 		ws.swaggerHandler(w, r)
 	} else {
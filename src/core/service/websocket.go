@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jabong/florest-core/src/common/constants"
+	"github.com/jabong/florest-core/src/common/logger"
+	"github.com/jabong/florest-core/src/common/monitor"
+	utilhttp "github.com/jabong/florest-core/src/common/utils/http"
+	"github.com/jabong/florest-core/src/common/utils/websocket"
+	workflow "github.com/jabong/florest-core/src/core/common/orchestrator"
+	"github.com/jabong/florest-core/src/core/common/utils/orchestratorhelper"
+	"github.com/jabong/florest-core/src/core/common/versionmanager"
+)
+
+// webSocketSession runs the orchestrator of a WebSocket api for each message received on the connection.
+// The messages are run one at a time, in the order received, with the IO data and the execution context
+// of the upgrade request, hence sharing its request context
+type webSocketSession struct {
+	orchestrator *workflow.Orchestrator
+	conf         websocket.Config
+	io           workflow.WorkFlowIOInterface
+	ec           *workflow.WorkFlowECInMemoryImpl
+	metricPrefix string
+	tags         []string
+}
+
+// isWebSocket checks if the api is served over a WebSocket
+func isWebSocket(apiVersion *versionmanager.Version) bool {
+	return apiVersion != nil && apiVersion.WebSocket != nil
+}
+
+// openWebSocket validates the handshake of the upgrade request and sets the session in the IO data.
+// The connection is upgraded by the web server once the service pipeline ends
+func openWebSocket(data workflow.WorkFlowData, req *utilhttp.Request, orchestrator *workflow.Orchestrator,
+	apiVersion *versionmanager.Version, resource string, version string, action string,
+	orchBucket string) *constants.AppError {
+	if req == nil || req.OriginalRequest == nil {
+		return &constants.AppError{Code: constants.UpgradeRequiredErrorCode, Message: "WebSocket handshake required"}
+	}
+	if err := websocket.CheckHandshake(req.OriginalRequest); err != nil {
+		return &constants.AppError{Code: constants.UpgradeRequiredErrorCode, Message: "WebSocket handshake required",
+			DeveloperMessage: err.Error()}
+	}
+	ec, ok := data.ExecContext.(*workflow.WorkFlowECInMemoryImpl)
+	if !ok {
+		return &constants.AppError{Code: constants.ResourceErrorCode, Message: "WebSocket session can not be set up",
+			DeveloperMessage: "Unsupported execution context"}
+	}
+	session := &webSocketSession{
+		orchestrator: orchestrator,
+		conf:         *apiVersion.WebSocket,
+		io:           data.IOData.Clone(),
+		ec:           ec,
+		metricPrefix: fmt.Sprintf("%v_%v_%v_%v_%v", action, version, resource, orchBucket, getCustomMetricPrefix(data)),
+		tags:         getBucketTags(data),
+	}
+	// the response writer is taken over by the connection
+	session.io.Set(constants.ResponseWriter, nil)
+	data.IOData.Set(constants.WebSocketSession, session)
+	return nil
+}
+
+// getWebSocketSession returns the WebSocket session set up for the request if any
+func getWebSocketSession(data workflow.WorkFlowData) *webSocketSession {
+	s, _ := data.IOData.Get(constants.WebSocketSession)
+	session, _ := s.(*webSocketSession)
+	return session
+}
+
+// serve upgrades the connection and runs the orchestrator for the messages until the connection is closed
+func (s *webSocketSession) serve(w http.ResponseWriter, r *http.Request) {
+	rc, _ := s.ec.Get(constants.RequestContext)
+	conn, err := websocket.Upgrade(w, r, s.conf)
+	if err != nil {
+		logger.Error(fmt.Sprintf("WebSocket upgrade failed. Err : %s", err), rc)
+		http.Error(w, "WebSocket upgrade failed", http.StatusInternalServerError)
+		return
+	}
+	logger.Info(fmt.Sprintf("WebSocket opened with %s", conn.RemoteAddr()), rc)
+	for {
+		messageType, p, err := conn.ReadMessage()
+		if err != nil {
+			logger.Info(fmt.Sprintf("WebSocket with %s ended. %s", conn.RemoteAddr(), err), rc)
+			return
+		}
+		replyType, reply := s.handle(messageType, p)
+		if err := conn.WriteMessage(replyType, reply); err != nil {
+			logger.Warning(fmt.Sprintf("WebSocket reply to %s failed. Err : %s", conn.RemoteAddr(), err), rc)
+			conn.Close(websocket.CloseGoingAway, "")
+			return
+		}
+	}
+}
+
+// handle runs the orchestrator for the message and returns the reply, the result of the orchestrator.
+// Results of type string are sent as text, []byte as binary and others as json text. On an error the
+// status of the errors is sent as json text
+func (s *webSocketSession) handle(messageType int, p []byte) (int, []byte) {
+	ec := s.ec.Clone()
+	io := s.io.Clone()
+	io.Set(constants.WebSocketMessage, p)
+	io.Set(constants.WebSocketMessageType, messageType)
+	data := workflow.WorkFlowData{}
+	data.Create(io, ec)
+
+	rc, _ := ec.Get(constants.RequestContext)
+	s.count("websocket_message_count", rc)
+	res, err := orchestratorhelper.ExecuteOrchestrator(&data, s.orchestrator)
+	if err == nil {
+		switch v := res.(type) {
+		case string:
+			return websocket.TextMessage, []byte(v)
+		case []byte:
+			return websocket.BinaryMessage, v
+		}
+		reply, jerr := json.Marshal(res)
+		if jerr == nil {
+			return websocket.TextMessage, reply
+		}
+		err = &constants.AppError{Code: constants.InvalidErrorCode, Message: "Result can not be encoded",
+			DeveloperMessage: jerr.Error()}
+	}
+
+	s.count("websocket_error_count", rc)
+	status := constants.GetAppHTTPError(*getAppErrors(err))
+	logger.Warning(fmt.Sprintf("WebSocket message failed with %v", status), rc)
+	reply, _ := json.Marshal(utilhttp.Response{Status: *status})
+	return websocket.TextMessage, reply
+}
+
+func (s *webSocketSession) count(metric string, rc interface{}) {
+	if dderr := monitor.GetInstance().Count(s.metricPrefix+metric, 1, s.tags, 1); dderr != nil {
+		logger.Error(fmt.Sprintln("Monitoring Error ", dderr.Error()), rc)
+	}
+}